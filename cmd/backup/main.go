@@ -0,0 +1,98 @@
+// Command backup snapshots, exports and restores a readdo database.
+//
+//	backup snapshot <file.db>     consistent SQLite copy of DB_PATH (VACUUM INTO)
+//	backup export <file.jsonl>    portable archive of items, intents, artifacts, todos and tags
+//	backup restore <file.jsonl>   load an archive into DB_PATH, which must have no items
+//
+// The database is taken from DB_PATH (or .env.local), like the server. It
+// is safe to snapshot or export while the server is running.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/yangwenmai/readdo/internal/config"
+	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/store"
+)
+
+// backupFilePerm keeps backups, which include webhook secrets, private.
+const backupFilePerm = 0o600
+
+func main() {
+	if len(os.Args) != 3 {
+		usage()
+	}
+	cmd, path := os.Args[1], os.Args[2]
+	if cmd != "snapshot" && cmd != "export" && cmd != "restore" {
+		usage()
+	}
+
+	cfg := config.Load()
+	db, err := store.OpenSQLite(cfg.DBPath)
+	if err != nil {
+		fail("open database %s: %v", cfg.DBPath, err)
+	}
+	defer db.Close()
+	s, err := store.New(db, store.WithURLCanonicalizer(model.NewURLCanonicalizer(cfg.TrackingParams)))
+	if err != nil {
+		fail("initialize store: %v", err)
+	}
+
+	ctx := context.Background()
+	switch cmd {
+	case "snapshot":
+		if err := s.Backup(ctx, path); err != nil {
+			fail("snapshot: %v", err)
+		}
+		if err := os.Chmod(path, backupFilePerm); err != nil {
+			fail("chmod snapshot: %v", err)
+		}
+		fmt.Printf("snapshot of %s written to %s\n", cfg.DBPath, path)
+
+	case "export":
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, backupFilePerm)
+		if err != nil {
+			fail("create archive: %v", err)
+		}
+		sum, err := s.ExportArchive(ctx, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+			fail("export: %v", err)
+		}
+		printSummary("exported", sum)
+
+	case "restore":
+		f, err := os.Open(path)
+		if err != nil {
+			fail("open archive: %v", err)
+		}
+		defer f.Close()
+		sum, err := s.RestoreArchive(ctx, f)
+		if err != nil {
+			fail("restore: %v", err)
+		}
+		printSummary("restored", sum)
+	}
+}
+
+func printSummary(verb string, sum store.ArchiveSummary) {
+	out, _ := json.Marshal(sum)
+	fmt.Printf("%s %s\n", verb, out)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: backup snapshot <file.db> | export <file.jsonl> | restore <file.jsonl>")
+	os.Exit(2)
+}
+
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "backup: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -3,16 +3,23 @@ package main
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/yangwenmai/readdo/internal/api"
 	"github.com/yangwenmai/readdo/internal/config"
 	"github.com/yangwenmai/readdo/internal/engine"
+	"github.com/yangwenmai/readdo/internal/events"
+	"github.com/yangwenmai/readdo/internal/export"
+	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/scheduler"
 	"github.com/yangwenmai/readdo/internal/store"
+	"github.com/yangwenmai/readdo/internal/webhook"
 	"github.com/yangwenmai/readdo/internal/worker"
 )
 
@@ -43,6 +50,7 @@ func main() {
 		"openai_base_url", cfg.OpenAIBaseURL,
 		"openai_key_set", cfg.OpenAIKey != "",
 		"worker_interval", cfg.WorkerInterval.String(),
+		"auto_tag_threshold", cfg.AutoTagThreshold,
 	)
 
 	// Open SQLite.
@@ -54,7 +62,7 @@ func main() {
 	defer db.Close()
 
 	// Initialize store.
-	s, err := store.New(db)
+	s, err := store.New(db, store.WithURLCanonicalizer(model.NewURLCanonicalizer(cfg.TrackingParams)))
 	if err != nil {
 		slog.Error("failed to initialize store", "error", err)
 		os.Exit(1)
@@ -92,36 +100,84 @@ func main() {
 		}
 	}
 
+	// Item lifecycle events, shared by the pipeline, worker and API.
+	bus := events.NewBus(cfg.EventHistorySize)
+
 	// Build pipeline with pluggable steps.
 	pipeline := engine.NewPipeline(
-		&engine.ExtractStep{Extractor: extractor, Artifacts: s},
+		&engine.ExtractStep{Extractor: extractor, Artifacts: s, Fingerprints: s},
+		&engine.ResolveStep{Items: s},
 		&engine.SynthesizeStep{Model: modelClient, Artifacts: s},
+		&engine.TagStep{Model: modelClient, Artifacts: s, Tags: s, Threshold: cfg.AutoTagThreshold},
 		&engine.ScoreStep{Model: modelClient, Artifacts: s, Scores: s},
-		&engine.TodoStep{Model: modelClient, Artifacts: s},
-	)
+		&engine.TodoStep{Model: modelClient, Artifacts: s, Todos: s},
+	).WithEvents(bus).WithIntents(s)
 
-	// Start worker in background.
+	// Background services stop in two stages at shutdown: first those that
+	// change items (ctx), then the event consumers (consumeCtx), so the
+	// consumers see every event the others published.
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	consumeCtx, cancelConsumers := context.WithCancel(context.Background())
+	defer cancelConsumers()
+	var producers, consumers sync.WaitGroup
 
-	w := worker.New(s, pipeline, cfg.WorkerInterval)
-	go w.Start(ctx)
+	// Start worker in background.
+	w := worker.New(s, pipeline, cfg.WorkerInterval, worker.WithEvents(bus))
+	producers.Go(func() { w.Start(ctx) })
+
+	// Start maintenance job scheduler in background.
+	sched := scheduler.New(s)
+	for _, job := range []scheduler.Job{
+		scheduler.WakeSnoozedJob(s, bus, cfg.SnoozeWakeSchedule),
+		scheduler.OptimizeDBJob(s, cfg.DBOptimizeSchedule),
+		scheduler.AutoArchiveJob(s, bus, cfg.AutoArchiveSchedule),
+	} {
+		if err := sched.Register(job); err != nil {
+			slog.Error("invalid job schedule", "error", err)
+			os.Exit(1)
+		}
+	}
+	producers.Go(func() { sched.Start(ctx) })
+
+	// Deliver item events to webhooks in background.
+	dispatcher := webhook.New(s, bus, cfg.WebhookTimeout)
+	consumers.Go(func() { dispatcher.Start(consumeCtx) })
+
+	// Keep the Markdown vault in sync in background, if configured.
+	opts := []api.Option{api.WithJobs(sched), api.WithEvents(bus), api.WithImportRate(cfg.ImportRate)}
+	if cfg.VaultDir != "" {
+		vault := export.NewVault(cfg.VaultDir, s).WithEvents(bus)
+		consumers.Go(func() { vault.Watch(consumeCtx, bus, cfg.VaultSyncInterval) })
+		opts = append(opts, api.WithVault(vault))
+	}
 
 	// Start API server.
-	srv := api.New(s)
+	srv := api.New(s, opts...)
+	reqCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 	httpServer := &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: srv.Handler(),
+		// Derive request contexts from reqCtx so that open event streams end
+		// on shutdown instead of blocking it.
+		BaseContext: func(net.Listener) context.Context { return reqCtx },
 	}
 
-	// Graceful shutdown.
+	// Graceful shutdown: requests, then producers, then consumers.
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		<-sigCh
 		slog.Info("shutting down...")
-		cancel()
+		cancelRequests()
 		httpServer.Shutdown(context.Background())
+		cancel()
+		producers.Wait()
+		cancelConsumers()
+		consumers.Wait()
 	}()
 
 	slog.Info("readdo server listening", "addr", "http://localhost:"+cfg.Port)
@@ -129,4 +185,5 @@ func main() {
 		slog.Error("server error", "error", err)
 		os.Exit(1)
 	}
+	<-stopped
 }
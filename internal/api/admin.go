@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/scheduler"
+)
+
+// JobRunner lists and triggers scheduled maintenance jobs.
+type JobRunner interface {
+	Jobs(ctx context.Context) ([]model.Job, error)
+	Trigger(name string) error
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/admin/jobs
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	if s.jobs == nil {
+		writeError(w, http.StatusServiceUnavailable, "scheduler is not running")
+		return
+	}
+	jobs, err := s.jobs.Jobs(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list jobs")
+		return
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/admin/jobs/{name}/run
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleRunJob(w http.ResponseWriter, r *http.Request) {
+	if s.jobs == nil {
+		writeError(w, http.StatusServiceUnavailable, "scheduler is not running")
+		return
+	}
+	name := r.PathValue("name")
+
+	err := s.jobs.Trigger(name)
+	if errors.Is(err, scheduler.ErrJobNotFound) {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	if errors.Is(err, scheduler.ErrJobRunning) {
+		writeError(w, http.StatusConflict, "job is already running")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to trigger job")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"name": name, "status": "triggered"})
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/admin/backup
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	dir, err := os.MkdirTemp("", "readdo-backup-*")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create backup")
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "readdo.db")
+	if err := s.store.Backup(r.Context(), path); err != nil {
+		slog.Error("backup failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create backup")
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read backup")
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read backup")
+		return
+	}
+
+	name := "readdo-" + time.Now().UTC().Format("20060102-150405") + ".db"
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/admin/export.jsonl
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleExportArchive(w http.ResponseWriter, r *http.Request) {
+	// Export to a temporary file first, so that a failure can still be
+	// reported as an error instead of a truncated download.
+	f, err := os.CreateTemp("", "readdo-export-*.jsonl")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to export")
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := s.store.ExportArchive(r.Context(), f); err != nil {
+		slog.Error("archive export failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to export")
+		return
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to export")
+		return
+	}
+
+	name := "readdo-" + time.Now().UTC().Format("20060102-150405") + ".jsonl"
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, f)
+}
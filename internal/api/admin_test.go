@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/scheduler"
+)
+
+// fakeJobRunner records triggered jobs.
+type fakeJobRunner struct {
+	jobs      []model.Job
+	triggered []string
+	running   bool
+}
+
+func (f *fakeJobRunner) Jobs(_ context.Context) ([]model.Job, error) {
+	return f.jobs, nil
+}
+
+func (f *fakeJobRunner) Trigger(name string) error {
+	for _, j := range f.jobs {
+		if j.Name == name {
+			if f.running {
+				return scheduler.ErrJobRunning
+			}
+			f.triggered = append(f.triggered, name)
+			return nil
+		}
+	}
+	return scheduler.ErrJobNotFound
+}
+
+func TestAdminJobs(t *testing.T) {
+	_, st := newTestServer(t)
+	runner := &fakeJobRunner{jobs: []model.Job{{Name: "snooze-wake", Schedule: "* * * * *", NextRunAt: "2026-03-04T10:01:00Z"}}}
+	h := New(st, WithJobs(runner)).Handler()
+
+	rr := doRequest(t, h, "GET", "/api/admin/jobs", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("list status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	var jobs []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &jobs)
+	if len(jobs) != 1 || jobs[0]["name"] != "snooze-wake" {
+		t.Errorf("jobs = %v, want [snooze-wake]", jobs)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/admin/jobs/snooze-wake/run", "")
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("run status = %d, want %d", rr.Code, http.StatusAccepted)
+	}
+	if len(runner.triggered) != 1 {
+		t.Errorf("triggered = %v, want [snooze-wake]", runner.triggered)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/admin/jobs/missing/run", "")
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("missing job status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+
+	runner.running = true
+	rr = doRequest(t, h, "POST", "/api/admin/jobs/snooze-wake/run", "")
+	if rr.Code != http.StatusConflict {
+		t.Errorf("running job status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestAdminJobs_NoScheduler(t *testing.T) {
+	srv, _ := newTestServer(t)
+	rr := doRequest(t, srv.Handler(), "GET", "/api/admin/jobs", "")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestAdminBackup(t *testing.T) {
+	srv, st := newTestServer(t)
+	st.CreateItem(context.Background(), model.NewItem("item-1", "https://example.com", "Saved", "example.com", "web", ""))
+
+	rr := doRequest(t, srv.Handler(), "GET", "/api/admin/backup", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.HasPrefix(rr.Body.String(), "SQLite format 3\x00") {
+		t.Errorf("backup is not a SQLite database: %q", rr.Body.String()[:min(rr.Body.Len(), 32)])
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") {
+		t.Errorf("Content-Disposition = %q, want attachment", cd)
+	}
+}
+
+func TestAdminExportArchive(t *testing.T) {
+	srv, st := newTestServer(t)
+	ctx := context.Background()
+	st.CreateItem(ctx, model.NewItem("item-1", "https://example.com", "Saved", "example.com", "web", ""))
+	st.CreateIntent(ctx, model.NewIntent("intent-1", "item-1", "learn"))
+
+	rr := doRequest(t, srv.Handler(), "GET", "/api/admin/export.jsonl", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+
+	// The archive restores into a fresh database with the same IDs.
+	_, fresh := newTestServer(t)
+	sum, err := fresh.RestoreArchive(ctx, rr.Body)
+	if err != nil {
+		t.Fatalf("RestoreArchive: %v", err)
+	}
+	if sum.Items != 1 || sum.Intents != 1 {
+		t.Errorf("restored %+v, want 1 item and 1 intent", sum)
+	}
+	if _, err := fresh.GetItem(ctx, "item-1"); err != nil {
+		t.Errorf("restored item: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/store"
+)
+
+// ---------------------------------------------------------------------------
+// GET /api/archive-rules
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListArchiveRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.store.ListArchiveRules(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list archive rules")
+		return
+	}
+	if rules == nil {
+		rules = []model.ArchiveRule{}
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/archive-rules
+// ---------------------------------------------------------------------------
+
+type archiveRuleRequest struct {
+	Name       string   `json:"name"`
+	Priorities []string `json:"priorities"`
+	Basis      string   `json:"basis"` // "created" or "updated"
+	Days       int      `json:"days"`
+	Enabled    *bool    `json:"enabled"` // defaults to true
+}
+
+// rule builds an ArchiveRule with the given ID from the request.
+func (req archiveRuleRequest) rule(id string) model.ArchiveRule {
+	rule := model.NewArchiveRule(id, req.Name, req.Priorities, req.Basis, req.Days)
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return rule
+}
+
+func (s *Server) handleCreateArchiveRule(w http.ResponseWriter, r *http.Request) {
+	var req archiveRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	rule := req.rule(uuid.New().String())
+	if err := rule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.CreateArchiveRule(r.Context(), rule); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create archive rule")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// ---------------------------------------------------------------------------
+// PUT /api/archive-rules/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleUpdateArchiveRule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req archiveRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	rule := req.rule(id)
+	if err := rule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := s.store.UpdateArchiveRule(r.Context(), rule)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "archive rule not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update archive rule")
+		return
+	}
+
+	updated, err := s.store.GetArchiveRule(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get archive rule")
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// ---------------------------------------------------------------------------
+// DELETE /api/archive-rules/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleDeleteArchiveRule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	err := s.store.DeleteArchiveRule(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "archive rule not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete archive rule")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": id, "deleted": "true"})
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/archive-rules/{id}/dry-run
+// POST /api/archive-rules/dry-run
+// ---------------------------------------------------------------------------
+
+type dryRunResponse struct {
+	Rule   model.ArchiveRule `json:"rule"`
+	Reason string            `json:"reason"`
+	Count  int               `json:"count"`
+	Items  []model.Item      `json:"items"`
+}
+
+func (s *Server) handleDryRunArchiveRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := s.store.GetArchiveRule(r.Context(), r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "archive rule not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get archive rule")
+		return
+	}
+	s.writeDryRun(w, r, *rule)
+}
+
+// handleDryRunDraftRule previews an unsaved rule given in the request body.
+func (s *Server) handleDryRunDraftRule(w http.ResponseWriter, r *http.Request) {
+	var req archiveRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	rule := req.rule("")
+	if err := rule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writeDryRun(w, r, rule)
+}
+
+func (s *Server) writeDryRun(w http.ResponseWriter, r *http.Request, rule model.ArchiveRule) {
+	items, err := s.store.MatchArchiveRule(r.Context(), rule, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to evaluate archive rule")
+		return
+	}
+	writeJSON(w, http.StatusOK, dryRunResponse{Rule: rule, Reason: rule.Reason(), Count: len(items), Items: items})
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/auto-archives
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListAutoArchives(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.store.ListAutoArchives(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list auto-archives")
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/auto-archives/{id}/undo
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleUndoAutoArchive(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.store.UndoAutoArchive(r.Context(), r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "auto-archive not found")
+		return
+	}
+	if errors.Is(err, store.ErrUndoConflict) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to undo auto-archive")
+		return
+	}
+	s.publish(model.StatusEvent(entry.ItemID, entry.PreviousStatus))
+	writeJSON(w, http.StatusOK, entry)
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestArchiveRules(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	rr := doRequest(t, h, "POST", "/api/archive-rules", `{"name":"Let go","priorities":["LET_GO"],"basis":"created","days":0}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid rule status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/archive-rules", `{"name":"Let go","priorities":["LET_GO"],"basis":"created","days":7}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d, body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	rule := decodeJSON(t, rr)
+	if rule["enabled"] != true {
+		t.Errorf("enabled = %v, want true by default", rule["enabled"])
+	}
+	ruleID := rule["id"].(string)
+
+	// An old LET_GO item.
+	item := model.NewItem("item-1", "https://example.com", "Old", "example.com", "web", "")
+	item.Status = model.StatusReady
+	letGo := model.PriorityLetGo
+	item.Priority = &letGo
+	item.CreatedAt = time.Now().AddDate(0, 0, -10).UTC().Format(time.RFC3339)
+	st.CreateItem(ctx, item)
+
+	rr = doRequest(t, h, "GET", "/api/archive-rules/"+ruleID+"/dry-run", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("dry-run status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if got := decodeJSON(t, rr)["count"]; got != float64(1) {
+		t.Errorf("dry-run count = %v, want 1", got)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/archive-rules/dry-run", `{"name":"draft","priorities":["LET_GO"],"basis":"created","days":30}`)
+	if got := decodeJSON(t, rr)["count"]; got != float64(0) {
+		t.Errorf("draft dry-run count = %v, want 0", got)
+	}
+
+	// Apply the rule the way the scheduled job does, then undo via the API.
+	saved, _ := st.GetArchiveRule(ctx, ruleID)
+	st.ApplyArchiveRule(ctx, *saved, time.Now())
+
+	rr = doRequest(t, h, "GET", "/api/auto-archives", "")
+	var entries []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &entries)
+	if len(entries) != 1 {
+		t.Fatalf("auto-archives = %d, want 1", len(entries))
+	}
+	entryID := entries[0]["id"].(string)
+
+	rr = doRequest(t, h, "POST", "/api/auto-archives/"+entryID+"/undo", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("undo status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	rr = doRequest(t, h, "POST", "/api/auto-archives/"+entryID+"/undo", "")
+	if rr.Code != http.StatusConflict {
+		t.Errorf("second undo status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	rr = doRequest(t, h, "GET", "/api/items/item-1", "")
+	if got := decodeJSON(t, rr)["status"]; got != model.StatusReady {
+		t.Errorf("item status = %v, want READY", got)
+	}
+
+	rr = doRequest(t, h, "DELETE", "/api/archive-rules/"+ruleID, "")
+	if rr.Code != http.StatusOK {
+		t.Errorf("delete status = %d, want %d", rr.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/export"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// GET /api/calendar.ics
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	opts := export.CalendarOptions{Now: time.Now()}
+	if v := q.Get("hours"); v != "" {
+		start, end, err := export.ParseWorkingHours(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		loc := time.Local
+		if tz := q.Get("tz"); tz != "" {
+			if loc, err = time.LoadLocation(tz); err != nil {
+				writeError(w, http.StatusBadRequest, "tz must be an IANA time zone such as Europe/Berlin")
+				return
+			}
+		}
+		weekends := false
+		if v := q.Get("weekends"); v != "" {
+			if weekends, err = strconv.ParseBool(v); err != nil {
+				writeError(w, http.StatusBadRequest, "weekends must be true or false")
+				return
+			}
+		}
+		opts.Hours = &export.WorkingHours{Start: start, End: end, Location: loc, Weekends: weekends}
+	}
+
+	items, err := s.store.ListItems(r.Context(), model.ItemFilter{
+		Status:   []string{model.StatusReady},
+		Priority: export.CalendarPriorities(),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list items")
+		return
+	}
+	todos, err := s.openTodosByItem(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list todos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="readdo.ics"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(export.RenderCalendar(items, todos, opts))
+}
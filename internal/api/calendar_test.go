@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestCalendar(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	for id, priority := range map[string]string{"first": model.PriorityDoFirst, "skim": model.PrioritySkimIt} {
+		item := model.NewItem(id, "https://example.com/"+id, "Item "+id, "example.com", "web", "")
+		st.CreateItem(ctx, item)
+		st.UpdateItemStatus(ctx, id, model.StatusReady, nil)
+		st.UpdateItemScoreAndPriority(ctx, id, 80, priority)
+		st.SyncGeneratedTodos(ctx, id, []model.Todo{model.NewTodo("t-"+id, id, "Read "+id, "20m", model.TodoTypeRead, 0)})
+	}
+
+	rr := doRequest(t, h, "GET", "/api/calendar.ics?hours=09:00-17:00&tz=UTC&weekends=true", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Content-Type = %q, want text/calendar", ct)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{"UID:todo-t-first@readdo", "UID:block-todo-t-first@readdo", "URL:https://example.com/first"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("calendar missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "t-skim") {
+		t.Error("SKIM_IT todo exported")
+	}
+
+	for _, q := range []string{"hours=17:00-09:00", "hours=09:00-17:00&tz=Mars/Olympus", "hours=09:00-17:00&weekends=maybe"} {
+		if rr := doRequest(t, h, "GET", "/api/calendar.ics?"+q, ""); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want %d", q, rr.Code, http.StatusBadRequest)
+		}
+	}
+}
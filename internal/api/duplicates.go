@@ -0,0 +1,168 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// GET /api/duplicates
+// ---------------------------------------------------------------------------
+
+// handleListDuplicates lists groups of items that share a canonical URL,
+// typically saved before canonicalization or in ARCHIVED status, followed by
+// the suspected duplicates awaiting confirmation and the groups of items
+// with near-duplicate content.
+func (s *Server) handleListDuplicates(w http.ResponseWriter, r *http.Request) {
+	clusters, err := s.store.ListURLDuplicates(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list duplicates")
+		return
+	}
+	suspected, err := s.store.ListSuspectedDuplicates(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list duplicates")
+		return
+	}
+	content, err := s.store.ListContentDuplicates(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list duplicates")
+		return
+	}
+	clusters = append(append(clusters, suspected...), content...)
+	if clusters == nil {
+		clusters = []model.DuplicateCluster{}
+	}
+	writeJSON(w, http.StatusOK, clusters)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/items/{id}/duplicate/merge
+// ---------------------------------------------------------------------------
+
+// handleConfirmDuplicate confirms a suspected duplicate: the item is merged
+// into the item it duplicates, which is returned and processed again.
+func (s *Server) handleConfirmDuplicate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	item, err := s.store.GetItem(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "item not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get item")
+		return
+	}
+	if item.DuplicateOf == nil {
+		writeError(w, http.StatusConflict, "item is not a suspected duplicate")
+		return
+	}
+	if item.Status == model.StatusProcessing {
+		writeError(w, http.StatusConflict, "cannot merge while PROCESSING")
+		return
+	}
+
+	merged, err := s.store.MergeItems(r.Context(), *item.DuplicateOf, []string{id})
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "the duplicated item no longer exists")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to merge items")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemDeleted, id))
+	s.publish(model.StatusEvent(merged.ID, merged.Status))
+	writeJSON(w, http.StatusOK, merged)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/items/merge
+// ---------------------------------------------------------------------------
+
+type mergeItemsRequest struct {
+	Into string   `json:"into"`
+	IDs  []string `json:"ids"`
+}
+
+// handleMergeItems folds the items in ids into the item into: their intents,
+// save counts and tags move over and they are deleted. The surviving item is
+// returned and processed again.
+func (s *Server) handleMergeItems(w http.ResponseWriter, r *http.Request) {
+	var req mergeItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Into == "" || len(req.IDs) == 0 {
+		writeError(w, http.StatusBadRequest, "into and ids are required")
+		return
+	}
+	var ids []string
+	seen := map[string]bool{}
+	for _, id := range req.IDs {
+		if id == req.Into {
+			writeError(w, http.StatusBadRequest, "cannot merge an item into itself")
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range append([]string{req.Into}, ids...) {
+		item, err := s.store.GetItem(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "item "+id+" not found")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get item")
+			return
+		}
+		if item.Status == model.StatusProcessing {
+			writeError(w, http.StatusConflict, "cannot merge while PROCESSING")
+			return
+		}
+	}
+
+	merged, err := s.store.MergeItems(r.Context(), req.Into, ids)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "item not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to merge items")
+		return
+	}
+	for _, id := range ids {
+		s.publish(model.NewEvent(model.EventItemDeleted, id))
+	}
+	s.publish(model.StatusEvent(merged.ID, merged.Status))
+	writeJSON(w, http.StatusOK, merged)
+}
+
+// ---------------------------------------------------------------------------
+// DELETE /api/items/{id}/duplicate
+// ---------------------------------------------------------------------------
+
+// handleDismissDuplicate keeps a suspected duplicate as a separate item.
+func (s *Server) handleDismissDuplicate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	err := s.store.DismissDuplicate(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "item is not a suspected duplicate")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to dismiss duplicate")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, id))
+	writeJSON(w, http.StatusOK, map[string]string{"id": id, "dismissed": "true"})
+}
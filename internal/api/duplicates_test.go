@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestCapture_CanonicalURL(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://www.example.com/post/?utm_source=news","intent_text":"first"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("first capture: status = %d", rr.Code)
+	}
+	id := decodeJSON(t, rr)["id"]
+
+	rr = doRequest(t, h, "POST", "/api/capture", `{"url":"http://example.com/post#comments","intent_text":"again"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("second capture: status = %d, want 200 (merged)", rr.Code)
+	}
+	if result := decodeJSON(t, rr); result["id"] != id || result["merged"] != true || result["save_count"] != float64(2) {
+		t.Errorf("second capture = %v", result)
+	}
+
+	rr = doRequest(t, h, "GET", "/api/items/"+id.(string), "")
+	if got := decodeJSON(t, rr)["canonical_url"]; got != "https://example.com/post" {
+		t.Errorf("canonical_url = %v", got)
+	}
+}
+
+func TestListDuplicates(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "GET", "/api/duplicates", "")
+	if rr.Code != http.StatusOK || rr.Body.String() != "[]\n" {
+		t.Errorf("empty: status = %d, body = %q", rr.Code, rr.Body.String())
+	}
+
+	// A capture that does not revive the archived item saves the URL twice.
+	rr = doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/a"}`)
+	id := decodeJSON(t, rr)["id"].(string)
+	st.UpdateItemStatus(context.Background(), id, model.StatusArchived, nil)
+	doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/a/?utm_medium=x","revive":false}`)
+
+	rr = doRequest(t, h, "GET", "/api/duplicates", "")
+	var clusters []model.DuplicateCluster
+	if err := json.Unmarshal(rr.Body.Bytes(), &clusters); err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Key != "https://example.com/a" || len(clusters[0].Items) != 2 {
+		t.Errorf("clusters = %+v", clusters)
+	}
+}
+
+func TestSuspectedDuplicate(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+	for _, id := range []string{"article", "amp", "other"} {
+		st.CreateItem(ctx, model.NewItem(id, "https://example.com/"+id, id, "example.com", "web", "because "+id))
+	}
+	st.FlagDuplicate(ctx, "amp", "article")
+	st.FlagDuplicate(ctx, "other", "article")
+
+	rr := doRequest(t, h, "GET", "/api/duplicates", "")
+	var clusters []model.DuplicateCluster
+	json.Unmarshal(rr.Body.Bytes(), &clusters)
+	if len(clusters) != 2 || clusters[0].Reason != model.DuplicateSuspected {
+		t.Fatalf("clusters = %+v", clusters)
+	}
+
+	if rr := doRequest(t, h, "POST", "/api/items/article/duplicate/merge", ""); rr.Code != http.StatusConflict {
+		t.Errorf("merge unflagged item: status = %d, want 409", rr.Code)
+	}
+	rr = doRequest(t, h, "POST", "/api/items/amp/duplicate/merge", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("confirm: status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if merged := decodeJSON(t, rr); merged["id"] != "article" || merged["save_count"] != float64(2) || merged["status"] != model.StatusCaptured {
+		t.Errorf("merged = %v", merged)
+	}
+	if rr := doRequest(t, h, "GET", "/api/items/amp", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("merged item: status = %d, want 404", rr.Code)
+	}
+
+	if rr := doRequest(t, h, "DELETE", "/api/items/other/duplicate", ""); rr.Code != http.StatusOK {
+		t.Errorf("dismiss: status = %d", rr.Code)
+	}
+	if rr := doRequest(t, h, "DELETE", "/api/items/other/duplicate", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("second dismiss: status = %d, want 404", rr.Code)
+	}
+	if rr := doRequest(t, h, "GET", "/api/duplicates", ""); rr.Body.String() != "[]\n" {
+		t.Errorf("duplicates after resolving = %s", rr.Body.String())
+	}
+}
+
+func TestMergeItems(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+	for _, id := range []string{"a", "b", "c"} {
+		st.CreateItem(ctx, model.NewItem(id, "https://example.com/"+id, id, "example.com", "web", "because "+id))
+		fp := model.Fingerprint(0)
+		st.SetFingerprint(ctx, id, &fp)
+	}
+	st.AddItemTags(ctx, "c", []string{"go"})
+
+	rr := doRequest(t, h, "GET", "/api/duplicates", "")
+	var clusters []model.DuplicateCluster
+	json.Unmarshal(rr.Body.Bytes(), &clusters)
+	if len(clusters) != 1 || clusters[0].Reason != model.DuplicateContent || len(clusters[0].Items) != 3 {
+		t.Fatalf("clusters = %+v", clusters)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"missing ids", `{"into":"a"}`, http.StatusBadRequest},
+		{"into itself", `{"into":"a","ids":["b","a"]}`, http.StatusBadRequest},
+		{"unknown item", `{"into":"a","ids":["missing"]}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rr := doRequest(t, h, "POST", "/api/items/merge", tt.body); rr.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rr.Code, tt.want)
+		}
+	}
+	st.UpdateItemStatus(ctx, "b", model.StatusProcessing, nil)
+	if rr := doRequest(t, h, "POST", "/api/items/merge", `{"into":"a","ids":["b"]}`); rr.Code != http.StatusConflict {
+		t.Errorf("merge PROCESSING item: status = %d, want 409", rr.Code)
+	}
+	st.UpdateItemStatus(ctx, "b", model.StatusFailed, nil)
+
+	rr = doRequest(t, h, "POST", "/api/items/merge", `{"into":"a","ids":["b","c","b"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("merge: status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	merged := decodeJSON(t, rr)
+	if merged["save_count"] != float64(3) || merged["intent_text"] != "because a\n---\nbecause b\n---\nbecause c" {
+		t.Errorf("merged = %v", merged)
+	}
+	if got, _ := st.GetItem(ctx, "a"); len(got.Tags) != 1 || got.Tags[0] != "go" {
+		t.Errorf("tags = %v", got.Tags)
+	}
+	if rr := doRequest(t, h, "GET", "/api/duplicates", ""); rr.Body.String() != "[]\n" {
+		t.Errorf("duplicates after merge = %s", rr.Body.String())
+	}
+}
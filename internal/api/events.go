@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// sseRetryMS is the reconnect delay suggested to EventSource clients.
+const sseRetryMS = 3000
+
+// ---------------------------------------------------------------------------
+// GET /api/events
+// ---------------------------------------------------------------------------
+
+// handleEvents streams item lifecycle events as Server-Sent Events. Clients
+// resume with the Last-Event-ID header (or ?last_event_id=); if the events
+// since then are no longer retained, a "reset" event tells the client to
+// refetch its state before the live stream continues.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if s.events == nil {
+		writeError(w, http.StatusServiceUnavailable, "event stream is not enabled")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	var since int64
+	if lastID != "" {
+		n, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+		since = n
+	}
+
+	sub, replay, complete := s.events.Subscribe(since)
+	defer sub.Close()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMS)
+	if !complete {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, e := range replay {
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes.
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e model.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/events"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// sseFrame is one parsed Server-Sent Events frame (or comment).
+type sseFrame struct {
+	id, event, data, retry, comment string
+}
+
+// openStream starts GET /api/events against a live test server and returns
+// the response and a function reading the next frame.
+func openStream(t *testing.T, srv *Server, lastEventID string) (*http.Response, func() sseFrame) {
+	t.Helper()
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	r := bufio.NewReader(resp.Body)
+	next := func() sseFrame {
+		t.Helper()
+		var f sseFrame
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read stream: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				if f == (sseFrame{}) {
+					continue
+				}
+				return f
+			case strings.HasPrefix(line, ":"):
+				f.comment = strings.TrimSpace(line[1:])
+			case strings.HasPrefix(line, "retry: "):
+				f.retry = line[len("retry: "):]
+			case strings.HasPrefix(line, "id: "):
+				f.id = line[len("id: "):]
+			case strings.HasPrefix(line, "event: "):
+				f.event = line[len("event: "):]
+			case strings.HasPrefix(line, "data: "):
+				f.data = line[len("data: "):]
+			}
+		}
+	}
+	return resp, next
+}
+
+func TestEvents_Disabled(t *testing.T) {
+	srv, _ := newTestServer(t)
+	rr := doRequest(t, srv.Handler(), "GET", "/api/events", "")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rr.Code)
+	}
+}
+
+func TestEvents_StreamsCapture(t *testing.T) {
+	_, st := newTestServer(t)
+	srv := New(st, WithEvents(events.NewBus(10)))
+
+	resp, next := openStream(t, srv, "")
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+	if f := next(); f.retry != "3000" {
+		t.Fatalf("first frame = %+v, want retry 3000", f)
+	}
+
+	rr := doRequest(t, srv.Handler(), "POST", "/api/capture", `{"url":"https://example.com/sse"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("capture status = %d, want 201", rr.Code)
+	}
+	itemID := decodeJSON(t, rr)["id"].(string)
+
+	f := next()
+	if f.id != "1" || f.event != model.EventItemCaptured {
+		t.Fatalf("frame = %+v, want id 1 %s", f, model.EventItemCaptured)
+	}
+	var e model.Event
+	if err := json.Unmarshal([]byte(f.data), &e); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if e.ItemID != itemID || e.Status != model.StatusCaptured {
+		t.Errorf("event = %+v, want item %s CAPTURED", e, itemID)
+	}
+
+	doRequest(t, srv.Handler(), "DELETE", "/api/items/"+itemID, "")
+	if f := next(); f.id != "2" || f.event != model.EventItemDeleted {
+		t.Errorf("frame = %+v, want id 2 %s", f, model.EventItemDeleted)
+	}
+}
+
+func TestEvents_Heartbeat(t *testing.T) {
+	_, st := newTestServer(t)
+	srv := New(st, WithEvents(events.NewBus(10)))
+	srv.heartbeat = 10 * time.Millisecond
+
+	_, next := openStream(t, srv, "")
+	next() // retry
+	if f := next(); f.comment != "heartbeat" {
+		t.Errorf("frame = %+v, want heartbeat comment", f)
+	}
+}
+
+func TestEvents_Resume(t *testing.T) {
+	_, st := newTestServer(t)
+	bus := events.NewBus(3)
+	srv := New(st, WithEvents(bus))
+	for _, id := range []string{"a", "b", "c", "d"} {
+		bus.Publish(model.StatusEvent(id, model.StatusReady))
+	}
+
+	t.Run("replays missed events", func(t *testing.T) {
+		_, next := openStream(t, srv, "2")
+		next() // retry
+		for _, want := range []string{"3", "4"} {
+			if f := next(); f.id != want || f.event != model.EventItemReady {
+				t.Errorf("frame = %+v, want id %s %s", f, want, model.EventItemReady)
+			}
+		}
+	})
+
+	t.Run("signals reset after a gap", func(t *testing.T) {
+		_, next := openStream(t, srv, "0")
+		next() // retry; 0 means a fresh client, no reset
+		bus.Publish(model.StatusEvent("e", model.StatusArchived))
+		if f := next(); f.id != "5" {
+			t.Errorf("frame = %+v, want live event 5", f)
+		}
+
+		_, next = openStream(t, srv, "1")
+		next() // retry
+		if f := next(); f.event != "reset" {
+			t.Fatalf("frame = %+v, want reset", f)
+		}
+		if f := next(); f.id != "3" {
+			t.Errorf("frame = %+v, want oldest retained event 3", f)
+		}
+	})
+}
+
+func TestEvents_InvalidLastEventID(t *testing.T) {
+	_, st := newTestServer(t)
+	srv := New(st, WithEvents(events.NewBus(10)))
+	rr := doRequest(t, srv.Handler(), "GET", "/api/events?last_event_id=abc", "")
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rr.Code)
+	}
+}
+
+func TestJSONResponses_ContentType(t *testing.T) {
+	srv, _ := newTestServer(t)
+	rr := doRequest(t, srv.Handler(), "GET", "/api/items", "")
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/export"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// VaultExporter exports items as Markdown notes.
+type VaultExporter interface {
+	ExportAll(ctx context.Context) (export.Summary, error)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/export/markdown
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleExportMarkdown(w http.ResponseWriter, r *http.Request) {
+	if s.vault == nil {
+		writeError(w, http.StatusServiceUnavailable, "markdown export is not configured (set VAULT_DIR)")
+		return
+	}
+	sum, err := s.vault.ExportAll(r.Context())
+	if err != nil {
+		slog.Error("markdown export failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to export markdown")
+		return
+	}
+	writeJSON(w, http.StatusOK, sum)
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/export/markdown/todo-syncs
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListTodoSyncs(w http.ResponseWriter, r *http.Request) {
+	syncs, err := s.store.ListTodoSyncs(r.Context(), r.URL.Query().Get("item_id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list todo syncs")
+		return
+	}
+	writeJSON(w, http.StatusOK, syncs)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/export/epub
+// ---------------------------------------------------------------------------
+
+// maxEPUBItems caps how many items one EPUB export holds.
+const maxEPUBItems = 200
+
+type epubRequest struct {
+	Title  string            `json:"title"`
+	IDs    []string          `json:"ids"`
+	Filter *model.ItemFilter `json:"filter"`
+}
+
+// handleExportEPUB builds an EPUB of the items named by ids, or of the
+// items matching filter, for reading on an e-reader.
+func (s *Server) handleExportEPUB(w http.ResponseWriter, r *http.Request) {
+	var req epubRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if (len(req.IDs) == 0) == (req.Filter == nil) {
+		writeError(w, http.StatusBadRequest, "either ids or filter is required")
+		return
+	}
+
+	ids := req.IDs
+	if req.Filter != nil {
+		if err := req.Filter.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		items, err := s.store.ListItems(r.Context(), *req.Filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to list items")
+			return
+		}
+		for _, it := range items {
+			ids = append(ids, it.ID)
+		}
+		if len(ids) == 0 {
+			writeError(w, http.StatusNotFound, "no items match the filter")
+			return
+		}
+	}
+	if len(ids) > maxEPUBItems {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d items can be exported at once", maxEPUBItems))
+		return
+	}
+
+	items := make([]model.ItemWithArtifacts, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		item, err := s.store.GetItem(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "item not found: "+id)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get item")
+			return
+		}
+		items = append(items, *item)
+	}
+
+	now := time.Now().UTC()
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = "readdo " + now.Format("2006-01-02")
+	}
+	var buf bytes.Buffer
+	if err := export.RenderEPUB(&buf, title, items, now); err != nil {
+		slog.Error("epub export failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to export epub")
+		return
+	}
+
+	name := "readdo-" + now.Format("2006-01-02") + ".epub"
+	w.Header().Set("Content-Type", "application/epub+zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/export"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestExportMarkdown(t *testing.T) {
+	srv, st := newTestServer(t)
+	if rr := doRequest(t, srv.Handler(), "POST", "/api/export/markdown", ""); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("without vault status = %d, want 503", rr.Code)
+	}
+
+	item := model.NewItem("item-1", "https://example.com", "Exported", "example.com", "web", "")
+	item.Status = model.StatusReady
+	st.CreateItem(context.Background(), item)
+
+	dir := t.TempDir()
+	h := New(st, WithVault(export.NewVault(dir, st))).Handler()
+	rr := doRequest(t, h, "POST", "/api/export/markdown", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if got := decodeJSON(t, rr)["written"]; got != float64(1) {
+		t.Errorf("written = %v, want 1", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "exported-item-1.md")); err != nil {
+		t.Errorf("note not written: %v", err)
+	}
+}
+
+func TestListTodoSyncs(t *testing.T) {
+	srv, st := newTestServer(t)
+	ctx := context.Background()
+	for i, itemID := range []string{"item-1", "item-2"} {
+		td := model.NewTodo("t-"+itemID, itemID, "Read", "10m", model.TodoTypeRead, i)
+		st.RecordTodoSync(ctx, model.NewTodoSync("s-"+itemID, td, "note.md", true, time.Now().Add(time.Hour)))
+	}
+
+	rr := doRequest(t, srv.Handler(), "GET", "/api/export/markdown/todo-syncs?item_id=item-2", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var syncs []model.TodoSync
+	if err := json.NewDecoder(rr.Body).Decode(&syncs); err != nil {
+		t.Fatal(err)
+	}
+	if len(syncs) != 1 || syncs[0].TodoID != "t-item-2" || syncs[0].Winner != model.SyncSideVault {
+		t.Errorf("syncs = %+v, want the item-2 vault win", syncs)
+	}
+}
+
+func TestExportEPUB(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		st.CreateItem(ctx, model.NewItem(id, "https://example.com/"+id, "Title "+id, "example.com", "web", ""))
+	}
+	st.UpdateItemStatus(ctx, "b", model.StatusReady, nil)
+
+	chapters := func(rr *httptest.ResponseRecorder) int {
+		t.Helper()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", rr.Code, rr.Body.String())
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/epub+zip" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if cd := rr.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="readdo-`) {
+			t.Errorf("Content-Disposition = %q", cd)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+		if err != nil {
+			t.Fatalf("not a zip: %v", err)
+		}
+		n := 0
+		for _, f := range zr.File {
+			if strings.HasPrefix(f.Name, "OEBPS/chapter-") {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := chapters(doRequest(t, h, "POST", "/api/export/epub", `{"ids":["a","b","a"]}`)); n != 2 {
+		t.Errorf("by ids: %d chapters, want 2", n)
+	}
+	if n := chapters(doRequest(t, h, "POST", "/api/export/epub", `{"title":"Ready","filter":{"status":["READY"]}}`)); n != 1 {
+		t.Errorf("by filter: %d chapters, want 1", n)
+	}
+
+	for body, want := range map[string]int{
+		`{}`:                             http.StatusBadRequest,
+		`{"ids":["a"],"filter":{}}`:      http.StatusBadRequest,
+		`{"filter":{"within":"soon"}}`:   http.StatusBadRequest,
+		`{"ids":["missing"]}`:            http.StatusNotFound,
+		`{"filter":{"status":["DONE"]}}`: http.StatusNotFound,
+	} {
+		if rr := doRequest(t, h, "POST", "/api/export/epub", body); rr.Code != want {
+			t.Errorf("%s: status = %d, want %d", body, rr.Code, want)
+		}
+	}
+}
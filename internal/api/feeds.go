@@ -0,0 +1,320 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/export"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// feedPath is where feed readers fetch a feed. Which feed is served is
+// decided by the token the reader presents.
+const feedPath = "/feeds/ready.atom"
+
+// maxFeedEntries caps how many items a feed lists.
+const maxFeedEntries = 100
+
+// ---------------------------------------------------------------------------
+// GET /feeds/ready.atom
+// ---------------------------------------------------------------------------
+
+// handleAtomFeed serves the feed whose token is presented. Tokens are never
+// accepted in the URL query, where they would end up in server and proxy
+// logs: readers send them as a Bearer token, or as the password of HTTP
+// Basic auth (any user name), which most feed readers support.
+func (s *Server) handleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	token := feedToken(r)
+	if token == "" {
+		writeFeedUnauthorized(w)
+		return
+	}
+	feed, err := s.store.GetFeedByTokenHash(r.Context(), model.HashFeedToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeFeedUnauthorized(w)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get feed")
+		return
+	}
+
+	var view *model.View
+	if feed.ViewID != nil {
+		view, err = s.store.GetView(r.Context(), *feed.ViewID)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "the feed's saved view no longer exists")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get view")
+			return
+		}
+	}
+
+	var items []model.Item
+	if filter, ok := feed.Filter(view); ok {
+		items, err = s.store.ListItems(r.Context(), filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to list items")
+			return
+		}
+	}
+	if len(items) > maxFeedEntries {
+		items = items[:maxFeedEntries]
+	}
+	entries := make([]model.ItemWithArtifacts, 0, len(items))
+	for _, it := range items {
+		full, err := s.store.GetItem(r.Context(), it.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // deleted meanwhile
+		}
+		if err != nil {
+			slog.Error("feed: get item failed", "feed_id", feed.ID, "item_id", it.ID, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to get item")
+			return
+		}
+		entries = append(entries, *full)
+	}
+
+	body := export.RenderAtom(*feed, entries, feedURL(r))
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	// The ETag, not a modification time, drives conditional requests: an
+	// item restored to READY changes the feed without a newer timestamp.
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+}
+
+// feedToken returns the token of a Bearer or Basic Authorization header.
+func feedToken(r *http.Request) string {
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func writeFeedUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="readdo feeds"`)
+	writeError(w, http.StatusUnauthorized, "a valid feed token is required")
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/feeds
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListFeeds(w http.ResponseWriter, r *http.Request) {
+	feeds, err := s.store.ListFeeds(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list feeds")
+		return
+	}
+	if feeds == nil {
+		feeds = []model.Feed{}
+	}
+	writeJSON(w, http.StatusOK, feeds)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/feeds
+// ---------------------------------------------------------------------------
+
+type feedRequest struct {
+	Name     string   `json:"name"`
+	ViewID   *string  `json:"view_id"`
+	Priority []string `json:"priority"`
+	Tags     []string `json:"tags"`
+}
+
+// feedCreated is the create and rotate response: the only time the token is
+// returned. URL is where readers fetch the feed.
+type feedCreated struct {
+	model.Feed
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+func (s *Server) handleCreateFeed(w http.ResponseWriter, r *http.Request) {
+	var req feedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	token, err := newSecret()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+	feed := model.NewFeed(uuid.New().String(), req.Name, req.ViewID, req.Priority, req.Tags, token)
+	if !s.validateFeed(w, r, &feed) {
+		return
+	}
+
+	if err := s.store.CreateFeed(r.Context(), feed); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create feed")
+		return
+	}
+	writeJSON(w, http.StatusCreated, feedCreated{Feed: feed, Token: token, URL: feedURL(r)})
+}
+
+// validateFeed normalizes the feed's tags and checks it, including that
+// its view exists. It writes the error response and returns false if the
+// feed is invalid.
+func (s *Server) validateFeed(w http.ResponseWriter, r *http.Request, feed *model.Feed) bool {
+	tags, err := model.NormalizeTags(feed.Tags)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	feed.Tags = tags
+	if feed.Priority == nil {
+		feed.Priority = []string{}
+	}
+	if err := feed.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	if feed.ViewID != nil {
+		if _, err := s.store.GetView(r.Context(), *feed.ViewID); errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusBadRequest, "view not found")
+			return false
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get view")
+			return false
+		}
+	}
+	return true
+}
+
+// feedURL returns the absolute URL readers fetch feeds from, without
+// credentials.
+func feedURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + feedPath
+}
+
+// ---------------------------------------------------------------------------
+// PUT /api/feeds/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleUpdateFeed(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req feedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	existing, err := s.store.GetFeed(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "feed not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get feed")
+		return
+	}
+
+	feed := *existing
+	feed.Name = req.Name
+	feed.ViewID = req.ViewID
+	feed.Priority = req.Priority
+	feed.Tags = req.Tags
+	feed.TokenHash = "" // keep the current token
+	if !s.validateFeed(w, r, &feed) {
+		return
+	}
+
+	err = s.store.UpdateFeed(r.Context(), feed)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "feed not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update feed")
+		return
+	}
+
+	updated, err := s.store.GetFeed(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get feed")
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/feeds/{id}/token
+// ---------------------------------------------------------------------------
+
+// handleRotateFeedToken replaces a feed's token; the old one stops working.
+func (s *Server) handleRotateFeedToken(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	existing, err := s.store.GetFeed(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "feed not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get feed")
+		return
+	}
+
+	token, err := newSecret()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+	feed := *existing
+	feed.TokenHash = model.HashFeedToken(token)
+	if err := s.store.UpdateFeed(r.Context(), feed); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update feed")
+		return
+	}
+
+	updated, err := s.store.GetFeed(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get feed")
+		return
+	}
+	writeJSON(w, http.StatusOK, feedCreated{Feed: *updated, Token: token, URL: feedURL(r)})
+}
+
+// ---------------------------------------------------------------------------
+// DELETE /api/feeds/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	err := s.store.DeleteFeed(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "feed not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete feed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": id, "deleted": "true"})
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// getFeed fetches the Atom feed, authenticating with the given header.
+func getFeed(t *testing.T, h http.Handler, auth string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/feeds/ready.atom", nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func createFeed(t *testing.T, h http.Handler, body string) feedCreated {
+	t.Helper()
+	rr := doRequest(t, h, "POST", "/api/feeds", body)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create feed: status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	var created feedCreated
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	return created
+}
+
+func TestAtomFeed(t *testing.T) {
+	srv, s := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	for id, priority := range map[string]string{"first": model.PriorityDoFirst, "skim": model.PrioritySkimIt} {
+		if err := s.CreateItem(ctx, model.NewItem(id, "https://example.com/"+id, id, "example.com", "web", "")); err != nil {
+			t.Fatal(err)
+		}
+		s.UpdateItemStatus(ctx, id, model.StatusReady, nil)
+		if err := s.UpdateItemScoreAndPriority(ctx, id, 80, priority); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.UpsertArtifact(ctx, model.NewArtifact("a-1", "first", model.ArtifactSynthesis, `{"points":["p1"],"insight":"the insight"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	created := createFeed(t, h, `{"name":"Do first","priority":["DO_FIRST"]}`)
+	if created.Token == "" || created.URL != "http://example.com/feeds/ready.atom" {
+		t.Errorf("created = %+v", created)
+	}
+	if strings.Contains(created.URL, created.Token) {
+		t.Error("feed URL contains the token")
+	}
+
+	for _, auth := range []string{"Bearer " + created.Token, "Basic " + base64.StdEncoding.EncodeToString([]byte("reader:"+created.Token))} {
+		rr := getFeed(t, h, auth)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body: %s", auth[:6], rr.Code, rr.Body.String())
+		}
+		if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/atom+xml") {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "urn:readdo:item:first") || strings.Contains(body, "urn:readdo:item:skim") {
+			t.Errorf("feed does not hold exactly the DO_FIRST item:\n%s", body)
+		}
+		if !strings.Contains(body, "the insight") {
+			t.Errorf("feed misses the synthesis:\n%s", body)
+		}
+	}
+
+	// Conditional requests are answered from the ETag.
+	rr := getFeed(t, h, "Bearer "+created.Token)
+	req := httptest.NewRequest("GET", "/feeds/ready.atom", nil)
+	req.Header.Set("Authorization", "Bearer "+created.Token)
+	req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	cond := httptest.NewRecorder()
+	h.ServeHTTP(cond, req)
+	if cond.Code != http.StatusNotModified {
+		t.Errorf("conditional request: status = %d, want 304", cond.Code)
+	}
+
+	// Rotating the token locks out the old one.
+	rr = doRequest(t, h, "POST", "/api/feeds/"+created.ID+"/token", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("rotate: status = %d", rr.Code)
+	}
+	var rotated feedCreated
+	json.Unmarshal(rr.Body.Bytes(), &rotated)
+	if rr := getFeed(t, h, "Bearer "+created.Token); rr.Code != http.StatusUnauthorized {
+		t.Errorf("old token: status = %d, want 401", rr.Code)
+	}
+	if rr := getFeed(t, h, "Bearer "+rotated.Token); rr.Code != http.StatusOK {
+		t.Errorf("new token: status = %d, want 200", rr.Code)
+	}
+}
+
+func TestAtomFeed_ViewAndFeedFilters(t *testing.T) {
+	srv, s := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	for _, it := range []struct{ id, priority, tag string }{
+		{"first-go", model.PriorityDoFirst, "go"},
+		{"first-db", model.PriorityDoFirst, "db"},
+		{"plan-go", model.PriorityPlanIt, "go"},
+		{"skim-go", model.PrioritySkimIt, "go"},
+	} {
+		if err := s.CreateItem(ctx, model.NewItem(it.id, "https://example.com/"+it.id, it.id, "example.com", "web", "")); err != nil {
+			t.Fatal(err)
+		}
+		s.UpdateItemStatus(ctx, it.id, model.StatusReady, nil)
+		if err := s.UpdateItemScoreAndPriority(ctx, it.id, 80, it.priority); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetItemTags(ctx, it.id, []string{it.tag}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rr := doRequest(t, h, "POST", "/api/views", `{"name":"Important","filter":{"priority":["DO_FIRST","PLAN_IT"],"tags":["go","db"]}}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create view: status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	viewID := decodeJSON(t, rr)["id"].(string)
+
+	feedItems := func(body string) []string {
+		t.Helper()
+		created := createFeed(t, h, body)
+		rr := getFeed(t, h, "Bearer "+created.Token)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("feed: status = %d, body: %s", rr.Code, rr.Body.String())
+		}
+		var ids []string
+		for _, id := range []string{"first-go", "first-db", "plan-go", "skim-go"} {
+			if strings.Contains(rr.Body.String(), "urn:readdo:item:"+id+"<") {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+
+	// The feed's priorities narrow the view's rather than replacing them,
+	// and its tags apply on top of the view's.
+	if got := feedItems(`{"name":"a","view_id":"` + viewID + `","priority":["DO_FIRST","SKIM_IT"]}`); !slices.Equal(got, []string{"first-go", "first-db"}) {
+		t.Errorf("view + feed priorities: items = %v", got)
+	}
+	if got := feedItems(`{"name":"b","view_id":"` + viewID + `","tags":["go"]}`); !slices.Equal(got, []string{"first-go", "plan-go"}) {
+		t.Errorf("view + feed tags: items = %v", got)
+	}
+	if got := feedItems(`{"name":"c","view_id":"` + viewID + `","priority":["SKIM_IT"]}`); len(got) != 0 {
+		t.Errorf("disjoint priorities: items = %v, want none", got)
+	}
+}
+
+func TestAtomFeed_Unauthorized(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+	createFeed(t, h, `{"name":"All"}`)
+
+	for _, auth := range []string{"", "Bearer wrong", "Token abc"} {
+		rr := getFeed(t, h, auth)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("auth %q: status = %d, want 401", auth, rr.Code)
+		}
+		if rr.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("auth %q: no WWW-Authenticate challenge", auth)
+		}
+	}
+	// Tokens in the query string are not accepted.
+	rr := doRequest(t, h, "GET", "/feeds/ready.atom?token=anything", "")
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("query token: status = %d, want 401", rr.Code)
+	}
+}
+
+func TestFeedCRUD(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	for _, body := range []string{`{"name":""}`, `{"name":"x","priority":["SOMEDAY"]}`, `{"name":"x","view_id":"missing"}`} {
+		if rr := doRequest(t, h, "POST", "/api/feeds", body); rr.Code != http.StatusBadRequest {
+			t.Errorf("create %s: status = %d, want 400", body, rr.Code)
+		}
+	}
+
+	rr := doRequest(t, h, "POST", "/api/views", `{"name":"Go","filter":{"tags":["go"]}}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create view: status = %d", rr.Code)
+	}
+	viewID := decodeJSON(t, rr)["id"].(string)
+
+	created := createFeed(t, h, `{"name":"Go feed","view_id":"`+viewID+`","tags":["Go "]}`)
+	if len(created.Tags) != 1 || created.Tags[0] != "go" {
+		t.Errorf("tags = %v, want normalized", created.Tags)
+	}
+
+	rr = doRequest(t, h, "PUT", "/api/feeds/"+created.ID, `{"name":"Renamed","priority":["PLAN_IT"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if result := decodeJSON(t, rr); result["name"] != "Renamed" || result["view_id"] != nil || result["token_hash"] != nil {
+		t.Errorf("updated = %v", result)
+	}
+	// The token survives an update.
+	if rr := getFeed(t, h, "Bearer "+created.Token); rr.Code != http.StatusOK {
+		t.Errorf("feed after update: status = %d", rr.Code)
+	}
+
+	rr = doRequest(t, h, "GET", "/api/feeds", "")
+	if !strings.Contains(rr.Body.String(), "Renamed") || strings.Contains(rr.Body.String(), created.Token) {
+		t.Errorf("list = %s", rr.Body.String())
+	}
+
+	if rr := doRequest(t, h, "DELETE", "/api/feeds/"+created.ID, ""); rr.Code != http.StatusOK {
+		t.Errorf("delete: status = %d", rr.Code)
+	}
+	if rr := doRequest(t, h, "DELETE", "/api/feeds/"+created.ID, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("second delete: status = %d, want 404", rr.Code)
+	}
+	if rr := getFeed(t, h, "Bearer "+created.Token); rr.Code != http.StatusUnauthorized {
+		t.Errorf("deleted feed: status = %d, want 401", rr.Code)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/store"
 )
 
 // ---------------------------------------------------------------------------
@@ -17,11 +18,15 @@ import (
 // ---------------------------------------------------------------------------
 
 type captureRequest struct {
-	URL        string `json:"url"`
-	Title      string `json:"title"`
-	Domain     string `json:"domain"`
-	SourceType string `json:"source_type"`
-	IntentText string `json:"intent_text"`
+	URL        string   `json:"url"`
+	Title      string   `json:"title"`
+	Domain     string   `json:"domain"`
+	SourceType string   `json:"source_type"`
+	IntentText string   `json:"intent_text"`
+	Tags       []string `json:"tags"`
+	// Revive, true unless set, brings an archived item with the same URL
+	// back instead of saving the URL as a new item.
+	Revive *bool `json:"revive"`
 }
 
 func (s *Server) handleCapture(w http.ResponseWriter, r *http.Request) {
@@ -37,6 +42,11 @@ func (s *Server) handleCapture(w http.ResponseWriter, r *http.Request) {
 	if req.SourceType == "" {
 		req.SourceType = "web"
 	}
+	tags, err := model.NormalizeTags(req.Tags)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	// Auto-extract domain if not provided.
 	if req.Domain == "" {
 		if u, err := url.Parse(req.URL); err == nil {
@@ -44,26 +54,43 @@ func (s *Server) handleCapture(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Check if an active (non-ARCHIVED) item already exists for this URL.
-	// If so, merge the new intent and re-queue for processing instead of rejecting.
+	// Check if an active (non-ARCHIVED) item already exists for this URL, or
+	// failing that an archived one to revive. If so, merge the new intent and
+	// re-queue for processing instead of rejecting.
 	existing, err := s.store.FindItemByURL(r.Context(), req.URL)
+	revived := false
+	if (err != nil || existing == nil) && (req.Revive == nil || *req.Revive) {
+		existing, err = s.store.FindArchivedItemByURL(r.Context(), req.URL)
+		revived = err == nil && existing != nil
+	}
 	if err == nil && existing != nil {
 		existing.MergeIntent(req.IntentText)
-		if err := s.store.UpdateItemForReprocess(r.Context(), existing.ID, existing.IntentText, existing.SaveCount); err != nil {
+		update := s.store.UpdateItemForReprocess
+		if revived {
+			update = s.store.ReviveItem
+		}
+		// The intent is also recorded as a separate timestamped entry.
+		if err := update(r.Context(), existing.ID, existing.IntentText, existing.SaveCount, captureIntents(existing.ID, req.IntentText)...); err != nil {
 			writeError(w, http.StatusInternalServerError, "failed to update item")
 			return
 		}
-		// Record the intent as a separate timestamped entry.
-		if req.IntentText != "" {
-			intent := model.NewIntent(uuid.New().String(), existing.ID, req.IntentText)
-			_ = s.store.CreateIntent(r.Context(), intent) // best-effort
+		if err := s.store.AddItemTags(r.Context(), existing.ID, tags); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save tags")
+			return
+		}
+		message := "intent merged, item re-queued for processing"
+		if revived {
+			s.publish(model.NewEvent(model.EventItemRevived, existing.ID))
+			message = "archived item revived, intent merged and re-queued for processing"
 		}
+		s.publish(model.StatusEvent(existing.ID, model.StatusCaptured))
 		writeJSON(w, http.StatusOK, map[string]any{
 			"id":         existing.ID,
 			"status":     model.StatusCaptured,
 			"merged":     true,
+			"revived":    revived,
 			"save_count": existing.SaveCount,
-			"message":    "intent merged, item re-queued for processing",
+			"message":    message,
 		})
 		return
 	}
@@ -77,34 +104,56 @@ func (s *Server) handleCapture(w http.ResponseWriter, r *http.Request) {
 		req.IntentText,
 	)
 
-	if err := s.store.CreateItem(r.Context(), item); err != nil {
+	// The initial intent is also recorded as a separate timestamped entry.
+	if err := s.store.CreateItem(r.Context(), item, captureIntents(item.ID, req.IntentText)...); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to create item")
 		return
 	}
 
-	// Record the initial intent as a separate timestamped entry.
-	if req.IntentText != "" {
-		intent := model.NewIntent(uuid.New().String(), item.ID, req.IntentText)
-		_ = s.store.CreateIntent(r.Context(), intent) // best-effort
+	if err := s.store.AddItemTags(r.Context(), item.ID, tags); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to save tags")
+		return
 	}
+	s.publish(model.StatusEvent(item.ID, item.Status))
 
 	writeJSON(w, http.StatusCreated, map[string]any{
 		"id":         item.ID,
 		"status":     item.Status,
 		"merged":     false,
+		"revived":    false,
 		"save_count": item.SaveCount,
 	})
 }
 
+// captureIntents returns the intent entry recording a capture's intent
+// text, if it has one.
+func captureIntents(itemID, text string) []model.Intent {
+	if text == "" {
+		return nil
+	}
+	return []model.Intent{model.NewIntent(uuid.New().String(), itemID, text)}
+}
+
 // ---------------------------------------------------------------------------
 // GET /api/items
 // ---------------------------------------------------------------------------
 
 func (s *Server) handleListItems(w http.ResponseWriter, r *http.Request) {
+	tags, err := model.NormalizeTags(splitComma(r.URL.Query().Get("tag")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	filter := model.ItemFilter{
 		Status:   splitComma(r.URL.Query().Get("status")),
 		Priority: splitComma(r.URL.Query().Get("priority")),
 		Query:    r.URL.Query().Get("q"),
+		Tags:     tags,
+		Within:   r.URL.Query().Get("within"),
+	}
+	if err := filter.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	items, err := s.store.ListItems(r.Context(), filter)
@@ -115,6 +164,12 @@ func (s *Server) handleListItems(w http.ResponseWriter, r *http.Request) {
 	if items == nil {
 		items = []model.Item{}
 	}
+	if r.URL.Query().Get("sort") == "rank" {
+		if err := s.sortByRank(r.Context(), items); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to rank items")
+			return
+		}
+	}
 	writeJSON(w, http.StatusOK, items)
 }
 
@@ -168,6 +223,7 @@ func (s *Server) handleDeleteItem(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to delete item")
 		return
 	}
+	s.publish(model.NewEvent(model.EventItemDeleted, id))
 
 	writeJSON(w, http.StatusOK, map[string]string{"id": id, "deleted": "true"})
 }
@@ -198,6 +254,7 @@ func (s *Server) handleRetry(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to update status")
 		return
 	}
+	s.publish(model.StatusEvent(id, model.StatusCaptured))
 
 	writeJSON(w, http.StatusOK, map[string]string{"id": id, "status": model.StatusCaptured})
 }
@@ -229,6 +286,7 @@ func (s *Server) handleReprocess(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to update status")
 		return
 	}
+	s.publish(model.StatusEvent(id, model.StatusCaptured))
 
 	writeJSON(w, http.StatusOK, map[string]string{"id": id, "status": model.StatusCaptured})
 }
@@ -238,7 +296,8 @@ func (s *Server) handleReprocess(w http.ResponseWriter, r *http.Request) {
 // ---------------------------------------------------------------------------
 
 type statusRequest struct {
-	Status string `json:"status"` // "ARCHIVED" or "READY" (restore)
+	Status      string `json:"status"`                 // "ARCHIVED", "READY" (restore), "DONE" or "SNOOZED"
+	SnoozeUntil string `json:"snooze_until,omitempty"` // required for SNOOZED: RFC 3339 or YYYY-MM-DD
 }
 
 func (s *Server) handleUpdateStatus(w http.ResponseWriter, r *http.Request) {
@@ -266,10 +325,26 @@ func (s *Server) handleUpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Status == model.StatusSnoozed {
+		until, err := model.ParseSnoozeUntil(req.SnoozeUntil, time.Now())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, err := s.store.SnoozeItems(r.Context(), []string{id}, until); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to update status")
+			return
+		}
+		s.publish(model.StatusEvent(id, req.Status))
+		writeJSON(w, http.StatusOK, map[string]string{"id": id, "status": req.Status, "snooze_until": until})
+		return
+	}
+
 	if err := s.store.UpdateItemStatus(r.Context(), id, req.Status, nil); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to update status")
 		return
 	}
+	s.publish(model.StatusEvent(id, req.Status))
 
 	writeJSON(w, http.StatusOK, map[string]string{"id": id, "status": req.Status})
 }
@@ -282,16 +357,18 @@ type editArtifactRequest struct {
 	Payload json.RawMessage `json:"payload"`
 }
 
+// editableArtifacts are the artifact types the user can edit.
+var editableArtifacts = map[string]bool{
+	model.ArtifactSynthesis: true,
+	model.ArtifactTodos:     true,
+}
+
 func (s *Server) handleEditArtifact(w http.ResponseWriter, r *http.Request) {
 	itemID := r.PathValue("id")
 	artifactType := r.PathValue("type")
 
 	// Validate artifact type.
-	validTypes := map[string]bool{
-		model.ArtifactSynthesis: true,
-		model.ArtifactTodos:     true,
-	}
-	if !validTypes[artifactType] {
+	if !editableArtifacts[artifactType] {
 		writeError(w, http.StatusBadRequest, "only synthesis and todos can be edited")
 		return
 	}
@@ -315,6 +392,19 @@ func (s *Server) handleEditArtifact(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid JSON body")
 		return
 	}
+	// Edited todos are synced into the todos table, so they must parse.
+	if artifactType == model.ArtifactTodos {
+		var todos struct {
+			Todos []struct {
+				Title string `json:"title"`
+				Done  bool   `json:"done"`
+			} `json:"todos"`
+		}
+		if err := json.Unmarshal(req.Payload, &todos); err != nil {
+			writeError(w, http.StatusBadRequest, `todos payload must be {"todos": [...]}`)
+			return
+		}
+	}
 
 	artifact := model.Artifact{
 		ID:           uuid.New().String(),
@@ -329,21 +419,44 @@ func (s *Server) handleEditArtifact(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to save artifact")
 		return
 	}
+	s.publish(model.NewEvent(model.EventItemUpdated, itemID))
 
-	writeJSON(w, http.StatusOK, artifact)
+	// Read it back for the version number the store assigned.
+	saved, err := s.store.GetArtifact(r.Context(), itemID, artifactType)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get artifact")
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
 }
 
 // ---------------------------------------------------------------------------
 // GET /api/stats
 // ---------------------------------------------------------------------------
 
+type statsResponse struct {
+	store.StatusCounts
+	Views []store.ViewCount `json:"views"`
+	Tags  []store.TagCount  `json:"tags"`
+}
+
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	counts, err := s.store.CountByStatus(r.Context())
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to get stats")
 		return
 	}
-	writeJSON(w, http.StatusOK, counts)
+	views, err := s.store.CountViews(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get stats")
+		return
+	}
+	tags, err := s.store.CountTags(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get stats")
+		return
+	}
+	writeJSON(w, http.StatusOK, statsResponse{StatusCounts: counts, Views: views, Tags: tags})
 }
 
 // ---------------------------------------------------------------------------
@@ -351,8 +464,9 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 // ---------------------------------------------------------------------------
 
 type batchStatusRequest struct {
-	IDs    []string `json:"ids"`
-	Status string   `json:"status"`
+	IDs         []string `json:"ids"`
+	Status      string   `json:"status"`
+	SnoozeUntil string   `json:"snooze_until,omitempty"` // required for SNOOZED
 }
 
 func (s *Server) handleBatchStatus(w http.ResponseWriter, r *http.Request) {
@@ -365,18 +479,40 @@ func (s *Server) handleBatchStatus(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "ids is required")
 		return
 	}
-	if req.Status != model.StatusArchived && req.Status != model.StatusReady {
-		writeError(w, http.StatusBadRequest, "status must be ARCHIVED or READY")
+	if !model.IsUserSettableStatus(req.Status) {
+		writeError(w, http.StatusBadRequest, "status must be ARCHIVED, READY, DONE or SNOOZED")
+		return
+	}
+
+	if req.Status == model.StatusSnoozed {
+		until, err := model.ParseSnoozeUntil(req.SnoozeUntil, time.Now())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Only READY items can be snoozed; others are skipped.
+		snoozed, err := s.store.SnoozeItems(r.Context(), req.IDs, until)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to update items")
+			return
+		}
+		for _, id := range snoozed {
+			s.publish(model.StatusEvent(id, req.Status))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"updated": len(snoozed), "snooze_until": until})
 		return
 	}
 
-	n, err := s.store.BatchUpdateStatus(r.Context(), req.IDs, req.Status)
+	updated, err := s.store.BatchUpdateStatus(r.Context(), req.IDs, req.Status)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to update items")
 		return
 	}
+	for _, id := range updated {
+		s.publish(model.StatusEvent(id, req.Status))
+	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"updated": n})
+	writeJSON(w, http.StatusOK, map[string]any{"updated": len(updated)})
 }
 
 // ---------------------------------------------------------------------------
@@ -398,11 +534,14 @@ func (s *Server) handleBatchDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	n, err := s.store.BatchDeleteItems(r.Context(), req.IDs)
+	deleted, err := s.store.BatchDeleteItems(r.Context(), req.IDs)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to delete items")
 		return
 	}
+	for _, id := range deleted {
+		s.publish(model.NewEvent(model.EventItemDeleted, id))
+	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"deleted": n})
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": len(deleted)})
 }
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/yangwenmai/readdo/internal/events"
 	"github.com/yangwenmai/readdo/internal/model"
 	"github.com/yangwenmai/readdo/internal/store"
 )
@@ -99,6 +101,50 @@ func TestCapture_DuplicateMerge(t *testing.T) {
 	}
 }
 
+func TestCapture_RevivesArchived(t *testing.T) {
+	_, st := newTestServer(t)
+	bus := events.NewBus(10)
+	srv := New(st, WithEvents(bus))
+	h := srv.Handler()
+	ctx := context.Background()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/old","intent_text":"first"}`)
+	id := decodeJSON(t, rr)["id"].(string)
+	st.UpdateItemStatus(ctx, id, model.StatusArchived, nil)
+	sub, _, _ := bus.Subscribe(0)
+	defer sub.Close()
+
+	rr = doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/old/","intent_text":"again"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rr.Code)
+	}
+	if result := decodeJSON(t, rr); result["id"] != id || result["revived"] != true || result["save_count"] != float64(2) {
+		t.Errorf("capture = %v", result)
+	}
+	got, _ := st.GetItem(ctx, id)
+	if got.Status != model.StatusCaptured || got.IntentText != "first\n---\nagain" || got.RestoredAt == nil || len(got.Intents) != 2 {
+		t.Errorf("revived item = %+v", got.Item)
+	}
+	// The revival is recorded on the item, not only announced on the bus.
+	rr = doRequest(t, h, "GET", "/api/items/"+id, "")
+	if revivedAt, _ := decodeJSON(t, rr)["revived_at"].(string); revivedAt == "" {
+		t.Errorf("GET item: revived_at missing, body: %s", rr.Body.String())
+	}
+	if e := <-sub.C; e.Type != model.EventItemRevived || e.ItemID != id {
+		t.Errorf("first event = %+v, want %s", e, model.EventItemRevived)
+	}
+	if e := <-sub.C; e.Type != model.EventItemCaptured {
+		t.Errorf("second event = %+v, want %s", e, model.EventItemCaptured)
+	}
+
+	// With revive off, the archived item stays archived and a new one is saved.
+	st.UpdateItemStatus(ctx, id, model.StatusArchived, nil)
+	rr = doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/old","revive":false}`)
+	if rr.Code != http.StatusCreated || decodeJSON(t, rr)["id"] == id {
+		t.Errorf("capture without revive: status = %d", rr.Code)
+	}
+}
+
 func TestListItems(t *testing.T) {
 	srv, _ := newTestServer(t)
 	h := srv.Handler()
@@ -213,6 +259,93 @@ func TestBatchStatus(t *testing.T) {
 	}
 }
 
+func TestUpdateStatus_Done(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com"}`)
+	id := decodeJSON(t, rr)["id"].(string)
+
+	rr = doRequest(t, h, "PATCH", "/api/items/"+id+"/status", `{"status":"DONE"}`)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("CAPTURED→DONE status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	st.UpdateItemStatus(context.Background(), id, model.StatusReady, nil)
+	rr = doRequest(t, h, "PATCH", "/api/items/"+id+"/status", `{"status":"DONE"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("READY→DONE status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	rr = doRequest(t, h, "GET", "/api/items/"+id, "")
+	if got := decodeJSON(t, rr)["completed_at"]; got == nil {
+		t.Error("completed_at should be set after DONE")
+	}
+}
+
+func TestUpdateStatus_Snooze(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com"}`)
+	id := decodeJSON(t, rr)["id"].(string)
+	st.UpdateItemStatus(context.Background(), id, model.StatusReady, nil)
+
+	rr = doRequest(t, h, "PATCH", "/api/items/"+id+"/status", `{"status":"SNOOZED"}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("missing snooze_until status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+	rr = doRequest(t, h, "PATCH", "/api/items/"+id+"/status", `{"status":"SNOOZED","snooze_until":"2000-01-01"}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("past snooze_until status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+
+	rr = doRequest(t, h, "PATCH", "/api/items/"+id+"/status", `{"status":"SNOOZED","snooze_until":"2999-01-01"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("READY→SNOOZED status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := decodeJSON(t, rr)["snooze_until"]; got != "2999-01-01T00:00:00Z" {
+		t.Errorf("snooze_until = %v, want 2999-01-01T00:00:00Z", got)
+	}
+
+	// Hidden from the default inbox list.
+	rr = doRequest(t, h, "GET", "/api/items", "")
+	var items []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &items)
+	if len(items) != 0 {
+		t.Errorf("default list = %d items, want 0", len(items))
+	}
+
+	// Waking up early by restoring to READY clears snooze_until.
+	rr = doRequest(t, h, "PATCH", "/api/items/"+id+"/status", `{"status":"READY"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("SNOOZED→READY status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	rr = doRequest(t, h, "GET", "/api/items/"+id, "")
+	if got := decodeJSON(t, rr)["snooze_until"]; got != nil {
+		t.Errorf("snooze_until = %v, want nil after wake", got)
+	}
+}
+
+func TestBatchStatus_Snooze(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+
+	rr1 := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/1"}`)
+	rr2 := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/2"}`)
+	id1 := decodeJSON(t, rr1)["id"].(string)
+	id2 := decodeJSON(t, rr2)["id"].(string)
+	st.UpdateItemStatus(context.Background(), id1, model.StatusReady, nil)
+
+	body := `{"ids":["` + id1 + `","` + id2 + `"],"status":"SNOOZED","snooze_until":"2999-01-01"}`
+	rr := doRequest(t, h, "POST", "/api/items/batch/status", body)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := decodeJSON(t, rr)["updated"]; got != float64(1) {
+		t.Errorf("updated = %v, want 1 (only READY items)", got)
+	}
+}
+
 func TestBatchStatus_InvalidStatus(t *testing.T) {
 	srv, _ := newTestServer(t)
 	h := srv.Handler()
@@ -256,6 +389,35 @@ func TestStats(t *testing.T) {
 	}
 }
 
+func TestBatch_PublishesOnlyChangedItems(t *testing.T) {
+	_, st := newTestServer(t)
+	bus := events.NewBus(10)
+	h := New(st, WithEvents(bus)).Handler()
+	ctx := context.Background()
+	for id, status := range map[string]string{"ready": model.StatusReady, "archived": model.StatusArchived, "captured": model.StatusCaptured} {
+		item := model.NewItem(id, "https://example.com/"+id, id, "example.com", "web", "")
+		item.Status = status
+		st.CreateItem(ctx, item)
+	}
+	sub, _, _ := bus.Subscribe(0)
+	defer sub.Close()
+
+	doRequest(t, h, "POST", "/api/items/batch/status", `{"ids":["archived","missing"],"status":"ARCHIVED"}`)
+	doRequest(t, h, "POST", "/api/items/batch/status", `{"ids":["ready","captured"],"status":"SNOOZED","snooze_until":"2099-01-01"}`)
+	doRequest(t, h, "POST", "/api/items/batch/tags", `{"ids":["ready","missing"],"add":["go"]}`)
+	doRequest(t, h, "POST", "/api/items/batch/delete", `{"ids":["captured","missing"]}`)
+
+	var got []string
+	for len(sub.C) > 0 {
+		e := <-sub.C
+		got = append(got, e.Type+" "+e.ItemID)
+	}
+	want := []string{model.EventStatusChanged + " ready", model.EventItemUpdated + " ready", model.EventItemDeleted + " captured"}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
 func TestBatchDelete(t *testing.T) {
 	srv, _ := newTestServer(t)
 	h := srv.Handler()
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// historyResponse lists every version of an artifact, newest first.
+type historyResponse struct {
+	CurrentVersion  int              `json:"current_version"`
+	ProposedVersion int              `json:"proposed_version,omitempty"`
+	Versions        []model.Artifact `json:"versions"`
+}
+
+// versionDiffResponse lists the changes from one version of an artifact to
+// another.
+type versionDiffResponse struct {
+	From int                `json:"from"`
+	To   int                `json:"to"`
+	Diff []model.JSONChange `json:"diff"`
+}
+
+// parseVersion parses a version number from a path or query value.
+func parseVersion(v string) (int, bool) {
+	n, err := strconv.Atoi(v)
+	return n, err == nil && n >= 1
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/items/{id}/artifacts/{type}/history
+// ---------------------------------------------------------------------------
+
+// handleArtifactHistory lists every generation and edit of an artifact with
+// who made it, and for generations the model and prompt version used.
+func (s *Server) handleArtifactHistory(w http.ResponseWriter, r *http.Request) {
+	itemID, artifactType := r.PathValue("id"), r.PathValue("type")
+	if !model.HasHistory(artifactType) {
+		writeError(w, http.StatusBadRequest, "no history is kept for "+artifactType)
+		return
+	}
+	current, err := s.store.GetArtifact(r.Context(), itemID, artifactType)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "artifact not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get artifact")
+		return
+	}
+	resp := historyResponse{CurrentVersion: current.Version}
+	proposed, err := s.store.GetArtifactProposal(r.Context(), itemID, artifactType)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusInternalServerError, "failed to get proposal")
+		return
+	}
+	if proposed != nil {
+		resp.ProposedVersion = proposed.Version
+	}
+	resp.Versions, err = s.store.ListArtifactVersions(r.Context(), itemID, artifactType)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list versions")
+		return
+	}
+	if resp.Versions == nil {
+		resp.Versions = []model.Artifact{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/items/{id}/artifacts/{type}/diff?from=N&to=M
+// ---------------------------------------------------------------------------
+
+// handleArtifactDiff compares two versions of an artifact. to defaults to
+// the current version.
+func (s *Server) handleArtifactDiff(w http.ResponseWriter, r *http.Request) {
+	itemID, artifactType := r.PathValue("id"), r.PathValue("type")
+	q := r.URL.Query()
+	from, ok := parseVersion(q.Get("from"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "from must be a version number")
+		return
+	}
+	to := 0
+	if v := q.Get("to"); v != "" {
+		if to, ok = parseVersion(v); !ok {
+			writeError(w, http.StatusBadRequest, "to must be a version number")
+			return
+		}
+	} else {
+		current, err := s.store.GetArtifact(r.Context(), itemID, artifactType)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "artifact not found")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get artifact")
+			return
+		}
+		to = current.Version
+	}
+
+	var payloads [2]string
+	for i, version := range []int{from, to} {
+		a, err := s.store.GetArtifactVersion(r.Context(), itemID, artifactType, version)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "version "+strconv.Itoa(version)+" not found")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get version")
+			return
+		}
+		payloads[i] = a.Payload
+	}
+	diff, err := model.DiffJSON(payloads[0], payloads[1])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to compare versions")
+		return
+	}
+	if diff == nil {
+		diff = []model.JSONChange{}
+	}
+	writeJSON(w, http.StatusOK, versionDiffResponse{From: from, To: to, Diff: diff})
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/items/{id}/artifacts/{type}/revert/{version}
+// ---------------------------------------------------------------------------
+
+// handleRevertArtifact restores an earlier version of an artifact. The
+// restored content becomes a new version, as if the user had edited it
+// back.
+func (s *Server) handleRevertArtifact(w http.ResponseWriter, r *http.Request) {
+	itemID, artifactType := r.PathValue("id"), r.PathValue("type")
+	if !editableArtifacts[artifactType] {
+		writeError(w, http.StatusBadRequest, "only synthesis and todos can be reverted")
+		return
+	}
+	version, ok := parseVersion(r.PathValue("version"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "version must be a version number")
+		return
+	}
+
+	item, err := s.store.GetItem(r.Context(), itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "item not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get item")
+		return
+	}
+	if item.Status != model.StatusReady {
+		writeError(w, http.StatusConflict, "can only revert artifacts of READY items")
+		return
+	}
+
+	artifact, err := s.store.RevertArtifact(r.Context(), itemID, artifactType, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "version not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to revert artifact")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, itemID))
+	writeJSON(w, http.StatusOK, artifact)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/yangwenmai/readdo/internal/importer"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// POST /api/import
+// ---------------------------------------------------------------------------
+
+// handleImport imports a bookmark export sent either as the raw request
+// body or as the "file" field of a multipart form. The format is detected
+// unless ?format= names it; ?tag= adds tags to every imported item.
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format != "" && !importer.IsFormat(format) {
+		writeError(w, http.StatusBadRequest, "format must be one of netscape, pocket-html, pocket-csv, instapaper, raindrop")
+		return
+	}
+	tags, err := model.NormalizeTags(splitComma(q.Get("tag")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := readImportFile(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "export file exceeds 32 MB")
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format, bookmarks, err := importer.Parse(data, format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rep, err := s.importer.Import(r.Context(), format, bookmarks, tags)
+	if err != nil {
+		slog.Error("import failed", "format", format, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to import bookmarks")
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// readImportFile returns the uploaded export, from the multipart "file"
+// field if the request is a form upload and from the body otherwise.
+func readImportFile(r *http.Request) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		data, err := io.ReadAll(r.Body)
+		if err == nil && len(data) == 0 {
+			err = errors.New("request body is empty")
+		}
+		return data, err
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, errors.New(`multipart form has no "file" field`)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			defer part.Close()
+			return io.ReadAll(part)
+		}
+		part.Close()
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+const pocketCSV = "title,url,time_added,tags,status\n" +
+	"One,https://example.com/1,1650000000,go|db,unread\n" +
+	"Dup,https://example.com/dup,1650000001,,archive\n"
+
+func TestImport_RawBody(t *testing.T) {
+	srv, s := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/dup","intent_text":"first"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("capture status = %d", rr.Code)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/import?tag=Pocket", pocketCSV)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body: %s", rr.Code, rr.Body.String())
+	}
+	result := decodeJSON(t, rr)
+	if result["format"] != "pocket-csv" || result["created"] != 1.0 || result["merged"] != 1.0 || result["failed"] != 0.0 {
+		t.Errorf("report = %v", result)
+	}
+
+	item, err := s.FindItemByURL(context.Background(), "https://example.com/1")
+	if err != nil || item == nil {
+		t.Fatalf("imported item not found: %v", err)
+	}
+	if item.CreatedAt != "2022-04-15T05:20:00Z" {
+		t.Errorf("created_at = %s, want the Pocket save date", item.CreatedAt)
+	}
+	full, _ := s.GetItem(context.Background(), item.ID)
+	if strings.Join(full.Tags, ",") != "db,go,pocket" {
+		t.Errorf("tags = %v", full.Tags)
+	}
+	dup, _ := s.FindItemByURL(context.Background(), "https://example.com/dup")
+	if dup.SaveCount != 2 || dup.Status != model.StatusCaptured {
+		t.Errorf("merged item = %+v", dup)
+	}
+}
+
+func TestImport_Multipart(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("note", "ignored")
+	fw, _ := mw.CreateFormFile("file", "bookmarks.html")
+	fw.Write([]byte(`<DL><p><DT><H3>Go</H3><DL><p><DT><A HREF="https://go.dev/" ADD_DATE="1700000000">Go</A></DL><p></DL>`))
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/api/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body: %s", rr.Code, rr.Body.String())
+	}
+	if result := decodeJSON(t, rr); result["format"] != "netscape" || result["created"] != 1.0 {
+		t.Errorf("report = %v", result)
+	}
+}
+
+func TestImport_BadRequests(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	tests := []struct {
+		name, path, body string
+	}{
+		{"empty body", "/api/import", ""},
+		{"unknown format", "/api/import?format=delicious", pocketCSV},
+		{"undetectable", "/api/import", "hello world\n"},
+		{"bad tag", "/api/import?tag=" + strings.Repeat("x", 60), pocketCSV},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := doRequest(t, h, "POST", tt.path, tt.body)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400, body: %s", rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	// Exports may be larger than other request bodies, but not unbounded.
+	rr := doRequest(t, h, "POST", "/api/import", strings.Repeat("a", int(maxImportBody)+1))
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized export: status = %d, want 413", rr.Code)
+	}
+}
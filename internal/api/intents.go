@@ -0,0 +1,223 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// intentRequest is the body of an intent creation or edit.
+type intentRequest struct {
+	Text string `json:"text"`
+}
+
+// intentResponse is an intent together with its item's status after the
+// change, which is CAPTURED when the item was re-queued with ?rescore=true.
+type intentResponse struct {
+	model.Intent
+	ItemStatus string `json:"item_status"`
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/items/{id}/intents
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListIntents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.intentItem(w, r, id); !ok {
+		return
+	}
+	intents, err := s.store.ListIntents(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list intents")
+		return
+	}
+	if intents == nil {
+		intents = []model.Intent{}
+	}
+	writeJSON(w, http.StatusOK, intents)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/items/{id}/intents
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleCreateIntent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	text, rescore, ok := parseIntentRequest(w, r)
+	if !ok {
+		return
+	}
+	item, ok := s.intentItem(w, r, id)
+	if !ok || !canRescore(w, item, rescore) {
+		return
+	}
+
+	intent := model.NewIntent(uuid.New().String(), id, text)
+	if err := s.store.CreateIntent(r.Context(), intent); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create intent")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, id))
+
+	status, err := s.requeueForIntents(r, item, rescore)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update status")
+		return
+	}
+	writeJSON(w, http.StatusCreated, intentResponse{Intent: intent, ItemStatus: status})
+}
+
+// ---------------------------------------------------------------------------
+// PATCH /api/items/{id}/intents/{intent_id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleUpdateIntent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	text, rescore, ok := parseIntentRequest(w, r)
+	if !ok {
+		return
+	}
+	item, ok := s.intentItem(w, r, id)
+	if !ok || !canRescore(w, item, rescore) {
+		return
+	}
+
+	intent, err := s.store.UpdateIntent(r.Context(), id, r.PathValue("intent_id"), text)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "intent not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update intent")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, id))
+
+	status, err := s.requeueForIntents(r, item, rescore)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update status")
+		return
+	}
+	writeJSON(w, http.StatusOK, intentResponse{Intent: *intent, ItemStatus: status})
+}
+
+// ---------------------------------------------------------------------------
+// DELETE /api/items/{id}/intents/{intent_id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleDeleteIntent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	intentID := r.PathValue("intent_id")
+	rescore, ok := parseRescore(w, r)
+	if !ok {
+		return
+	}
+	item, ok := s.intentItem(w, r, id)
+	if !ok || !canRescore(w, item, rescore) {
+		return
+	}
+
+	err := s.store.DeleteIntent(r.Context(), id, intentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "intent not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete intent")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, id))
+
+	status, err := s.requeueForIntents(r, item, rescore)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update status")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": intentID, "deleted": "true", "item_status": status})
+}
+
+// intentItem fetches the item whose intents are being accessed, writing a
+// 404 if it does not exist.
+func (s *Server) intentItem(w http.ResponseWriter, r *http.Request, id string) (*model.ItemWithArtifacts, bool) {
+	item, err := s.store.GetItem(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "item not found")
+		return nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get item")
+		return nil, false
+	}
+	return item, true
+}
+
+// parseIntentRequest reads the intent text from the body and the rescore
+// option from the query, writing a 400 if either is invalid.
+func parseIntentRequest(w http.ResponseWriter, r *http.Request) (text string, rescore, ok bool) {
+	rescore, ok = parseRescore(w, r)
+	if !ok {
+		return "", false, false
+	}
+	var req intentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return "", false, false
+	}
+	text = strings.TrimSpace(req.Text)
+	if text == "" {
+		writeError(w, http.StatusBadRequest, "text is required")
+		return "", false, false
+	}
+	return text, rescore, true
+}
+
+func parseRescore(w http.ResponseWriter, r *http.Request) (rescore, ok bool) {
+	v := r.URL.Query().Get("rescore")
+	if v == "" {
+		return false, true
+	}
+	rescore, err := strconv.ParseBool(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "rescore must be true or false")
+		return false, false
+	}
+	return rescore, true
+}
+
+// canRescore writes a 409 if rescore is set for an item that is DONE,
+// SNOOZED or ARCHIVED, which a rescore does not re-queue, so that the
+// intents are left unchanged rather than the request silently ignored.
+func canRescore(w http.ResponseWriter, item *model.ItemWithArtifacts, rescore bool) bool {
+	switch {
+	case !rescore:
+		return true
+	case item.Status == model.StatusDone, item.Status == model.StatusSnoozed, item.Status == model.StatusArchived:
+		writeError(w, http.StatusConflict, fmt.Sprintf("cannot rescore a %s item; restore it to READY first", item.Status))
+		return false
+	}
+	return true
+}
+
+// requeueForIntents re-queues a READY or FAILED item for processing when
+// rescore is set, so that its score, brief and todos reflect the edited
+// intents. CAPTURED and PROCESSING items are left alone, as they will pick
+// up the edit anyway; canRescore has refused the other statuses. It returns
+// the item's resulting status.
+func (s *Server) requeueForIntents(r *http.Request, item *model.ItemWithArtifacts, rescore bool) (string, error) {
+	if !rescore || (item.Status != model.StatusReady && item.Status != model.StatusFailed) {
+		return item.Status, nil
+	}
+	if err := s.store.UpdateItemStatus(r.Context(), item.ID, model.StatusCaptured, nil); err != nil {
+		return "", err
+	}
+	s.publish(model.StatusEvent(item.ID, model.StatusCaptured))
+	return model.StatusCaptured, nil
+}
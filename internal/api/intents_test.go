@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestIntents(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/go","intent_text":"learn Go"}`)
+	id := decodeJSON(t, rr)["id"].(string)
+
+	rr = doRequest(t, h, "GET", "/api/items/"+id+"/intents", "")
+	var intents []model.Intent
+	json.Unmarshal(rr.Body.Bytes(), &intents)
+	if rr.Code != http.StatusOK || len(intents) != 1 || intents[0].Text != "learn Go" {
+		t.Fatalf("list: status = %d, intents = %+v", rr.Code, intents)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/items/"+id+"/intents", `{"text":"  write a CLI  "}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	created := decodeJSON(t, rr)
+	if created["text"] != "write a CLI" || created["item_status"] != model.StatusCaptured {
+		t.Errorf("created = %v", created)
+	}
+
+	// With rescore, a READY item is re-queued.
+	st.UpdateItemStatus(ctx, id, model.StatusReady, nil)
+	rr = doRequest(t, h, "PATCH", "/api/items/"+id+"/intents/"+intents[0].ID+"?rescore=true", `{"text":"learn Go generics"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	if updated := decodeJSON(t, rr); updated["text"] != "learn Go generics" || updated["item_status"] != model.StatusCaptured {
+		t.Errorf("updated = %v", updated)
+	}
+
+	st.UpdateItemStatus(ctx, id, model.StatusReady, nil)
+	rr = doRequest(t, h, "DELETE", "/api/items/"+id+"/intents/"+created["id"].(string), "")
+	if rr.Code != http.StatusOK || decodeJSON(t, rr)["item_status"] != model.StatusReady {
+		t.Errorf("delete: status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	if item, _ := st.GetItem(ctx, id); item.IntentText != "learn Go generics" || len(item.Intents) != 1 {
+		t.Errorf("intent_text = %q, intents = %+v", item.IntentText, item.Intents)
+	}
+
+	// A rescore that would not re-queue the item is refused, and the
+	// intent is left unchanged.
+	st.UpdateItemStatus(ctx, id, model.StatusDone, nil)
+	rr = doRequest(t, h, "PATCH", "/api/items/"+id+"/intents/"+intents[0].ID+"?rescore=true", `{"text":"changed"}`)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("rescore DONE item: status = %d, want 409", rr.Code)
+	}
+	if item, _ := st.GetItem(ctx, id); item.IntentText != "learn Go generics" {
+		t.Errorf("intent_text after refused rescore = %q", item.IntentText)
+	}
+	if rr := doRequest(t, h, "PATCH", "/api/items/"+id+"/intents/"+intents[0].ID, `{"text":"learn Go generics well"}`); rr.Code != http.StatusOK {
+		t.Errorf("edit DONE item without rescore: status = %d, want 200", rr.Code)
+	}
+
+	tests := []struct {
+		name, method, path, body string
+		want                     int
+	}{
+		{"unknown item", "GET", "/api/items/missing/intents", "", http.StatusNotFound},
+		{"empty text", "POST", "/api/items/" + id + "/intents", `{"text":" "}`, http.StatusBadRequest},
+		{"bad rescore", "POST", "/api/items/" + id + "/intents?rescore=maybe", `{"text":"x"}`, http.StatusBadRequest},
+		{"unknown intent", "PATCH", "/api/items/" + id + "/intents/missing", `{"text":"x"}`, http.StatusNotFound},
+		{"deleted intent", "DELETE", "/api/items/" + id + "/intents/" + created["id"].(string), "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rr := doRequest(t, h, tt.method, tt.path, tt.body); rr.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rr.Code, tt.want)
+		}
+	}
+}
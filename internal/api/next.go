@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+const (
+	defaultNextBudget = 30 * time.Minute
+	defaultNextLimit  = 5
+	maxNextLimit      = 50
+)
+
+// ---------------------------------------------------------------------------
+// GET /api/next
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleNext(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	budget := defaultNextBudget
+	if v := q.Get("budget"); v != "" {
+		d, err := model.ParseWithin(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "budget must be a duration such as 30m or 2h")
+			return
+		}
+		budget = d
+	}
+	limit := defaultNextLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxNextLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 50")
+			return
+		}
+		limit = n
+	}
+
+	items, err := s.store.ListItems(r.Context(), model.ItemFilter{Status: []string{model.StatusReady}})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list items")
+		return
+	}
+	todos, err := s.openTodosByItem(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list todos")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.PlanNext(items, todos, budget, limit, time.Now()))
+}
+
+// openTodosByItem returns every open todo grouped by item, in position order.
+func (s *Server) openTodosByItem(ctx context.Context) (map[string][]model.Todo, error) {
+	open := false
+	todos, err := s.store.ListTodos(ctx, model.TodoFilter{Done: &open})
+	if err != nil {
+		return nil, err
+	}
+	byItem := make(map[string][]model.Todo)
+	for _, t := range todos {
+		byItem[t.ItemID] = append(byItem[t.ItemID], t)
+	}
+	return byItem, nil
+}
+
+// sortByRank orders items by model.RankScore, best first.
+func (s *Server) sortByRank(ctx context.Context, items []model.Item) error {
+	todos, err := s.openTodosByItem(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	ranks := make(map[string]float64, len(items))
+	for i := range items {
+		ranks[items[i].ID] = model.RankScore(&items[i], model.ItemEffort(todos[items[i].ID]), now)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return ranks[items[i].ID] > ranks[items[j].ID] })
+	return nil
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestNext(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/1"}`)
+	id := decodeJSON(t, rr)["id"].(string)
+	st.UpdateItemStatus(ctx, id, model.StatusReady, nil)
+	st.SyncGeneratedTodos(ctx, id, []model.Todo{
+		model.NewTodo("t1", id, "Read", "20m", model.TodoTypeRead, 0),
+		model.NewTodo("t2", id, "Build", "2h", model.TodoTypeBuild, 1),
+	})
+	// Not READY: never suggested.
+	doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/2"}`)
+
+	rr = doRequest(t, h, "GET", "/api/next?budget=30m", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var plan model.NextPlan
+	if err := json.Unmarshal(rr.Body.Bytes(), &plan); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(plan.Items) != 1 || plan.Items[0].ID != id {
+		t.Fatalf("items = %+v, want the READY item", plan.Items)
+	}
+	if len(plan.Items[0].Todos) != 1 || plan.Items[0].Todos[0].ID != "t1" {
+		t.Errorf("todos = %+v, want only the 20m todo", plan.Items[0].Todos)
+	}
+	if plan.PlannedMinutes != 20 {
+		t.Errorf("planned = %d, want 20", plan.PlannedMinutes)
+	}
+
+	for _, q := range []string{"budget=soon", "budget=30m&limit=0"} {
+		if rr := doRequest(t, h, "GET", "/api/next?"+q, ""); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want %d", q, rr.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// proposalResponse compares the user's version of an artifact with the
+// version generated since.
+type proposalResponse struct {
+	Current  model.Artifact     `json:"current"`
+	Proposed model.Artifact     `json:"proposed"`
+	Diff     []model.JSONChange `json:"diff"`
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/items/{id}/artifacts/{type}/proposal
+// ---------------------------------------------------------------------------
+
+// handleGetProposal returns both versions of an artifact the user edited
+// before the item was processed again, and the changes from the user's
+// version to the generated one.
+func (s *Server) handleGetProposal(w http.ResponseWriter, r *http.Request) {
+	itemID, artifactType := r.PathValue("id"), r.PathValue("type")
+	proposed, err := s.store.GetArtifactProposal(r.Context(), itemID, artifactType)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "no proposal for this artifact")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get proposal")
+		return
+	}
+	current, err := s.store.GetArtifact(r.Context(), itemID, artifactType)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get artifact")
+		return
+	}
+	diff, err := model.DiffJSON(current.Payload, proposed.Payload)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to compare versions")
+		return
+	}
+	writeJSON(w, http.StatusOK, proposalResponse{Current: *current, Proposed: *proposed, Diff: diff})
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/items/{id}/artifacts/{type}/proposal/accept
+// ---------------------------------------------------------------------------
+
+// handleAcceptProposal replaces the user's version of an artifact with the
+// generated one.
+func (s *Server) handleAcceptProposal(w http.ResponseWriter, r *http.Request) {
+	itemID, artifactType := r.PathValue("id"), r.PathValue("type")
+	artifact, err := s.store.AcceptArtifactProposal(r.Context(), itemID, artifactType)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "no proposal for this artifact")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to accept proposal")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, itemID))
+	writeJSON(w, http.StatusOK, artifact)
+}
+
+// ---------------------------------------------------------------------------
+// DELETE /api/items/{id}/artifacts/{type}/proposal
+// ---------------------------------------------------------------------------
+
+// handleRejectProposal discards the generated version, keeping the user's.
+func (s *Server) handleRejectProposal(w http.ResponseWriter, r *http.Request) {
+	itemID, artifactType := r.PathValue("id"), r.PathValue("type")
+	err := s.store.RejectArtifactProposal(r.Context(), itemID, artifactType)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "no proposal for this artifact")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to reject proposal")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, itemID))
+	writeJSON(w, http.StatusOK, map[string]string{"item_id": itemID, "artifact_type": artifactType, "rejected": "true"})
+}
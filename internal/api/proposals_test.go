@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestArtifactProposal(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+	item := model.NewItem("item-1", "https://example.com/1", "One", "example.com", "web", "learn")
+	item.Status = model.StatusReady
+	st.CreateItem(ctx, item)
+	st.UpsertArtifact(ctx, model.NewArtifact("gen-1", "item-1", model.ArtifactSynthesis, `{"points":["a"],"insight":"generated"}`))
+
+	path := "/api/items/item-1/artifacts/synthesis"
+	if rr := doRequest(t, h, "GET", path+"/proposal", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("no proposal: status = %d, want 404", rr.Code)
+	}
+	doRequest(t, h, "PUT", path, `{"payload":{"points":["a"],"insight":"mine"}}`)
+	// The item is processed again.
+	st.UpsertArtifact(ctx, model.NewArtifact("gen-2", "item-1", model.ArtifactSynthesis, `{"points":["a","b"],"insight":"regenerated"}`))
+
+	rr := doRequest(t, h, "GET", path+"/proposal", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("get proposal: status = %d", rr.Code)
+	}
+	var got proposalResponse
+	json.Unmarshal(rr.Body.Bytes(), &got)
+	if got.Current.CreatedBy != model.CreatedByUser || got.Proposed.ID != "gen-2" || len(got.Diff) != 2 ||
+		got.Diff[0].Path != "/insight" || got.Diff[1].Path != "/points/1" || got.Diff[1].Op != model.DiffAdd {
+		t.Errorf("proposal = %+v", got)
+	}
+
+	rr = doRequest(t, h, "POST", path+"/proposal/accept", "")
+	if rr.Code != http.StatusOK || decodeJSON(t, rr)["id"] != "gen-2" {
+		t.Errorf("accept: status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	if rr := doRequest(t, h, "DELETE", path+"/proposal", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("reject after accept: status = %d, want 404", rr.Code)
+	}
+
+	doRequest(t, h, "PUT", path, `{"payload":{"insight":"mine again"}}`)
+	st.UpsertArtifact(ctx, model.NewArtifact("gen-3", "item-1", model.ArtifactSynthesis, `{"insight":"gen-3"}`))
+	if rr := doRequest(t, h, "DELETE", path+"/proposal", ""); rr.Code != http.StatusOK {
+		t.Errorf("reject: status = %d", rr.Code)
+	}
+	if a, _ := st.GetArtifact(ctx, "item-1", model.ArtifactSynthesis); a.Payload != `{"insight":"mine again"}` {
+		t.Errorf("artifact after reject = %s", a.Payload)
+	}
+}
+
+func TestArtifactHistory(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+	item := model.NewItem("item-1", "https://example.com/1", "One", "example.com", "web", "learn")
+	item.Status = model.StatusReady
+	st.CreateItem(ctx, item)
+	st.UpsertArtifact(ctx, model.NewArtifact("gen-1", "item-1", model.ArtifactSynthesis, `{"points":["a"],"insight":"generated"}`))
+	path := "/api/items/item-1/artifacts/synthesis"
+	// The edit responds with the version it was stored as.
+	rr := doRequest(t, h, "PUT", path, `{"payload":{"points":["a"],"insight":"mine"}}`)
+	var edited model.Artifact
+	json.Unmarshal(rr.Body.Bytes(), &edited)
+	if rr.Code != http.StatusOK || edited.Version != 2 || edited.CreatedBy != model.CreatedByUser {
+		t.Errorf("edit: status = %d, artifact = %+v, want version 2", rr.Code, edited)
+	}
+
+	rr = doRequest(t, h, "GET", path+"/history", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("history: status = %d", rr.Code)
+	}
+	var history historyResponse
+	json.Unmarshal(rr.Body.Bytes(), &history)
+	if history.CurrentVersion != 2 || len(history.Versions) != 2 || history.Versions[0].CreatedBy != model.CreatedByUser {
+		t.Errorf("history = %+v", history)
+	}
+
+	rr = doRequest(t, h, "GET", path+"/diff?from=1", "")
+	var diff versionDiffResponse
+	json.Unmarshal(rr.Body.Bytes(), &diff)
+	if rr.Code != http.StatusOK || diff.To != 2 || len(diff.Diff) != 1 || diff.Diff[0].Path != "/insight" {
+		t.Errorf("diff: status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	for query, want := range map[string]int{"from=x": http.StatusBadRequest, "from=1&to=0": http.StatusBadRequest, "from=1&to=7": http.StatusNotFound} {
+		if rr := doRequest(t, h, "GET", path+"/diff?"+query, ""); rr.Code != want {
+			t.Errorf("diff?%s: status = %d, want %d", query, rr.Code, want)
+		}
+	}
+
+	rr = doRequest(t, h, "POST", path+"/revert/1", "")
+	if rr.Code != http.StatusOK || decodeJSON(t, rr)["version"] != float64(3) {
+		t.Errorf("revert: status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	if a, _ := st.GetArtifact(ctx, "item-1", model.ArtifactSynthesis); a.Payload != `{"points":["a"],"insight":"generated"}` {
+		t.Errorf("artifact after revert = %s", a.Payload)
+	}
+	if rr := doRequest(t, h, "POST", path+"/revert/9", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("revert to missing version: status = %d, want 404", rr.Code)
+	}
+	if rr := doRequest(t, h, "POST", "/api/items/item-1/artifacts/score/revert/1", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("revert score: status = %d, want 400", rr.Code)
+	}
+	st.UpdateItemStatus(ctx, "item-1", model.StatusProcessing, nil)
+	if rr := doRequest(t, h, "POST", path+"/revert/1", ""); rr.Code != http.StatusConflict {
+		t.Errorf("revert while processing: status = %d, want 409", rr.Code)
+	}
+}
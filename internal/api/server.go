@@ -5,43 +5,145 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/yangwenmai/readdo/internal/events"
+	"github.com/yangwenmai/readdo/internal/importer"
+	"github.com/yangwenmai/readdo/internal/model"
 	"github.com/yangwenmai/readdo/internal/store"
 )
 
 // maxRequestBody is the maximum allowed request body size (1 MB).
 const maxRequestBody int64 = 1 << 20
 
+// maxImportBody is the maximum size of an uploaded bookmark export (32 MB).
+const maxImportBody int64 = 32 << 20
+
+// defaultHeartbeat is how often an idle event stream sends a keep-alive comment.
+const defaultHeartbeat = 15 * time.Second
+
 // Server holds the HTTP handlers and dependencies.
 type Server struct {
-	store store.ItemRepository
-	mux   *http.ServeMux
+	store     store.ItemRepository
+	jobs      JobRunner
+	events    *events.Bus
+	vault     VaultExporter
+	importer  *importer.Importer
+	rate      int
+	heartbeat time.Duration
+	mux       *http.ServeMux
+}
+
+// Option configures optional Server dependencies.
+type Option func(*Server)
+
+// WithJobs exposes the maintenance job scheduler under /api/admin/jobs.
+func WithJobs(j JobRunner) Option {
+	return func(s *Server) { s.jobs = j }
+}
+
+// WithEvents publishes item changes to bus and streams them under /api/events.
+func WithEvents(bus *events.Bus) Option {
+	return func(s *Server) { s.events = bus }
+}
+
+// WithVault enables on-demand Markdown export under /api/export/markdown.
+func WithVault(v VaultExporter) Option {
+	return func(s *Server) { s.vault = v }
+}
+
+// WithImportRate queues imported items for processing at no more than n
+// per minute. Imports are not throttled by default.
+func WithImportRate(n int) Option {
+	return func(s *Server) { s.rate = n }
 }
 
 // New creates a new API server.
-func New(s store.ItemRepository) *Server {
-	srv := &Server{store: s, mux: http.NewServeMux()}
+func New(s store.ItemRepository, opts ...Option) *Server {
+	srv := &Server{store: s, heartbeat: defaultHeartbeat, mux: http.NewServeMux()}
+	for _, o := range opts {
+		o(srv)
+	}
+	srv.importer = importer.New(s, srv.rate)
+	if srv.events != nil {
+		srv.importer.WithEvents(srv.events)
+	}
 	srv.routes()
 	return srv
 }
 
 // Handler returns the root http.Handler with middleware applied.
 func (s *Server) Handler() http.Handler {
-	return corsMiddleware(limitBody(jsonContent(s.mux)))
+	return corsMiddleware(limitBody(s.mux))
 }
 
 func (s *Server) routes() {
 	s.mux.HandleFunc("POST /api/capture", s.handleCapture)
+	s.mux.HandleFunc("POST /api/import", s.handleImport)
 	s.mux.HandleFunc("GET /api/items", s.handleListItems)
 	s.mux.HandleFunc("GET /api/items/{id}", s.handleGetItem)
+	s.mux.HandleFunc("GET /api/duplicates", s.handleListDuplicates)
+	s.mux.HandleFunc("POST /api/items/merge", s.handleMergeItems)
+	s.mux.HandleFunc("POST /api/items/{id}/duplicate/merge", s.handleConfirmDuplicate)
+	s.mux.HandleFunc("DELETE /api/items/{id}/duplicate", s.handleDismissDuplicate)
 	s.mux.HandleFunc("DELETE /api/items/{id}", s.handleDeleteItem)
+	s.mux.HandleFunc("GET /api/items/{id}/intents", s.handleListIntents)
+	s.mux.HandleFunc("POST /api/items/{id}/intents", s.handleCreateIntent)
+	s.mux.HandleFunc("PATCH /api/items/{id}/intents/{intent_id}", s.handleUpdateIntent)
+	s.mux.HandleFunc("DELETE /api/items/{id}/intents/{intent_id}", s.handleDeleteIntent)
 	s.mux.HandleFunc("POST /api/items/{id}/retry", s.handleRetry)
 	s.mux.HandleFunc("POST /api/items/{id}/reprocess", s.handleReprocess)
 	s.mux.HandleFunc("PATCH /api/items/{id}/status", s.handleUpdateStatus)
 	s.mux.HandleFunc("PUT /api/items/{id}/artifacts/{type}", s.handleEditArtifact)
+	s.mux.HandleFunc("GET /api/items/{id}/artifacts/{type}/proposal", s.handleGetProposal)
+	s.mux.HandleFunc("POST /api/items/{id}/artifacts/{type}/proposal/accept", s.handleAcceptProposal)
+	s.mux.HandleFunc("DELETE /api/items/{id}/artifacts/{type}/proposal", s.handleRejectProposal)
+	s.mux.HandleFunc("GET /api/items/{id}/artifacts/{type}/history", s.handleArtifactHistory)
+	s.mux.HandleFunc("GET /api/items/{id}/artifacts/{type}/diff", s.handleArtifactDiff)
+	s.mux.HandleFunc("POST /api/items/{id}/artifacts/{type}/revert/{version}", s.handleRevertArtifact)
+	s.mux.HandleFunc("PUT /api/items/{id}/tags", s.handleSetTags)
 	s.mux.HandleFunc("POST /api/items/batch/status", s.handleBatchStatus)
 	s.mux.HandleFunc("POST /api/items/batch/delete", s.handleBatchDelete)
+	s.mux.HandleFunc("POST /api/items/batch/tags", s.handleBatchTags)
+	s.mux.HandleFunc("GET /api/todos", s.handleListTodos)
+	s.mux.HandleFunc("PATCH /api/todos/{id}", s.handleUpdateTodo)
+	s.mux.HandleFunc("GET /api/next", s.handleNext)
+	s.mux.HandleFunc("GET /api/calendar.ics", s.handleCalendar)
 	s.mux.HandleFunc("GET /api/stats", s.handleStats)
+	s.mux.HandleFunc("GET /api/views", s.handleListViews)
+	s.mux.HandleFunc("POST /api/views", s.handleCreateView)
+	s.mux.HandleFunc("GET /api/views/{id}", s.handleGetView)
+	s.mux.HandleFunc("PUT /api/views/{id}", s.handleUpdateView)
+	s.mux.HandleFunc("DELETE /api/views/{id}", s.handleDeleteView)
+	s.mux.HandleFunc("GET /api/views/{id}/items", s.handleViewItems)
+	s.mux.HandleFunc("GET /api/archive-rules", s.handleListArchiveRules)
+	s.mux.HandleFunc("POST /api/archive-rules", s.handleCreateArchiveRule)
+	s.mux.HandleFunc("POST /api/archive-rules/dry-run", s.handleDryRunDraftRule)
+	s.mux.HandleFunc("PUT /api/archive-rules/{id}", s.handleUpdateArchiveRule)
+	s.mux.HandleFunc("DELETE /api/archive-rules/{id}", s.handleDeleteArchiveRule)
+	s.mux.HandleFunc("GET /api/archive-rules/{id}/dry-run", s.handleDryRunArchiveRule)
+	s.mux.HandleFunc("GET /api/auto-archives", s.handleListAutoArchives)
+	s.mux.HandleFunc("POST /api/auto-archives/{id}/undo", s.handleUndoAutoArchive)
+	s.mux.HandleFunc("GET /api/webhooks", s.handleListWebhooks)
+	s.mux.HandleFunc("POST /api/webhooks", s.handleCreateWebhook)
+	s.mux.HandleFunc("PUT /api/webhooks/{id}", s.handleUpdateWebhook)
+	s.mux.HandleFunc("DELETE /api/webhooks/{id}", s.handleDeleteWebhook)
+	s.mux.HandleFunc("GET /api/webhooks/{id}/deliveries", s.handleListWebhookDeliveries)
+	s.mux.HandleFunc("POST /api/webhook-deliveries/{id}/redeliver", s.handleRedeliverWebhook)
+	s.mux.HandleFunc("POST /api/export/markdown", s.handleExportMarkdown)
+	s.mux.HandleFunc("GET /api/export/markdown/todo-syncs", s.handleListTodoSyncs)
+	s.mux.HandleFunc("POST /api/export/epub", s.handleExportEPUB)
+	s.mux.HandleFunc("GET /api/feeds", s.handleListFeeds)
+	s.mux.HandleFunc("POST /api/feeds", s.handleCreateFeed)
+	s.mux.HandleFunc("PUT /api/feeds/{id}", s.handleUpdateFeed)
+	s.mux.HandleFunc("DELETE /api/feeds/{id}", s.handleDeleteFeed)
+	s.mux.HandleFunc("POST /api/feeds/{id}/token", s.handleRotateFeedToken)
+	s.mux.HandleFunc("GET "+feedPath, s.handleAtomFeed)
+	s.mux.HandleFunc("GET /api/events", s.handleEvents)
+	s.mux.HandleFunc("GET /api/admin/jobs", s.handleListJobs)
+	s.mux.HandleFunc("GET /api/admin/backup", s.handleBackup)
+	s.mux.HandleFunc("GET /api/admin/export.jsonl", s.handleExportArchive)
+	s.mux.HandleFunc("POST /api/admin/jobs/{name}/run", s.handleRunJob)
 }
 
 // ---------------------------------------------------------------------------
@@ -58,7 +160,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", origin)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Last-Event-ID")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -67,17 +169,15 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// limitBody restricts the request body to maxRequestBody bytes.
+// limitBody restricts the request body to maxRequestBody bytes, or
+// maxImportBody for bookmark imports.
 func limitBody(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
-		next.ServeHTTP(w, r)
-	})
-}
-
-func jsonContent(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+		limit := maxRequestBody
+		if r.URL.Path == "/api/import" {
+			limit = maxImportBody
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
 		next.ServeHTTP(w, r)
 	})
 }
@@ -87,6 +187,7 @@ func jsonContent(next http.Handler) http.Handler {
 // ---------------------------------------------------------------------------
 
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(v)
 }
@@ -95,6 +196,13 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	writeJSON(w, status, map[string]string{"error": msg})
 }
 
+// publish sends e to the event bus, if one is configured.
+func (s *Server) publish(e model.Event) {
+	if s.events != nil {
+		s.events.Publish(e)
+	}
+}
+
 func splitComma(s string) []string {
 	if s == "" {
 		return nil
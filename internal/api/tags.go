@@ -0,0 +1,96 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// PUT /api/items/{id}/tags
+// ---------------------------------------------------------------------------
+
+type setTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+func (s *Server) handleSetTags(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req setTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	tags, err := model.NormalizeTags(req.Tags)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.store.GetItem(r.Context(), id); errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "item not found")
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get item")
+		return
+	}
+
+	if err := s.store.SetItemTags(r.Context(), id, tags); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to save tags")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, id))
+
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "tags": tags})
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/items/batch/tags
+// ---------------------------------------------------------------------------
+
+type batchTagsRequest struct {
+	IDs    []string `json:"ids"`
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+func (s *Server) handleBatchTags(w http.ResponseWriter, r *http.Request) {
+	var req batchTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeError(w, http.StatusBadRequest, "ids is required")
+		return
+	}
+	add, err := model.NormalizeTags(req.Add)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	remove, err := model.NormalizeTags(req.Remove)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		writeError(w, http.StatusBadRequest, "add or remove is required")
+		return
+	}
+
+	updated, err := s.store.BatchUpdateTags(r.Context(), req.IDs, add, remove)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update tags")
+		return
+	}
+	for _, id := range updated {
+		s.publish(model.NewEvent(model.EventItemUpdated, id))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"updated": len(updated)})
+}
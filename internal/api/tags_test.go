@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCapture_WithTags(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com","tags":["Go","go","db"]}`)
+	id := decodeJSON(t, rr)["id"].(string)
+	doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com","tags":["perf"]}`)
+
+	rr = doRequest(t, h, "GET", "/api/items/"+id, "")
+	tags, _ := decodeJSON(t, rr)["tags"].([]any)
+	if len(tags) != 3 {
+		t.Errorf("tags = %v, want [db go perf]", tags)
+	}
+}
+
+func TestSetTags(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com","tags":["old"]}`)
+	id := decodeJSON(t, rr)["id"].(string)
+
+	rr = doRequest(t, h, "PUT", "/api/items/"+id+"/tags", `{"tags":["go"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	// The filter is normalized like the stored tags.
+	rr = doRequest(t, h, "GET", "/api/items?tag=Go", "")
+	var items []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &items)
+	if len(items) != 1 {
+		t.Errorf("items tagged Go = %d, want 1", len(items))
+	}
+	if rr := doRequest(t, h, "GET", "/api/items?tag="+strings.Repeat("x", 60), ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("overlong tag filter status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+
+	rr = doRequest(t, h, "PUT", "/api/items/nonexistent/tags", `{"tags":["go"]}`)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("missing item status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestBatchTags(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	rr1 := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/1"}`)
+	rr2 := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/2"}`)
+	id1 := decodeJSON(t, rr1)["id"].(string)
+	id2 := decodeJSON(t, rr2)["id"].(string)
+
+	rr := doRequest(t, h, "POST", "/api/items/batch/tags", `{"ids":["`+id1+`","`+id2+`"],"add":["go"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := decodeJSON(t, rr)["updated"]; got != float64(2) {
+		t.Errorf("updated = %v, want 2", got)
+	}
+
+	rr = doRequest(t, h, "GET", "/api/stats", "")
+	tags, _ := decodeJSON(t, rr)["tags"].([]any)
+	if len(tags) != 1 || tags[0].(map[string]any)["count"] != float64(2) {
+		t.Errorf("stats tags = %v, want go:2", tags)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/items/batch/tags", `{"ids":["`+id1+`"]}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("empty add/remove status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// GET /api/todos
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListTodos(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := model.TodoFilter{
+		Type:   splitComma(q.Get("type")),
+		ItemID: q.Get("item_id"),
+	}
+	if v := q.Get("done"); v != "" {
+		done, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "done must be true or false")
+			return
+		}
+		filter.Done = &done
+	}
+
+	todos, err := s.store.ListTodos(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list todos")
+		return
+	}
+	writeJSON(w, http.StatusOK, todos)
+}
+
+// ---------------------------------------------------------------------------
+// PATCH /api/todos/{id}
+// ---------------------------------------------------------------------------
+
+// todoResponse is a todo together with its item's status after the update,
+// which may have moved to DONE (all todos completed) or back to READY.
+type todoResponse struct {
+	model.Todo
+	ItemStatus string `json:"item_status"`
+}
+
+func (s *Server) handleUpdateTodo(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var patch model.TodoPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	todo, err := s.store.GetTodo(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "todo not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get todo")
+		return
+	}
+
+	wasDone := todo.Done
+	if err := todo.Apply(patch); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.UpdateTodo(r.Context(), *todo); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update todo")
+		return
+	}
+	s.publish(model.NewEvent(model.EventItemUpdated, todo.ItemID))
+
+	status, err := s.syncItemCompletion(r, todo.ItemID, wasDone && !todo.Done)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update item status")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, todoResponse{Todo: *todo, ItemStatus: status})
+}
+
+// syncItemCompletion moves the item to DONE when all its todos are completed,
+// or back to READY when reopened reports that a todo of a DONE item was
+// unticked. It returns the item's resulting status.
+func (s *Server) syncItemCompletion(r *http.Request, itemID string, reopened bool) (string, error) {
+	item, err := s.store.GetItem(r.Context(), itemID)
+	if err != nil {
+		return "", err
+	}
+	total, done, err := s.store.CountItemTodos(r.Context(), itemID)
+	if err != nil {
+		return "", err
+	}
+	status, ok := item.CompletionStatus(total, done, reopened)
+	if !ok {
+		return item.Status, nil
+	}
+	if err := s.store.UpdateItemStatus(r.Context(), itemID, status, nil); err != nil {
+		return "", err
+	}
+	s.publish(model.StatusEvent(itemID, status))
+	return status, nil
+}
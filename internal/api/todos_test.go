@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestTodos_ListAndPatch(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com"}`)
+	id := decodeJSON(t, rr)["id"].(string)
+	st.SyncGeneratedTodos(ctx, id, []model.Todo{
+		model.NewTodo("t-1", id, "Read", "10m", model.TodoTypeRead, 0),
+		model.NewTodo("t-2", id, "Write", "20m", model.TodoTypeWrite, 1),
+	})
+
+	rr = doRequest(t, h, "GET", "/api/todos?type=WRITE&done=false", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("list status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	var todos []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &todos)
+	if len(todos) != 1 || todos[0]["id"] != "t-2" {
+		t.Fatalf("open WRITE todos = %v, want [t-2]", todos)
+	}
+
+	rr = doRequest(t, h, "PATCH", "/api/todos/t-2", `{"done":true,"due_date":"2026-11-01"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("patch status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	result := decodeJSON(t, rr)
+	if result["done"] != true || result["completed_at"] == nil {
+		t.Errorf("patched todo = %v, want done with completed_at", result)
+	}
+
+	rr = doRequest(t, h, "GET", "/api/todos?type=WRITE&done=false", "")
+	json.Unmarshal(rr.Body.Bytes(), &todos)
+	if len(todos) != 0 {
+		t.Errorf("open WRITE todos after patch = %d, want 0", len(todos))
+	}
+}
+
+func TestUpdateTodo_Errors(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "PATCH", "/api/todos/missing", `{"done":true}`)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("missing todo status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	rr = doRequest(t, h, "GET", "/api/todos?done=maybe", "")
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("bad done status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUpdateTodo_CompletesItem(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	rr := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com"}`)
+	id := decodeJSON(t, rr)["id"].(string)
+	st.UpdateItemStatus(ctx, id, model.StatusReady, nil)
+	st.SyncGeneratedTodos(ctx, id, []model.Todo{
+		model.NewTodo("t-1", id, "Read", "10m", model.TodoTypeRead, 0),
+		model.NewTodo("t-2", id, "Write", "20m", model.TodoTypeWrite, 1),
+	})
+
+	rr = doRequest(t, h, "PATCH", "/api/todos/t-1", `{"done":true}`)
+	if got := decodeJSON(t, rr)["item_status"]; got != model.StatusReady {
+		t.Errorf("item_status after first todo = %v, want READY", got)
+	}
+	rr = doRequest(t, h, "PATCH", "/api/todos/t-2", `{"done":true}`)
+	if got := decodeJSON(t, rr)["item_status"]; got != model.StatusDone {
+		t.Errorf("item_status after last todo = %v, want DONE", got)
+	}
+
+	rr = doRequest(t, h, "GET", "/api/stats", "")
+	stats := decodeJSON(t, rr)
+	if stats["done"] != float64(1) || stats["inbox"] != float64(0) {
+		t.Errorf("stats = %v, want done=1 inbox=0", stats)
+	}
+
+	// Reopening a todo moves the item back to READY.
+	rr = doRequest(t, h, "PATCH", "/api/todos/t-2", `{"done":false}`)
+	if got := decodeJSON(t, rr)["item_status"]; got != model.StatusReady {
+		t.Errorf("item_status after reopening = %v, want READY", got)
+	}
+
+	// Marked DONE by hand with a todo still open, the item stays DONE when
+	// that todo is renamed, rescheduled or moved.
+	st.UpdateItemStatus(ctx, id, model.StatusDone, nil)
+	for _, patch := range []string{`{"title":"Write it up"}`, `{"due_date":"2026-12-01"}`, `{"position":0}`} {
+		rr = doRequest(t, h, "PATCH", "/api/todos/t-2", patch)
+		if got := decodeJSON(t, rr)["item_status"]; got != model.StatusDone {
+			t.Errorf("item_status after %s = %v, want DONE", patch, got)
+		}
+	}
+}
+
+func TestEditTodosArtifact_SyncsTodos(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+	item := model.NewItem("item-1", "https://example.com/1", "One", "example.com", "web", "learn")
+	item.Status = model.StatusReady
+	st.CreateItem(ctx, item)
+
+	path := "/api/items/item-1/artifacts/todos"
+	rr := doRequest(t, h, "PUT", path, `{"payload":{"todos":[{"title":"Read","eta":"10m","type":"READ"}]}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("edit status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	rr = doRequest(t, h, "GET", "/api/todos?item_id=item-1", "")
+	var todos []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &todos)
+	if len(todos) != 1 || todos[0]["title"] != "Read" {
+		t.Errorf("todos after edit = %v, want [Read]", todos)
+	}
+
+	if rr := doRequest(t, h, "PUT", path, `{"payload":{"todos":"none"}}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("malformed todos status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// GET /api/views
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListViews(w http.ResponseWriter, r *http.Request) {
+	views, err := s.store.ListViews(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list views")
+		return
+	}
+	if views == nil {
+		views = []model.View{}
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/views
+// ---------------------------------------------------------------------------
+
+type viewRequest struct {
+	Name   string           `json:"name"`
+	Filter model.ItemFilter `json:"filter"`
+}
+
+func (s *Server) handleCreateView(w http.ResponseWriter, r *http.Request) {
+	var req viewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	view := model.NewView(uuid.New().String(), req.Name, req.Filter)
+	if err := view.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.CreateView(r.Context(), view); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create view")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, view)
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/views/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleGetView(w http.ResponseWriter, r *http.Request) {
+	view, err := s.store.GetView(r.Context(), r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "view not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get view")
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// ---------------------------------------------------------------------------
+// PUT /api/views/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleUpdateView(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req viewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	view := model.View{ID: id, Name: req.Name, Filter: req.Filter}
+	if err := view.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := s.store.UpdateView(r.Context(), view)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "view not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update view")
+		return
+	}
+
+	updated, err := s.store.GetView(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get view")
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// ---------------------------------------------------------------------------
+// DELETE /api/views/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleDeleteView(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	err := s.store.DeleteView(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "view not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete view")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": id, "deleted": "true"})
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/views/{id}/items
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleViewItems(w http.ResponseWriter, r *http.Request) {
+	view, err := s.store.GetView(r.Context(), r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "view not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get view")
+		return
+	}
+
+	items, err := s.store.ListItems(r.Context(), view.Filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list items")
+		return
+	}
+	if items == nil {
+		items = []model.Item{}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
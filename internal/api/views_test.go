@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestViewsCRUD(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	rr := doRequest(t, h, "POST", "/api/views", `{"name":"Do first","filter":{"priority":["DO_FIRST"],"within":"7d"}}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d, body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	id := decodeJSON(t, rr)["id"].(string)
+
+	rr = doRequest(t, h, "PUT", "/api/views/"+id, `{"name":"Do first this week","filter":{"priority":["DO_FIRST"],"within":"7d"}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := decodeJSON(t, rr)["name"]; got != "Do first this week" {
+		t.Errorf("name = %v, want %q", got, "Do first this week")
+	}
+
+	rr = doRequest(t, h, "GET", "/api/views", "")
+	var views []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &views)
+	if len(views) != 1 {
+		t.Errorf("views = %d, want 1", len(views))
+	}
+
+	rr = doRequest(t, h, "DELETE", "/api/views/"+id, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	rr = doRequest(t, h, "GET", "/api/views/"+id, "")
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("get after delete status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestCreateView_Invalid(t *testing.T) {
+	srv, _ := newTestServer(t)
+	h := srv.Handler()
+
+	for _, body := range []string{
+		`{"filter":{}}`,
+		`{"name":"Bad","filter":{"within":"soon"}}`,
+	} {
+		rr := doRequest(t, h, "POST", "/api/views", body)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want %d", body, rr.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestViewItemsAndStats(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+
+	rr1 := doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/1","title":"One"}`)
+	doRequest(t, h, "POST", "/api/capture", `{"url":"https://example.com/2","title":"Two"}`)
+	id1 := decodeJSON(t, rr1)["id"].(string)
+	st.UpdateItemStatus(context.Background(), id1, model.StatusFailed, nil)
+
+	rr := doRequest(t, h, "POST", "/api/views", `{"name":"Failed last 24h","filter":{"status":["FAILED"],"within":"24h"}}`)
+	viewID := decodeJSON(t, rr)["id"].(string)
+
+	rr = doRequest(t, h, "GET", "/api/views/"+viewID+"/items", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("view items status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	var items []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &items)
+	if len(items) != 1 || items[0]["id"] != id1 {
+		t.Errorf("view items = %v, want only %s", items, id1)
+	}
+
+	rr = doRequest(t, h, "GET", "/api/stats", "")
+	stats := decodeJSON(t, rr)
+	views, _ := stats["views"].([]any)
+	if len(views) != 1 {
+		t.Fatalf("stats views = %v, want 1 entry", stats["views"])
+	}
+	if count := views[0].(map[string]any)["count"]; count != float64(1) {
+		t.Errorf("view count = %v, want 1", count)
+	}
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// GET /api/webhooks
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := s.store.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list webhooks")
+		return
+	}
+	if hooks == nil {
+		hooks = []model.Webhook{}
+	}
+	writeJSON(w, http.StatusOK, hooks)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/webhooks
+// ---------------------------------------------------------------------------
+
+type webhookRequest struct {
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Secret  string   `json:"secret"`  // generated on create if empty; kept on update if empty
+	Enabled *bool    `json:"enabled"` // defaults to true
+}
+
+// webhookCreated is the create response: the only time the secret is returned.
+type webhookCreated struct {
+	model.Webhook
+	Secret string `json:"secret"`
+}
+
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newSecret(); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to generate secret")
+			return
+		}
+	}
+	hook := model.NewWebhook(uuid.New().String(), req.URL, req.Events, secret)
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	if err := hook.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.CreateWebhook(r.Context(), hook); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, webhookCreated{Webhook: hook, Secret: secret})
+}
+
+// newSecret returns a random 256-bit secret, hex encoded.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ---------------------------------------------------------------------------
+// PUT /api/webhooks/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	existing, err := s.store.GetWebhook(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get webhook")
+		return
+	}
+
+	hook := *existing
+	hook.URL = req.URL
+	hook.Events = req.Events
+	if req.Secret != "" {
+		hook.Secret = req.Secret
+	}
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	if err := hook.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = s.store.UpdateWebhook(r.Context(), hook)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update webhook")
+		return
+	}
+
+	updated, err := s.store.GetWebhook(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get webhook")
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// ---------------------------------------------------------------------------
+// DELETE /api/webhooks/{id}
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	err := s.store.DeleteWebhook(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": id, "deleted": "true"})
+}
+
+// ---------------------------------------------------------------------------
+// GET /api/webhooks/{id}/deliveries
+// ---------------------------------------------------------------------------
+
+var validDeliveryStatuses = map[string]bool{
+	model.DeliveryPending:   true,
+	model.DeliveryDelivered: true,
+	model.DeliveryFailed:    true,
+}
+
+func (s *Server) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	status := r.URL.Query().Get("status")
+	if status != "" && !validDeliveryStatuses[status] {
+		writeError(w, http.StatusBadRequest, "status must be pending, delivered or failed")
+		return
+	}
+
+	if _, err := s.store.GetWebhook(r.Context(), id); errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get webhook")
+		return
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(r.Context(), id, status)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list deliveries")
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// ---------------------------------------------------------------------------
+// POST /api/webhook-deliveries/{id}/redeliver
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleRedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	delivery, err := s.store.RedeliverWebhookDelivery(r.Context(), r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "delivery not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to redeliver")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, delivery)
+}
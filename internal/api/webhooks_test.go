@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestWebhooks(t *testing.T) {
+	srv, st := newTestServer(t)
+	h := srv.Handler()
+	ctx := context.Background()
+
+	rr := doRequest(t, h, "POST", "/api/webhooks", `{"url":"ftp://example.com","events":["item.ready"]}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid webhook status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/webhooks", `{"url":"https://example.com/hook","events":["item.ready","item.failed"]}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d, body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	created := decodeJSON(t, rr)
+	secret, _ := created["secret"].(string)
+	if len(secret) != 64 {
+		t.Errorf("generated secret = %q, want 64 hex chars", secret)
+	}
+	if created["enabled"] != true {
+		t.Errorf("enabled = %v, want true by default", created["enabled"])
+	}
+	hookID := created["id"].(string)
+
+	// The secret is never returned again.
+	rr = doRequest(t, h, "GET", "/api/webhooks", "")
+	var hooks []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &hooks)
+	if len(hooks) != 1 {
+		t.Fatalf("list = %d webhooks, want 1", len(hooks))
+	}
+	if _, ok := hooks[0]["secret"]; ok {
+		t.Error("list exposes the secret")
+	}
+
+	rr = doRequest(t, h, "PUT", "/api/webhooks/"+hookID, `{"url":"https://example.com/v2","events":["*"],"enabled":false}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	updated := decodeJSON(t, rr)
+	if updated["url"] != "https://example.com/v2" || updated["enabled"] != false {
+		t.Errorf("updated = %v", updated)
+	}
+	if saved, _ := st.GetWebhook(ctx, hookID); saved.Secret != secret {
+		t.Error("update without secret changed the secret")
+	}
+	if rr := doRequest(t, h, "PUT", "/api/webhooks/missing", `{"url":"https://example.com","events":["*"]}`); rr.Code != http.StatusNotFound {
+		t.Errorf("update missing status = %d, want 404", rr.Code)
+	}
+
+	// Delivery log and redelivery.
+	d := model.NewWebhookDelivery("d1", hookID, model.StatusEvent("item-1", model.StatusReady), json.RawMessage(`{}`))
+	d.RecordAttempt(500, "unexpected status 500", time.Now().Add(-time.Hour))
+	st.EnqueueDelivery(ctx, d)
+
+	rr = doRequest(t, h, "GET", "/api/webhooks/"+hookID+"/deliveries?status=pending", "")
+	var log []map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &log)
+	if rr.Code != http.StatusOK || len(log) != 1 || log[0]["attempts"] != float64(1) {
+		t.Fatalf("deliveries = %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := doRequest(t, h, "GET", "/api/webhooks/"+hookID+"/deliveries?status=bogus", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("bad status filter = %d, want 400", rr.Code)
+	}
+	if rr := doRequest(t, h, "GET", "/api/webhooks/missing/deliveries", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("deliveries of missing webhook = %d, want 404", rr.Code)
+	}
+
+	rr = doRequest(t, h, "POST", "/api/webhook-deliveries/d1/redeliver", "")
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("redeliver status = %d, body: %s", rr.Code, rr.Body.String())
+	}
+	if got := decodeJSON(t, rr)["attempts"]; got != float64(0) {
+		t.Errorf("attempts after redeliver = %v, want 0", got)
+	}
+
+	rr = doRequest(t, h, "DELETE", "/api/webhooks/"+hookID, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("delete status = %d", rr.Code)
+	}
+	if rr := doRequest(t, h, "DELETE", "/api/webhooks/"+hookID, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("delete twice status = %d, want 404", rr.Code)
+	}
+}
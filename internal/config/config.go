@@ -65,6 +65,44 @@ type Config struct {
 
 	// CORSOrigin is the allowed CORS origin. Defaults to "*".
 	CORSOrigin string
+
+	// AutoTagThreshold is the minimum model confidence (0-1) for an AI-suggested
+	// tag to be applied to an item automatically.
+	AutoTagThreshold float64
+
+	// SnoozeWakeSchedule is the cron expression for returning snoozed items
+	// to READY once their snooze_until has passed.
+	SnoozeWakeSchedule string
+
+	// DBOptimizeSchedule is the cron expression for SQLite maintenance.
+	DBOptimizeSchedule string
+
+	// AutoArchiveSchedule is the cron expression for applying auto-archive rules.
+	AutoArchiveSchedule string
+
+	// EventHistorySize is how many recent events are kept for clients
+	// resuming the event stream with Last-Event-ID.
+	EventHistorySize int
+
+	// WebhookTimeout bounds each outbound webhook delivery request.
+	WebhookTimeout time.Duration
+
+	// VaultDir is the Obsidian-style vault directory that items are exported
+	// to as Markdown notes. Export is disabled when empty.
+	VaultDir string
+
+	// VaultSyncInterval is how often the vault is checked for notes whose
+	// todo checkboxes were edited.
+	VaultSyncInterval time.Duration
+
+	// ImportRate is how many imported items per minute are released to the
+	// worker. Zero or less releases them all at once.
+	ImportRate int
+
+	// TrackingParams adjusts which query parameters are dropped when URLs
+	// are canonicalized for duplicate detection: "name" or "prefix*" adds a
+	// rule, "-name" keeps a parameter dropped by default.
+	TrackingParams []string
 }
 
 // Load reads configuration from .env.local (if present) then environment
@@ -95,6 +133,17 @@ func Load() Config {
 		HTTPTimeout:    envDuration("HTTP_TIMEOUT", 60*time.Second),
 		MaxTextLength:  envInt("MAX_TEXT_LENGTH", 15000),
 		CORSOrigin:     envOr("CORS_ORIGIN", "*"),
+
+		AutoTagThreshold:    envFloat("AUTO_TAG_THRESHOLD", 0.7),
+		SnoozeWakeSchedule:  envOr("SNOOZE_WAKE_SCHEDULE", "* * * * *"),
+		DBOptimizeSchedule:  envOr("DB_OPTIMIZE_SCHEDULE", "30 3 * * *"),
+		AutoArchiveSchedule: envOr("AUTO_ARCHIVE_SCHEDULE", "0 * * * *"),
+		EventHistorySize:    envInt("EVENT_HISTORY_SIZE", 500),
+		WebhookTimeout:      envDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		VaultDir:            os.Getenv("VAULT_DIR"),
+		VaultSyncInterval:   envDuration("VAULT_SYNC_INTERVAL", 5*time.Second),
+		ImportRate:          envInt("IMPORT_RATE", 10),
+		TrackingParams:      envList("TRACKING_PARAMS"),
 	}
 }
 
@@ -171,3 +220,26 @@ func envInt(key string, fallback int) int {
 	}
 	return n
 }
+
+// envList splits a comma-separated value, dropping empty entries.
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func envFloat(key string, fallback float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
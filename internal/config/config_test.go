@@ -80,6 +80,9 @@ func TestLoad_Defaults(t *testing.T) {
 		"GEMINI_API_KEY", "GEMINI_MODEL",
 		"OLLAMA_URL", "OLLAMA_MODEL",
 		"WORKER_INTERVAL", "HTTP_TIMEOUT", "MAX_TEXT_LENGTH", "CORS_ORIGIN",
+		"AUTO_TAG_THRESHOLD", "SNOOZE_WAKE_SCHEDULE", "DB_OPTIMIZE_SCHEDULE", "AUTO_ARCHIVE_SCHEDULE",
+		"EVENT_HISTORY_SIZE", "WEBHOOK_TIMEOUT", "VAULT_DIR", "VAULT_SYNC_INTERVAL", "IMPORT_RATE",
+		"TRACKING_PARAMS",
 	}
 	saved := make(map[string]string)
 	for _, k := range envKeys {
@@ -116,6 +119,46 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.MaxTextLength != 15000 {
 		t.Errorf("MaxTextLength = %d, want 15000", cfg.MaxTextLength)
 	}
+	if cfg.AutoTagThreshold != 0.7 {
+		t.Errorf("AutoTagThreshold = %v, want 0.7", cfg.AutoTagThreshold)
+	}
+	if cfg.SnoozeWakeSchedule != "* * * * *" {
+		t.Errorf("SnoozeWakeSchedule = %q, want every minute", cfg.SnoozeWakeSchedule)
+	}
+	if cfg.DBOptimizeSchedule != "30 3 * * *" {
+		t.Errorf("DBOptimizeSchedule = %q, want %q", cfg.DBOptimizeSchedule, "30 3 * * *")
+	}
+	if cfg.AutoArchiveSchedule != "0 * * * *" {
+		t.Errorf("AutoArchiveSchedule = %q, want hourly", cfg.AutoArchiveSchedule)
+	}
+	if cfg.EventHistorySize != 500 {
+		t.Errorf("EventHistorySize = %d, want 500", cfg.EventHistorySize)
+	}
+	if cfg.WebhookTimeout != 10*time.Second {
+		t.Errorf("WebhookTimeout = %v, want 10s", cfg.WebhookTimeout)
+	}
+	if cfg.VaultDir != "" {
+		t.Errorf("VaultDir = %q, want disabled by default", cfg.VaultDir)
+	}
+	if cfg.VaultSyncInterval != 5*time.Second {
+		t.Errorf("VaultSyncInterval = %v, want 5s", cfg.VaultSyncInterval)
+	}
+	if cfg.ImportRate != 10 {
+		t.Errorf("ImportRate = %d, want 10", cfg.ImportRate)
+	}
+	if cfg.TrackingParams != nil {
+		t.Errorf("TrackingParams = %v, want none", cfg.TrackingParams)
+	}
+}
+
+func TestLoad_TrackingParams(t *testing.T) {
+	os.Setenv("TRACKING_PARAMS", " session, trk_* ,,-ref")
+	t.Cleanup(func() { os.Unsetenv("TRACKING_PARAMS") })
+
+	got := Load().TrackingParams
+	if len(got) != 3 || got[0] != "session" || got[1] != "trk_*" || got[2] != "-ref" {
+		t.Errorf("TrackingParams = %q", got)
+	}
 }
 
 func TestLoad_EnvOverride(t *testing.T) {
@@ -183,3 +226,13 @@ func TestEnvInt_Invalid(t *testing.T) {
 		t.Errorf("envInt with invalid value = %d, want fallback 42", got)
 	}
 }
+
+func TestEnvFloat_Invalid(t *testing.T) {
+	os.Setenv("TEST_FLOAT_INVALID", "high")
+	t.Cleanup(func() { os.Unsetenv("TEST_FLOAT_INVALID") })
+
+	got := envFloat("TEST_FLOAT_INVALID", 0.5)
+	if got != 0.5 {
+		t.Errorf("envFloat with invalid value = %v, want fallback 0.5", got)
+	}
+}
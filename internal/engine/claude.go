@@ -147,3 +147,6 @@ func (c *ClaudeClient) doRequest(ctx context.Context, body []byte) (string, erro
 
 	return "", fmt.Errorf("no text content in response")
 }
+
+// ModelName returns the configured model name.
+func (c *ClaudeClient) ModelName() string { return c.model }
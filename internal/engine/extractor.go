@@ -1,17 +1,20 @@
 package engine
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
 	nurl "net/url"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
 )
 
 const (
@@ -93,8 +96,10 @@ func (e *HTTPExtractor) doExtract(ctx context.Context, url string) (*ExtractedCo
 		return nil, fmt.Errorf("read body: %w", err)
 	}
 
-	parsedURL, _ := nurl.Parse(url)
-	article, err := readability.FromReader(strings.NewReader(string(body)), parsedURL)
+	// Redirects were followed: resolve links against, and report, where the
+	// page actually is.
+	finalURL := resp.Request.URL
+	article, err := readability.FromReader(strings.NewReader(string(body)), finalURL)
 	if err != nil {
 		return nil, fmt.Errorf("readability: %w", err)
 	}
@@ -121,6 +126,8 @@ func (e *HTTPExtractor) doExtract(ctx context.Context, url string) (*ExtractedCo
 
 	return &ExtractedContent{
 		NormalizedText: text,
+		FinalURL:       finalURL.String(),
+		CanonicalURL:   canonicalLink(body, finalURL),
 		Meta: ContentMeta{
 			Author:      article.Byline,
 			PublishDate: publishDate,
@@ -131,6 +138,55 @@ func (e *HTTPExtractor) doExtract(ctx context.Context, url string) (*ExtractedCo
 	}, nil
 }
 
+// canonicalLink returns the absolute href of the page's
+// <link rel="canonical">, or "" if there is none or it is implausible: not
+// http(s), or the site root for a page that is not (a common
+// misconfiguration that would make every article a duplicate).
+func canonicalLink(body []byte, base *nurl.URL) string {
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return ""
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "body":
+				return ""
+			case "link":
+			default:
+				continue
+			}
+			var rel, href string
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				switch string(key) {
+				case "rel":
+					rel = string(val)
+				case "href":
+					href = strings.TrimSpace(string(val))
+				}
+			}
+			if !slices.Contains(strings.Fields(strings.ToLower(rel)), "canonical") || href == "" {
+				continue
+			}
+			u, err := base.Parse(href)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return ""
+			}
+			if strings.Trim(u.Path, "/") == "" && strings.Trim(base.Path, "/") != "" {
+				return ""
+			}
+			return u.String()
+		}
+	}
+}
+
 var multiSpace = regexp.MustCompile(`[ \t]+`)
 var multiNewline = regexp.MustCompile(`\n{3,}`)
 
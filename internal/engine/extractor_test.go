@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	nurl "net/url"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalLink(t *testing.T) {
+	base, _ := nurl.Parse("https://amp.example.com/news/post.amp")
+	tests := []struct {
+		name, head, want string
+	}{
+		{"absolute", `<link rel="canonical" href="https://example.com/news/post">`, "https://example.com/news/post"},
+		{"relative", `<link rel="Canonical" href="/news/post">`, "https://amp.example.com/news/post"},
+		{"multiple rel values", `<link rel="alternate canonical" href="/p"/>`, "https://amp.example.com/p"},
+		{"other links", `<link rel="stylesheet" href="/a.css"><link rel="amphtml" href="/amp">`, ""},
+		{"site root", `<link rel="canonical" href="https://example.com/">`, ""},
+		{"not http", `<link rel="canonical" href="javascript:void(0)">`, ""},
+		{"in body", `</head><body><link rel="canonical" href="/p">`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := "<html><head><title>x</title>" + tt.head + "</head><body></body></html>"
+			if got := canonicalLink([]byte(body), base); got != tt.want {
+				t.Errorf("canonicalLink = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPExtractor_ReportsResolvedURLs(t *testing.T) {
+	article := "<html><head><link rel=canonical href=/articles/go></head><body><article><h1>Go</h1><p>" +
+		strings.Repeat("Errors are values in Go, and handling them is part of the design. ", 10) +
+		"</p></article></body></html>"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/s/abc", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/articles/go?utm_source=short", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/articles/go", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(article))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	content, err := NewHTTPExtractor().Extract(context.Background(), srv.URL+"/s/abc")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if content.FinalURL != srv.URL+"/articles/go?utm_source=short" {
+		t.Errorf("FinalURL = %q", content.FinalURL)
+	}
+	if content.CanonicalURL != srv.URL+"/articles/go" {
+		t.Errorf("CanonicalURL = %q", content.CanonicalURL)
+	}
+}
@@ -155,3 +155,6 @@ func (c *GeminiClient) doRequest(ctx context.Context, body []byte) (string, erro
 
 	return "", fmt.Errorf("no content in response")
 }
+
+// ModelName returns the configured model name.
+func (c *GeminiClient) ModelName() string { return c.model }
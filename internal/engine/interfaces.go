@@ -11,15 +11,24 @@ type ModelClient interface {
 	Complete(ctx context.Context, prompt string) (string, error)
 }
 
+// ModelNamer is implemented by model clients that can report which model
+// they call, so generated artifacts can record it.
+type ModelNamer interface {
+	ModelName() string
+}
+
 // ContentExtractor abstracts web content extraction.
 type ContentExtractor interface {
 	Extract(ctx context.Context, url string) (*ExtractedContent, error)
 }
 
 // ArtifactStore abstracts artifact persistence so that the engine package
-// does not depend on the store package directly.
+// does not depend on the store package directly. UpsertArtifact must keep,
+// not overwrite, an artifact the user has edited, storing the new version
+// alongside it; GetArtifact returns the artifact that was kept.
 type ArtifactStore interface {
 	UpsertArtifact(ctx context.Context, a model.Artifact) error
+	GetArtifact(ctx context.Context, itemID, artifactType string) (*model.Artifact, error)
 }
 
 // ItemScoreUpdater abstracts updating the AI-derived score and priority on an item.
@@ -27,9 +36,46 @@ type ItemScoreUpdater interface {
 	UpdateItemScoreAndPriority(ctx context.Context, id string, score float64, priority string) error
 }
 
-// ExtractedContent holds the result of content extraction.
+// TagStore abstracts the tag vocabulary and AI-applied item tags.
+// Implementations must never remove tags the user applied themselves.
+type TagStore interface {
+	ListTagVocabulary(ctx context.Context, limit int) ([]string, error)
+	ReplaceAutoTags(ctx context.Context, itemID string, tags []string) error
+}
+
+// TodoStore abstracts persistence of tracked todos. SyncGeneratedTodos must
+// reconcile with, not wipe, todos the user has already edited.
+type TodoStore interface {
+	SyncGeneratedTodos(ctx context.Context, itemID string, generated []model.Todo) error
+}
+
+// DuplicateStore abstracts finding the items an item's resolved URL
+// already belongs to, and folding the item into them.
+type DuplicateStore interface {
+	FindDuplicateOf(ctx context.Context, id, url string) (*model.Item, error)
+	SetResolvedURL(ctx context.Context, id, url string) error
+	FlagDuplicate(ctx context.Context, id, ofID string) (bool, error)
+	MergeItems(ctx context.Context, intoID string, fromIDs []string) (*model.Item, error)
+}
+
+// IntentLister abstracts reading an item's intents, oldest first.
+type IntentLister interface {
+	ListIntents(ctx context.Context, itemID string) ([]model.Intent, error)
+}
+
+// FingerprintStore abstracts persistence of content fingerprints. A nil
+// fingerprint removes the item's stored one.
+type FingerprintStore interface {
+	SetFingerprint(ctx context.Context, itemID string, fp *model.Fingerprint) error
+}
+
+// ExtractedContent holds the result of content extraction. FinalURL is
+// where the item's URL led after redirects, and CanonicalURL is the URL the
+// page declares for itself with <link rel="canonical">, if any.
 type ExtractedContent struct {
 	NormalizedText string      `json:"normalized_text"`
+	FinalURL       string      `json:"final_url,omitempty"`
+	CanonicalURL   string      `json:"canonical_url,omitempty"`
 	Meta           ContentMeta `json:"content_meta"`
 }
 
@@ -71,13 +117,26 @@ type TodosResult struct {
 	Todos []TodoItem `json:"todos"`
 }
 
+// TagSuggestion is a single tag proposed by the model with its confidence (0-1).
+type TagSuggestion struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// TagsResult is the structured output of the tag step.
+type TagsResult struct {
+	Tags []TagSuggestion `json:"tags"`
+}
+
 // StepContext carries data between pipeline steps.
 // Each step reads inputs from previous steps and writes its own output.
 type StepContext struct {
 	Item       *model.Item
-	SaveCount  int // how many times this URL has been saved; used as a scoring boost signal
+	Intent     string // the user's intents as given to prompts; see Pipeline.WithIntents
+	SaveCount  int    // how many times this URL has been saved; used as a scoring boost signal
 	Extraction *ExtractedContent
 	Synthesis  *SynthesisResult
+	Tags       *TagsResult
 	Score      *ScoreResult
 	Todos      *TodosResult
 }
@@ -87,3 +146,8 @@ type Step interface {
 	Name() string
 	Run(ctx context.Context, sc *StepContext) error
 }
+
+// EventPublisher receives pipeline progress events.
+type EventPublisher interface {
+	Publish(e model.Event)
+}
@@ -140,3 +140,6 @@ func (c *OllamaClient) doRequest(ctx context.Context, body []byte) (string, erro
 
 	return ollamaResp.Response, nil
 }
+
+// ModelName returns the configured model name.
+func (c *OllamaClient) ModelName() string { return c.model }
@@ -170,3 +170,6 @@ func (c *OpenAIClient) doRequest(ctx context.Context, body []byte) (string, erro
 
 	return chatResp.Choices[0].Message.Content, nil
 }
+
+// ModelName returns the configured model name.
+func (c *OpenAIClient) ModelName() string { return c.model }
@@ -2,13 +2,17 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/yangwenmai/readdo/internal/model"
 )
 
 // Pipeline orchestrates the execution of a sequence of Steps for an item.
 type Pipeline struct {
-	steps []Step
+	steps   []Step
+	events  EventPublisher
+	intents IntentLister
 }
 
 // NewPipeline creates a pipeline with the given steps, executed in order.
@@ -16,19 +20,63 @@ func NewPipeline(steps ...Step) *Pipeline {
 	return &Pipeline{steps: steps}
 }
 
+// WithEvents makes the pipeline publish step started/finished events to pub.
+func (p *Pipeline) WithEvents(pub EventPublisher) *Pipeline {
+	p.events = pub
+	return p
+}
+
+// WithIntents makes the pipeline build prompts from the item's intents in
+// intents, most recent first with their dates, rather than from the item's
+// intent_text.
+func (p *Pipeline) WithIntents(intents IntentLister) *Pipeline {
+	p.intents = intents
+	return p
+}
+
 // Run executes all pipeline steps for the given item.
 // On success it returns nil. On failure it returns a *StepError indicating
-// which step failed.
+// which step failed. If a step merged the item into another one, Run stops
+// and returns that step's *model.MergedError.
 func (p *Pipeline) Run(ctx context.Context, item *model.Item) error {
-	sc := &StepContext{Item: item, SaveCount: item.SaveCount}
+	sc := &StepContext{Item: item, Intent: item.IntentText, SaveCount: item.SaveCount}
+	if p.intents != nil {
+		intents, err := p.intents.ListIntents(ctx, item.ID)
+		if err != nil {
+			return fmt.Errorf("list intents: %w", err)
+		}
+		if len(intents) > 0 {
+			sc.Intent = model.FormatIntents(intents)
+		}
+	}
 	for _, step := range p.steps {
-		if err := step.Run(ctx, sc); err != nil {
+		p.publish(model.EventStepStarted, item.ID, step.Name(), nil)
+		err := step.Run(ctx, sc)
+		var merged *model.MergedError
+		if errors.As(err, &merged) {
+			p.publish(model.EventStepFinished, item.ID, step.Name(), nil)
+			return merged
+		}
+		p.publish(model.EventStepFinished, item.ID, step.Name(), err)
+		if err != nil {
 			return &StepError{Step: step.Name(), Err: err}
 		}
 	}
 	return nil
 }
 
+func (p *Pipeline) publish(eventType, itemID, step string, err error) {
+	if p.events == nil {
+		return
+	}
+	e := model.NewEvent(eventType, itemID)
+	e.Step = step
+	if err != nil {
+		e.Error = err.Error()
+	}
+	p.events.Publish(e)
+}
+
 // StepError wraps an error with the step name that failed.
 type StepError struct {
 	Step string
@@ -3,14 +3,17 @@ package engine
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/yangwenmai/readdo/internal/model"
 )
 
-// mockArtifactStore records all upserted artifacts.
+// mockArtifactStore records all upserted artifacts. Artifacts in userEdited
+// are kept over generated ones of the same type, as the store does.
 type mockArtifactStore struct {
-	artifacts []model.Artifact
+	artifacts  []model.Artifact
+	userEdited map[string]model.Artifact
 }
 
 func (m *mockArtifactStore) UpsertArtifact(_ context.Context, a model.Artifact) error {
@@ -18,6 +21,18 @@ func (m *mockArtifactStore) UpsertArtifact(_ context.Context, a model.Artifact)
 	return nil
 }
 
+func (m *mockArtifactStore) GetArtifact(_ context.Context, _, artifactType string) (*model.Artifact, error) {
+	if a, ok := m.userEdited[artifactType]; ok {
+		return &a, nil
+	}
+	for i := len(m.artifacts) - 1; i >= 0; i-- {
+		if m.artifacts[i].ArtifactType == artifactType {
+			return &m.artifacts[i], nil
+		}
+	}
+	return nil, errors.New("artifact not found")
+}
+
 // mockScoreUpdater records score update calls.
 type mockScoreUpdater struct {
 	calls []scoreCall
@@ -34,9 +49,20 @@ func (m *mockScoreUpdater) UpdateItemScoreAndPriority(_ context.Context, id stri
 	return nil
 }
 
+// mockTodoStore records synced todos.
+type mockTodoStore struct {
+	synced []model.Todo
+}
+
+func (m *mockTodoStore) SyncGeneratedTodos(_ context.Context, _ string, generated []model.Todo) error {
+	m.synced = generated
+	return nil
+}
+
 func TestPipeline_FullRun(t *testing.T) {
 	as := &mockArtifactStore{}
 	su := &mockScoreUpdater{}
+	ts := &mockTodoStore{}
 	stub := &StubModelClient{}
 	extractor := &StubExtractor{}
 
@@ -44,7 +70,7 @@ func TestPipeline_FullRun(t *testing.T) {
 		&ExtractStep{Extractor: extractor, Artifacts: as},
 		&SynthesizeStep{Model: stub, Artifacts: as},
 		&ScoreStep{Model: stub, Artifacts: as, Scores: su},
-		&TodoStep{Model: stub, Artifacts: as},
+		&TodoStep{Model: stub, Artifacts: as, Todos: ts},
 	)
 
 	item := &model.Item{
@@ -67,6 +93,10 @@ func TestPipeline_FullRun(t *testing.T) {
 	types := map[string]bool{}
 	for _, a := range as.artifacts {
 		types[a.ArtifactType] = true
+		// Generated artifacts record how they were generated.
+		if generated := a.ArtifactType != model.ArtifactExtraction; generated != (a.Model == "stub" && a.PromptVersion != "") {
+			t.Errorf("%s artifact: model = %q, prompt version = %q", a.ArtifactType, a.Model, a.PromptVersion)
+		}
 	}
 	for _, expected := range []string{model.ArtifactExtraction, model.ArtifactSynthesis, model.ArtifactScore, model.ArtifactTodos} {
 		if !types[expected] {
@@ -81,6 +111,14 @@ func TestPipeline_FullRun(t *testing.T) {
 	if su.calls[0].ID != "item-1" {
 		t.Errorf("score update ID = %q, want %q", su.calls[0].ID, "item-1")
 	}
+
+	// Generated todos should be synced into the tracked todos.
+	if len(ts.synced) != 4 {
+		t.Fatalf("synced todos = %d, want 4", len(ts.synced))
+	}
+	if ts.synced[2].Position != 2 || ts.synced[2].Type != model.TodoTypeWrite {
+		t.Errorf("synced todo[2] = %+v, want WRITE at position 2", ts.synced[2])
+	}
 }
 
 // failingStep always returns an error.
@@ -141,6 +179,48 @@ func TestPipeline_StopsOnFirstError(t *testing.T) {
 	}
 }
 
+// mockPublisher records published events.
+type mockPublisher struct {
+	events []model.Event
+}
+
+func (m *mockPublisher) Publish(e model.Event) { m.events = append(m.events, e) }
+
+func TestPipeline_PublishesStepEvents(t *testing.T) {
+	pub := &mockPublisher{}
+	pipeline := NewPipeline(
+		&ExtractStep{Extractor: &StubExtractor{}, Artifacts: &mockArtifactStore{}},
+		&failingStep{name: "fail-step"},
+	).WithEvents(pub)
+
+	item := &model.Item{ID: "item-1", URL: "https://example.com", SaveCount: 1}
+	if err := pipeline.Run(context.Background(), item); err == nil {
+		t.Fatal("expected error from failing step")
+	}
+
+	want := []struct{ typ, step string }{
+		{model.EventStepStarted, "extract"},
+		{model.EventStepFinished, "extract"},
+		{model.EventStepStarted, "fail-step"},
+		{model.EventStepFinished, "fail-step"},
+	}
+	if len(pub.events) != len(want) {
+		t.Fatalf("published %d events, want %d", len(pub.events), len(want))
+	}
+	for i, w := range want {
+		e := pub.events[i]
+		if e.Type != w.typ || e.Step != w.step || e.ItemID != "item-1" {
+			t.Errorf("event[%d] = {%s %s %s}, want {%s %s item-1}", i, e.Type, e.Step, e.ItemID, w.typ, w.step)
+		}
+	}
+	if pub.events[1].Error != "" {
+		t.Errorf("successful step has error %q", pub.events[1].Error)
+	}
+	if pub.events[3].Error != "intentional failure" {
+		t.Errorf("failed step error = %q, want %q", pub.events[3].Error, "intentional failure")
+	}
+}
+
 func TestStepError_Unwrap(t *testing.T) {
 	inner := errors.New("root cause")
 	se := &StepError{Step: "extract", Err: inner}
@@ -152,3 +232,288 @@ func TestStepError_Unwrap(t *testing.T) {
 		t.Error("Unwrap should make inner error accessible via errors.Is")
 	}
 }
+
+// fixedModelClient returns the same response to every prompt.
+type fixedModelClient struct {
+	response string
+}
+
+func (m *fixedModelClient) Complete(_ context.Context, _ string) (string, error) {
+	return m.response, nil
+}
+
+// mockTagStore records auto-applied tags.
+type mockTagStore struct {
+	vocabulary []string
+	applied    []string
+}
+
+func (m *mockTagStore) ListTagVocabulary(_ context.Context, _ int) ([]string, error) {
+	return m.vocabulary, nil
+}
+
+func (m *mockTagStore) ReplaceAutoTags(_ context.Context, _ string, tags []string) error {
+	m.applied = tags
+	return nil
+}
+
+func TestTagStep_AppliesOnlyAboveThreshold(t *testing.T) {
+	as := &mockArtifactStore{}
+	ts := &mockTagStore{vocabulary: []string{"architecture"}}
+	step := &TagStep{Model: &StubModelClient{}, Artifacts: as, Tags: ts, Threshold: 0.7}
+
+	sc := &StepContext{
+		Item:      &model.Item{ID: "item-1"},
+		Intent:    "learn Go patterns",
+		Synthesis: &SynthesisResult{Points: []string{"p"}, Insight: "i"},
+	}
+	if err := step.Run(context.Background(), sc); err != nil {
+		t.Fatalf("TagStep.Run: %v", err)
+	}
+
+	if len(ts.applied) != 1 || ts.applied[0] != "architecture" {
+		t.Errorf("applied = %v, want [architecture]", ts.applied)
+	}
+	if len(as.artifacts) != 1 || as.artifacts[0].ArtifactType != model.ArtifactTags {
+		t.Fatalf("artifacts = %v, want one tags artifact", as.artifacts)
+	}
+	// The artifact keeps every suggestion, including those below threshold.
+	if sc.Tags == nil || len(sc.Tags.Tags) != 2 {
+		t.Errorf("sc.Tags = %v, want 2 suggestions", sc.Tags)
+	}
+}
+
+func TestTagStep_DropsInvalidSuggestions(t *testing.T) {
+	mc := &fixedModelClient{response: `{"tags":[{"name":"` + strings.Repeat("x", 60) + `","confidence":0.9},{"name":"Go","confidence":0.9},{"name":"go","confidence":0.8}]}`}
+	ts := &mockTagStore{}
+	step := &TagStep{Model: mc, Artifacts: &mockArtifactStore{}, Tags: ts, Threshold: 0.7}
+
+	sc := &StepContext{Item: &model.Item{ID: "item-1"}, Synthesis: &SynthesisResult{}}
+	if err := step.Run(context.Background(), sc); err != nil {
+		t.Fatalf("TagStep.Run: %v", err)
+	}
+	if len(ts.applied) != 1 || ts.applied[0] != "go" {
+		t.Errorf("applied = %v, want [go]", ts.applied)
+	}
+}
+
+// promptRecorder answers like StubModelClient and records the prompts.
+type promptRecorder struct {
+	StubModelClient
+	prompts []string
+}
+
+func (m *promptRecorder) Complete(ctx context.Context, prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	return m.StubModelClient.Complete(ctx, prompt)
+}
+
+func TestSteps_BuildOnUserEditedArtifacts(t *testing.T) {
+	userSynthesis := model.NewArtifact("user-syn", "item-1", model.ArtifactSynthesis, `{"points":["mine"],"insight":"the user's insight"}`)
+	userSynthesis.CreatedBy = model.CreatedByUser
+	userTodos := model.NewArtifact("user-todos", "item-1", model.ArtifactTodos, `{"todos":[{"title":"My own todo","eta":"10m","type":"READ"}]}`)
+	userTodos.CreatedBy = model.CreatedByUser
+	as := &mockArtifactStore{userEdited: map[string]model.Artifact{
+		model.ArtifactSynthesis: userSynthesis,
+		model.ArtifactTodos:     userTodos,
+	}}
+	mc := &promptRecorder{}
+	ts := &mockTodoStore{}
+
+	sc := &StepContext{Item: &model.Item{ID: "item-1"}, Intent: "learn Go", Extraction: &ExtractedContent{NormalizedText: "text"}}
+	for _, step := range []Step{
+		&SynthesizeStep{Model: mc, Artifacts: as},
+		&ScoreStep{Model: mc, Artifacts: as, Scores: &mockScoreUpdater{}},
+		&TodoStep{Model: mc, Artifacts: as, Todos: ts},
+	} {
+		if err := step.Run(context.Background(), sc); err != nil {
+			t.Fatalf("%s: %v", step.Name(), err)
+		}
+	}
+
+	if sc.Synthesis == nil || sc.Synthesis.Insight != "the user's insight" {
+		t.Errorf("sc.Synthesis = %+v, want the user's synthesis", sc.Synthesis)
+	}
+	for _, p := range mc.prompts[1:] {
+		if !strings.Contains(p, "the user's insight") {
+			t.Errorf("prompt does not build on the user's synthesis:\n%s", p)
+		}
+	}
+	// The generated todos are only a proposal: the todos table is left alone.
+	if ts.synced != nil {
+		t.Errorf("synced = %v, want no sync while the user's todos are kept", ts.synced)
+	}
+	if sc.Todos == nil || len(sc.Todos.Todos) != 1 || sc.Todos.Todos[0].Title != "My own todo" {
+		t.Errorf("sc.Todos = %+v, want the user's todos", sc.Todos)
+	}
+}
+
+// mockDuplicateStore holds existing items by URL and records what the
+// resolve step did.
+type mockDuplicateStore struct {
+	byURL    map[string]*model.Item
+	resolved string
+	flagged  string
+	merged   string
+}
+
+func (m *mockDuplicateStore) FindDuplicateOf(_ context.Context, _, url string) (*model.Item, error) {
+	return m.byURL[url], nil
+}
+
+func (m *mockDuplicateStore) SetResolvedURL(_ context.Context, _, url string) error {
+	m.resolved = url
+	return nil
+}
+
+func (m *mockDuplicateStore) FlagDuplicate(_ context.Context, _, ofID string) (bool, error) {
+	m.flagged = ofID
+	return true, nil
+}
+
+func (m *mockDuplicateStore) MergeItems(_ context.Context, intoID string, _ []string) (*model.Item, error) {
+	m.merged = intoID
+	return &model.Item{ID: intoID}, nil
+}
+
+// resolvingExtractor reports fixed final and canonical URLs.
+type resolvingExtractor struct {
+	final, canonical string
+}
+
+func (e *resolvingExtractor) Extract(ctx context.Context, url string) (*ExtractedContent, error) {
+	content, _ := (&StubExtractor{}).Extract(ctx, url)
+	content.FinalURL, content.CanonicalURL = e.final, e.canonical
+	return content, nil
+}
+
+func TestResolveStep(t *testing.T) {
+	existing := &model.Item{ID: "existing"}
+	tests := []struct {
+		name                    string
+		final, canonical        string
+		wantMerged, wantFlagged string
+		wantResolved            string
+	}{
+		{"redirect to a saved url merges", "https://example.com/saved", "", "existing", "", ""},
+		{"canonical of a saved url flags", "https://example.com/amp", "https://example.com/saved", "", "existing", "https://example.com/saved"},
+		{"new url is recorded", "https://example.com/new", "https://example.com/new-canonical", "", "", "https://example.com/new-canonical"},
+		{"without canonical the final url is recorded", "https://example.com/new", "", "", "", "https://example.com/new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &mockDuplicateStore{byURL: map[string]*model.Item{"https://example.com/saved": existing}}
+			pipeline := NewPipeline(
+				&ExtractStep{Extractor: &resolvingExtractor{final: tt.final, canonical: tt.canonical}, Artifacts: &mockArtifactStore{}},
+				&ResolveStep{Items: ds},
+				&failingStep{name: "after-resolve"},
+			)
+			err := pipeline.Run(context.Background(), &model.Item{ID: "item-1", URL: "https://t.co/x"})
+
+			var merged *model.MergedError
+			if tt.wantMerged != "" {
+				if !errors.As(err, &merged) || merged.IntoID != tt.wantMerged {
+					t.Fatalf("err = %v, want merged into %s and no later step", err, tt.wantMerged)
+				}
+			} else if errors.As(err, &merged) {
+				t.Fatalf("unexpected merge: %v", err)
+			}
+			if ds.merged != tt.wantMerged || ds.flagged != tt.wantFlagged || ds.resolved != tt.wantResolved {
+				t.Errorf("merged = %q, flagged = %q, resolved = %q", ds.merged, ds.flagged, ds.resolved)
+			}
+		})
+	}
+}
+
+// mockFingerprintStore records the last fingerprint set per item.
+type mockFingerprintStore struct {
+	set map[string]*model.Fingerprint
+}
+
+func (m *mockFingerprintStore) SetFingerprint(_ context.Context, itemID string, fp *model.Fingerprint) error {
+	m.set[itemID] = fp
+	return nil
+}
+
+// textExtractor returns a fixed text for every URL.
+type textExtractor struct {
+	text string
+}
+
+func (e *textExtractor) Extract(ctx context.Context, url string) (*ExtractedContent, error) {
+	return &ExtractedContent{NormalizedText: e.text}, nil
+}
+
+func TestExtractStep_Fingerprint(t *testing.T) {
+	long := strings.Repeat("Errors are values, and values can be programmed like any other value. ", 10)
+	want, _ := model.SimHash(long)
+	fs := &mockFingerprintStore{set: map[string]*model.Fingerprint{}}
+
+	step := &ExtractStep{Extractor: &textExtractor{text: long}, Artifacts: &mockArtifactStore{}, Fingerprints: fs}
+	if err := step.Run(context.Background(), &StepContext{Item: &model.Item{ID: "long"}}); err != nil {
+		t.Fatal(err)
+	}
+	if fp, ok := fs.set["long"]; !ok || fp == nil || *fp != want {
+		t.Errorf("fingerprint = %v, want %s", fp, want)
+	}
+
+	// Too short a text clears any fingerprint stored before.
+	step.Extractor = &StubExtractor{}
+	if err := step.Run(context.Background(), &StepContext{Item: &model.Item{ID: "short"}}); err != nil {
+		t.Fatal(err)
+	}
+	if fp, ok := fs.set["short"]; !ok || fp != nil {
+		t.Errorf("short text: fingerprint = %v, set = %v; want cleared", fp, ok)
+	}
+}
+
+// mockIntentLister returns fixed intents for every item.
+type mockIntentLister struct {
+	intents []model.Intent
+}
+
+func (m *mockIntentLister) ListIntents(_ context.Context, _ string) ([]model.Intent, error) {
+	return m.intents, nil
+}
+
+// intentStep records the intent the steps were given.
+type intentStep struct {
+	got string
+}
+
+func (s *intentStep) Name() string { return "intent" }
+
+func (s *intentStep) Run(_ context.Context, sc *StepContext) error {
+	s.got = sc.Intent
+	return nil
+}
+
+func TestPipeline_WithIntents(t *testing.T) {
+	item := &model.Item{ID: "item-1", IntentText: "stale\n---\ntext"}
+
+	step := &intentStep{}
+	if err := NewPipeline(step).Run(context.Background(), item); err != nil {
+		t.Fatal(err)
+	}
+	if step.got != item.IntentText {
+		t.Errorf("without intents: Intent = %q, want intent_text", step.got)
+	}
+
+	lister := &mockIntentLister{intents: []model.Intent{
+		{Text: "first", CreatedAt: "2026-09-01T08:00:00Z"},
+		{Text: "second", CreatedAt: "2026-10-01T08:00:00Z"},
+	}}
+	if err := NewPipeline(step).WithIntents(lister).Run(context.Background(), item); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[2026-10-01] second\n---\n[2026-09-01] first"; step.got != want {
+		t.Errorf("Intent = %q, want %q", step.got, want)
+	}
+
+	// An item without intent records falls back to its intent_text.
+	lister.intents = nil
+	NewPipeline(step).WithIntents(lister).Run(context.Background(), item)
+	if step.got != item.IntentText {
+		t.Errorf("no intents: Intent = %q, want intent_text", step.got)
+	}
+}
@@ -4,8 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"unicode/utf8"
+
+	"github.com/yangwenmai/readdo/internal/model"
 )
 
+// promptVersions identifies the current prompt for each generated artifact
+// type and is recorded with every generation. Bump a version whenever its
+// prompt changes, so artifact history shows which prompt produced what.
+var promptVersions = map[string]string{
+	model.ArtifactSynthesis: "1",
+	model.ArtifactTags:      "1",
+	model.ArtifactScore:     "1",
+	model.ArtifactTodos:     "1",
+}
+
 func buildSynthesisPrompt(text, intent string) string {
 	return fmt.Sprintf(`你是一位专业的阅读顾问。用户保存了一篇文章，并留下了阅读意图。请以用户的意图为锚点，从文章中提取对用户最有价值的内容。
 
@@ -27,6 +39,28 @@ func buildSynthesisPrompt(text, intent string) string {
 %s`, intent, truncateRunes(text, 12000))
 }
 
+// maxTagVocabulary caps how many existing tags are offered to the model.
+const maxTagVocabulary = 200
+
+func buildTagPrompt(intent string, synthesis *SynthesisResult, vocabulary []string) string {
+	synthesisJSON := mustJSON(synthesis)
+	vocabularyJSON := mustJSON(vocabulary)
+	return fmt.Sprintf(`你是一位内容分类专家。请为用户保存的这篇文章选择标签。
+
+用户的阅读意图："%s"
+文章结合解答：%s
+用户已有的标签：%s
+
+请仅输出合法的 JSON（不要 markdown、不要额外解释），结构如下：
+{"tags": [{"name": "go", "confidence": 0.9}, ...]}
+
+规则：
+- 1 到 5 个标签
+- 优先从「用户已有的标签」中选择，只有在没有合适的已有标签时才提出新标签
+- 新标签应简短（1-3 个词）、小写、表达主题而非情绪
+- confidence（0-1）：你对该标签适用于这篇文章的把握程度`, intent, synthesisJSON, vocabularyJSON)
+}
+
 func buildScorePrompt(intent string, synthesis *SynthesisResult, extraction *ExtractedContent, saveCount int) string {
 	synthesisJSON := mustJSON(synthesis)
 	saveCountHint := ""
@@ -13,37 +13,59 @@ import (
 // Helper: run an LLM step and persist the result as an artifact.
 // ---------------------------------------------------------------------------
 
-func runLLMStep[T any](ctx context.Context, mc ModelClient, as ArtifactStore, itemID, artifactType, prompt string) (*T, error) {
+// runLLMStep returns the item's artifact after the step. When the user has
+// edited the artifact, the new result is only stored as a proposal: the
+// user's version is returned instead, with proposed set, so that later
+// steps build on what the user kept.
+func runLLMStep[T any](ctx context.Context, mc ModelClient, as ArtifactStore, itemID, artifactType, prompt string) (result *T, proposed bool, err error) {
 	raw, err := mc.Complete(ctx, prompt)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	var result T
-	if err := json.Unmarshal([]byte(raw), &result); err != nil {
-		return nil, fmt.Errorf("unmarshal %s: %w", artifactType, err)
+	var generated T
+	if err := json.Unmarshal([]byte(raw), &generated); err != nil {
+		return nil, false, fmt.Errorf("unmarshal %s: %w", artifactType, err)
 	}
 
-	payload, err := json.Marshal(result)
+	payload, err := json.Marshal(generated)
 	if err != nil {
-		return nil, fmt.Errorf("marshal %s artifact: %w", artifactType, err)
+		return nil, false, fmt.Errorf("marshal %s artifact: %w", artifactType, err)
 	}
 
 	artifact := model.NewArtifact(uuid.New().String(), itemID, artifactType, string(payload))
+	artifact.PromptVersion = promptVersions[artifactType]
+	if n, ok := mc.(ModelNamer); ok {
+		artifact.Model = n.ModelName()
+	}
 	if err := as.UpsertArtifact(ctx, artifact); err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	current, err := as.GetArtifact(ctx, itemID, artifactType)
+	if err != nil {
+		return nil, false, fmt.Errorf("get %s artifact: %w", artifactType, err)
 	}
-	return &result, nil
+	if current.ID == artifact.ID {
+		return &generated, false, nil
+	}
+	var kept T
+	if err := json.Unmarshal([]byte(current.Payload), &kept); err != nil {
+		return nil, false, fmt.Errorf("unmarshal kept %s: %w", artifactType, err)
+	}
+	return &kept, true, nil
 }
 
 // ---------------------------------------------------------------------------
 // Step 1: Extract
 // ---------------------------------------------------------------------------
 
-// ExtractStep fetches and extracts web content.
+// ExtractStep fetches and extracts web content. If Fingerprints is set, it
+// also stores a SimHash fingerprint of the text for near-duplicate lookup.
 type ExtractStep struct {
-	Extractor ContentExtractor
-	Artifacts ArtifactStore
+	Extractor    ContentExtractor
+	Artifacts    ArtifactStore
+	Fingerprints FingerprintStore
 }
 
 func (s *ExtractStep) Name() string { return "extract" }
@@ -63,16 +85,76 @@ func (s *ExtractStep) Run(ctx context.Context, sc *StepContext) error {
 	if err := s.Artifacts.UpsertArtifact(ctx, artifact); err != nil {
 		return err
 	}
+	if s.Fingerprints != nil {
+		var fp *model.Fingerprint
+		if f, ok := model.SimHash(content.NormalizedText); ok {
+			fp = &f
+		}
+		if err := s.Fingerprints.SetFingerprint(ctx, sc.Item.ID, fp); err != nil {
+			return fmt.Errorf("set fingerprint: %w", err)
+		}
+	}
 
 	sc.Extraction = content
 	return nil
 }
 
+// ---------------------------------------------------------------------------
+// Step 1b: Resolve
+// ---------------------------------------------------------------------------
+
+// ResolveStep checks where the extracted page actually is against the other
+// items. If the URL redirected to an item already saved, the item is merged
+// into that one and processing stops with a *model.MergedError. A page's
+// rel=canonical is only a claim by the page, so an item it points at is
+// flagged as a suspected duplicate for the user to confirm instead.
+type ResolveStep struct {
+	Items DuplicateStore
+}
+
+func (s *ResolveStep) Name() string { return "resolve" }
+
+func (s *ResolveStep) Run(ctx context.Context, sc *StepContext) error {
+	ext := sc.Extraction
+	if ext == nil || (ext.FinalURL == "" && ext.CanonicalURL == "") {
+		return nil
+	}
+
+	if ext.FinalURL != "" {
+		dup, err := s.Items.FindDuplicateOf(ctx, sc.Item.ID, ext.FinalURL)
+		if err != nil {
+			return fmt.Errorf("find duplicate: %w", err)
+		}
+		if dup != nil {
+			if _, err := s.Items.MergeItems(ctx, dup.ID, []string{sc.Item.ID}); err != nil {
+				return fmt.Errorf("merge into %s: %w", dup.ID, err)
+			}
+			return &model.MergedError{IntoID: dup.ID}
+		}
+	}
+
+	resolved := ext.FinalURL
+	if ext.CanonicalURL != "" {
+		resolved = ext.CanonicalURL
+		dup, err := s.Items.FindDuplicateOf(ctx, sc.Item.ID, ext.CanonicalURL)
+		if err != nil {
+			return fmt.Errorf("find duplicate: %w", err)
+		}
+		if dup != nil {
+			if _, err := s.Items.FlagDuplicate(ctx, sc.Item.ID, dup.ID); err != nil {
+				return fmt.Errorf("flag duplicate: %w", err)
+			}
+		}
+	}
+	return s.Items.SetResolvedURL(ctx, sc.Item.ID, resolved)
+}
+
 // ---------------------------------------------------------------------------
 // Step 2: Synthesize
 // ---------------------------------------------------------------------------
 
-// SynthesizeStep generates an intent-driven synthesis using an LLM.
+// SynthesizeStep generates an intent-driven synthesis using an LLM. If the
+// user has edited the synthesis, later steps use the user's version.
 type SynthesizeStep struct {
 	Model     ModelClient
 	Artifacts ArtifactStore
@@ -81,8 +163,8 @@ type SynthesizeStep struct {
 func (s *SynthesizeStep) Name() string { return "synthesize" }
 
 func (s *SynthesizeStep) Run(ctx context.Context, sc *StepContext) error {
-	prompt := buildSynthesisPrompt(sc.Extraction.NormalizedText, sc.Item.IntentText)
-	result, err := runLLMStep[SynthesisResult](ctx, s.Model, s.Artifacts, sc.Item.ID, model.ArtifactSynthesis, prompt)
+	prompt := buildSynthesisPrompt(sc.Extraction.NormalizedText, sc.Intent)
+	result, _, err := runLLMStep[SynthesisResult](ctx, s.Model, s.Artifacts, sc.Item.ID, model.ArtifactSynthesis, prompt)
 	if err != nil {
 		return err
 	}
@@ -90,6 +172,56 @@ func (s *SynthesizeStep) Run(ctx context.Context, sc *StepContext) error {
 	return nil
 }
 
+// ---------------------------------------------------------------------------
+// Step 2b: Tag
+// ---------------------------------------------------------------------------
+
+// TagStep asks the model to classify the item into tags, preferring the
+// existing vocabulary. All suggestions are stored as an artifact; only those
+// at or above Threshold are applied to the item.
+type TagStep struct {
+	Model     ModelClient
+	Artifacts ArtifactStore
+	Tags      TagStore
+	Threshold float64
+}
+
+func (s *TagStep) Name() string { return "tag" }
+
+func (s *TagStep) Run(ctx context.Context, sc *StepContext) error {
+	vocabulary, err := s.Tags.ListTagVocabulary(ctx, maxTagVocabulary)
+	if err != nil {
+		return fmt.Errorf("list tag vocabulary: %w", err)
+	}
+
+	prompt := buildTagPrompt(sc.Intent, sc.Synthesis, vocabulary)
+	result, _, err := runLLMStep[TagsResult](ctx, s.Model, s.Artifacts, sc.Item.ID, model.ArtifactTags, prompt)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, t := range result.Tags {
+		if t.Confidence < s.Threshold {
+			continue
+		}
+		// An invalid suggestion is dropped rather than failing the item.
+		if n, err := model.NormalizeTags([]string{t.Name}); err == nil {
+			names = append(names, n...)
+		}
+	}
+	names, err = model.NormalizeTags(names)
+	if err != nil {
+		return fmt.Errorf("normalize tags: %w", err)
+	}
+	if err := s.Tags.ReplaceAutoTags(ctx, sc.Item.ID, names); err != nil {
+		return fmt.Errorf("apply tags: %w", err)
+	}
+
+	sc.Tags = result
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Step 3: Score
 // ---------------------------------------------------------------------------
@@ -104,8 +236,8 @@ type ScoreStep struct {
 func (s *ScoreStep) Name() string { return "score" }
 
 func (s *ScoreStep) Run(ctx context.Context, sc *StepContext) error {
-	prompt := buildScorePrompt(sc.Item.IntentText, sc.Synthesis, sc.Extraction, sc.SaveCount)
-	result, err := runLLMStep[ScoreResult](ctx, s.Model, s.Artifacts, sc.Item.ID, model.ArtifactScore, prompt)
+	prompt := buildScorePrompt(sc.Intent, sc.Synthesis, sc.Extraction, sc.SaveCount)
+	result, _, err := runLLMStep[ScoreResult](ctx, s.Model, s.Artifacts, sc.Item.ID, model.ArtifactScore, prompt)
 	if err != nil {
 		return err
 	}
@@ -122,20 +254,37 @@ func (s *ScoreStep) Run(ctx context.Context, sc *StepContext) error {
 // Step 4: Todo
 // ---------------------------------------------------------------------------
 
-// TodoStep generates actionable TODO items using an LLM.
+// TodoStep generates actionable TODO items using an LLM and syncs them into
+// the tracked todos, keeping any the user has already edited. Todos
+// generated while the user's todos artifact is kept are only proposed; they
+// are synced when the user accepts the proposal.
 type TodoStep struct {
 	Model     ModelClient
 	Artifacts ArtifactStore
+	Todos     TodoStore
 }
 
 func (s *TodoStep) Name() string { return "todo" }
 
 func (s *TodoStep) Run(ctx context.Context, sc *StepContext) error {
-	prompt := buildTodoPrompt(sc.Item.IntentText, sc.Synthesis, sc.Score)
-	result, err := runLLMStep[TodosResult](ctx, s.Model, s.Artifacts, sc.Item.ID, model.ArtifactTodos, prompt)
+	prompt := buildTodoPrompt(sc.Intent, sc.Synthesis, sc.Score)
+	result, proposed, err := runLLMStep[TodosResult](ctx, s.Model, s.Artifacts, sc.Item.ID, model.ArtifactTodos, prompt)
 	if err != nil {
 		return err
 	}
+	if proposed {
+		sc.Todos = result
+		return nil
+	}
+
+	generated := make([]model.Todo, len(result.Todos))
+	for i, t := range result.Todos {
+		generated[i] = model.NewTodo(uuid.New().String(), sc.Item.ID, t.Title, t.ETA, t.Type, i)
+	}
+	if err := s.Todos.SyncGeneratedTodos(ctx, sc.Item.ID, generated); err != nil {
+		return fmt.Errorf("sync todos: %w", err)
+	}
+
 	sc.Todos = result
 	return nil
 }
@@ -22,6 +22,8 @@ func (e *StubExtractor) Extract(_ context.Context, url string) (*ExtractedConten
 // StubModelClient returns mock LLM responses (for development/testing).
 type StubModelClient struct{}
 
+func (m *StubModelClient) ModelName() string { return "stub" }
+
 func (m *StubModelClient) Complete(_ context.Context, prompt string) (string, error) {
 	if strings.Contains(prompt, "阅读顾问") {
 		result := SynthesisResult{
@@ -36,6 +38,17 @@ func (m *StubModelClient) Complete(_ context.Context, prompt string) (string, er
 		return string(b), nil
 	}
 
+	if strings.Contains(prompt, "内容分类") {
+		result := TagsResult{
+			Tags: []TagSuggestion{
+				{Name: "architecture", Confidence: 0.9},
+				{Name: "best practices", Confidence: 0.6},
+			},
+		}
+		b, _ := json.Marshal(result)
+		return string(b), nil
+	}
+
 	if strings.Contains(prompt, "内容评估") {
 		result := ScoreResult{
 			IntentScore:  78,
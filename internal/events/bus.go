@@ -0,0 +1,165 @@
+// Package events provides an in-process publish/subscribe bus for item
+// lifecycle events, with a bounded history so subscribers can resume.
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// subscriberBuffer is how many events may queue for a subscriber before it is
+// considered too slow and dropped.
+const subscriberBuffer = 64
+
+// Bus fans events out to subscribers and keeps the most recent ones in a ring
+// buffer for Last-Event-ID replay.
+type Bus struct {
+	mu     sync.Mutex
+	lastID int64
+	ring   []model.Event // oldest first once full, starting at head
+	head   int
+	subs   map[*Subscription]struct{}
+}
+
+// Subscription receives events published after it was created.
+// C is closed when the subscription is closed or falls too far behind.
+type Subscription struct {
+	C   <-chan model.Event
+	ch  chan model.Event
+	bus *Bus
+}
+
+// NewBus creates a bus that retains the last capacity events.
+func NewBus(capacity int) *Bus {
+	return &Bus{ring: make([]model.Event, 0, capacity), subs: map[*Subscription]struct{}{}}
+}
+
+// Publish assigns the event the next ID, records it and delivers it to every
+// subscriber. Subscribers whose buffer is full are dropped rather than
+// blocking the publisher; they can resume with Last-Event-ID.
+func (b *Bus) Publish(e model.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e.ID = b.lastID
+	if len(b.ring) < cap(b.ring) {
+		b.ring = append(b.ring, e)
+	} else if cap(b.ring) > 0 {
+		b.ring[b.head] = e
+		b.head = (b.head + 1) % cap(b.ring)
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- e:
+		default:
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. It returns the retained events with an ID
+// greater than lastID, and complete=false when some events after lastID have
+// already left the buffer (the client should then refetch its state).
+// A lastID of 0 replays nothing.
+func (b *Bus) Subscribe(lastID int64) (sub *Subscription, replay []model.Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = true
+	if lastID > 0 {
+		history := b.history()
+		for _, e := range history {
+			if e.ID > lastID {
+				replay = append(replay, e)
+			}
+		}
+		oldest := b.lastID + 1
+		if len(history) > 0 {
+			oldest = history[0].ID
+		}
+		complete = lastID >= oldest-1 && lastID <= b.lastID
+	}
+
+	ch := make(chan model.Event, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, bus: b}
+	b.subs[sub] = struct{}{}
+	return sub, replay, complete
+}
+
+// Close unregisters the subscription and closes its channel.
+// It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subs[s]; ok {
+		delete(s.bus.subs, s)
+		close(s.ch)
+	}
+}
+
+// Consume calls fn for every event published from now on, until ctx is
+// cancelled. If the subscription is dropped for falling behind, it
+// resubscribes and resumes after the last event handled, so a slow consumer
+// only misses events that have already left the buffer. Events already
+// queued for the consumer when ctx is cancelled are still passed to fn
+// before Consume returns, so fn must not rely on ctx being live.
+func (b *Bus) Consume(ctx context.Context, name string, fn func(model.Event)) {
+	var lastID int64
+	for {
+		sub, replay, complete := b.Subscribe(lastID)
+		if !complete {
+			slog.Warn("event consumer missed events", "consumer", name, "after_id", lastID)
+		}
+		for _, e := range replay {
+			fn(e)
+			lastID = e.ID
+		}
+		if !b.drain(ctx, sub, func(e model.Event) {
+			fn(e)
+			lastID = e.ID
+		}) {
+			return
+		}
+	}
+}
+
+// drain passes events from sub to fn until ctx is cancelled (returning false,
+// after the events still queued) or the subscription is dropped (returning
+// true).
+func (b *Bus) drain(ctx context.Context, sub *Subscription, fn func(model.Event)) bool {
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case e, ok := <-sub.C:
+					if !ok {
+						return false
+					}
+					fn(e)
+				default:
+					return false
+				}
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				return true
+			}
+			fn(e)
+		}
+	}
+}
+
+// history returns the retained events oldest first. Callers hold b.mu.
+func (b *Bus) history() []model.Event {
+	out := make([]model.Event, 0, len(b.ring))
+	out = append(out, b.ring[b.head:]...)
+	return append(out, b.ring[:b.head]...)
+}
@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	b := NewBus(10)
+	sub, replay, complete := b.Subscribe(0)
+	defer sub.Close()
+	if len(replay) != 0 || !complete {
+		t.Fatalf("fresh subscribe: replay=%d complete=%v, want 0/true", len(replay), complete)
+	}
+
+	b.Publish(model.NewEvent(model.EventItemCaptured, "a"))
+	b.Publish(model.NewEvent(model.EventItemReady, "a"))
+
+	for i, wantType := range []string{model.EventItemCaptured, model.EventItemReady} {
+		e := <-sub.C
+		if e.ID != int64(i+1) || e.Type != wantType {
+			t.Errorf("event %d = {%d %s}, want {%d %s}", i, e.ID, e.Type, i+1, wantType)
+		}
+	}
+}
+
+func TestBus_Replay(t *testing.T) {
+	tests := []struct {
+		name         string
+		lastID       int64
+		wantIDs      []int64
+		wantComplete bool
+	}{
+		{"no resume", 0, nil, true},
+		{"up to date", 5, nil, true},
+		{"within history", 3, []int64{4, 5}, true},
+		{"oldest retained is next", 2, []int64{3, 4, 5}, true},
+		{"gap", 1, []int64{3, 4, 5}, false},
+		{"from the future", 9, nil, false},
+	}
+	b := NewBus(3)
+	for i := 0; i < 5; i++ {
+		b.Publish(model.NewEvent(model.EventStatusChanged, "a"))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, replay, complete := b.Subscribe(tt.lastID)
+			defer sub.Close()
+			if complete != tt.wantComplete {
+				t.Errorf("complete = %v, want %v", complete, tt.wantComplete)
+			}
+			if len(replay) != len(tt.wantIDs) {
+				t.Fatalf("replay = %d events, want %d", len(replay), len(tt.wantIDs))
+			}
+			for i, e := range replay {
+				if e.ID != tt.wantIDs[i] {
+					t.Errorf("replay[%d].ID = %d, want %d", i, e.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBus_DropsSlowSubscriber(t *testing.T) {
+	b := NewBus(0)
+	slow, _, _ := b.Subscribe(0)
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.Publish(model.NewEvent(model.EventStatusChanged, "a"))
+	}
+
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("slow subscriber received %d events before being dropped, want %d", n, subscriberBuffer)
+	}
+	slow.Close() // must not panic after the bus closed the channel
+}
+
+func TestSubscription_Close(t *testing.T) {
+	b := NewBus(10)
+	sub, _, _ := b.Subscribe(0)
+	sub.Close()
+	sub.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("channel still open after Close")
+	}
+	b.Publish(model.NewEvent(model.EventItemDeleted, "a")) // no subscribers left
+}
+
+func TestBus_ConsumeResumesAfterDrop(t *testing.T) {
+	b := NewBus(200)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := make(chan int64, 1)
+	release := make(chan struct{})
+	got := make(chan int64, 200)
+	var once bool
+	go b.Consume(ctx, "test", func(e model.Event) {
+		if !once {
+			once = true
+			first <- e.ID
+			<-release // fall behind so the bus drops the subscription
+		}
+		got <- e.ID
+	})
+
+	// Publish until the consumer has subscribed and picked up an event.
+	var start int64
+	for start == 0 {
+		b.Publish(model.NewEvent(model.EventStatusChanged, "a"))
+		select {
+		case start = <-first:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	for i := 0; i < subscriberBuffer*2; i++ {
+		b.Publish(model.NewEvent(model.EventStatusChanged, "a"))
+	}
+	close(release)
+
+	// Every event from the first one on arrives exactly once, in order.
+	for id := start; id <= start+subscriberBuffer*2; id++ {
+		select {
+		case e := <-got:
+			if e != id {
+				t.Fatalf("got event %d, want %d", e, id)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for event %d", id)
+		}
+	}
+}
+
+func TestBus_ConsumeHandlesQueuedEventsOnCancel(t *testing.T) {
+	b := NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	first := make(chan struct{})
+	release := make(chan struct{})
+	var handled int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Consume(ctx, "test", func(e model.Event) {
+			if handled == 0 {
+				close(first)
+				<-release
+			}
+			handled++
+		})
+	}()
+
+	// Publish until the consumer has subscribed and is busy with an event.
+	published := 0
+	for busy := false; !busy; {
+		b.Publish(model.NewEvent(model.EventStatusChanged, "a"))
+		published++
+		select {
+		case <-first:
+			busy = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	for i := 0; i < 3; i++ {
+		b.Publish(model.NewEvent(model.EventStatusChanged, "a"))
+	}
+	cancel()
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Consume did not return after cancel")
+	}
+	// The busy event, the three queued after it and any published before
+	// the consumer picked one up.
+	if handled < 4 || handled > published+3 {
+		t.Errorf("handled = %d, want the 3 queued events handled too", handled)
+	}
+}
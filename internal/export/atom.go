@@ -0,0 +1,156 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// atomPriorityScheme marks the category that carries an item's priority,
+// as opposed to its tags.
+const atomPriorityScheme = "urn:readdo:priority"
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomCategory struct {
+	Term   string `xml:"term,attr"`
+	Scheme string `xml:"scheme,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    atomText       `xml:"content"`
+}
+
+// RenderAtom renders items as an Atom feed. Each entry links to the
+// article and carries the synthesis insight and points as its content.
+// An entry's updated time is that of the item's newest artifact, so
+// readers pick up summaries rewritten by reprocessing; the feed's is the
+// newest entry's, or the feed definition's when it has no entries.
+// selfURL is the feed's own absolute URL.
+func RenderAtom(feed model.Feed, items []model.ItemWithArtifacts, selfURL string) []byte {
+	out := atomFeed{
+		ID:      "urn:readdo:feed:" + feed.ID,
+		Title:   "readdo: " + feed.Name,
+		Updated: feed.UpdatedAt,
+		Author:  atomPerson{Name: "readdo"},
+		Links:   []atomLink{{Rel: "self", Href: selfURL}},
+	}
+	for i := range items {
+		e := atomItemEntry(&items[i])
+		if laterThan(e.Updated, out.Updated) {
+			out.Updated = e.Updated
+		}
+		out.Entries = append(out.Entries, e)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	enc.Encode(out) // only fails on unsupported types
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+func atomItemEntry(item *model.ItemWithArtifacts) atomEntry {
+	var syn synthesis
+	updated := item.CreatedAt
+	for _, a := range item.Artifacts {
+		if a.ArtifactType == model.ArtifactSynthesis {
+			json.Unmarshal([]byte(a.Payload), &syn)
+		}
+		if laterThan(a.CreatedAt, updated) {
+			updated = a.CreatedAt
+		}
+	}
+
+	title := item.Title
+	if title == "" {
+		title = item.URL
+	}
+	e := atomEntry{
+		ID:        "urn:readdo:item:" + item.ID,
+		Title:     title,
+		Link:      atomLink{Rel: "alternate", Href: item.URL},
+		Published: item.CreatedAt,
+		Updated:   updated,
+		Content:   atomText{Type: "html", Body: atomContent(item, syn)},
+	}
+	if item.Priority != nil {
+		e.Categories = append(e.Categories, atomCategory{Term: *item.Priority, Scheme: atomPriorityScheme})
+	}
+	for _, t := range item.Tags {
+		e.Categories = append(e.Categories, atomCategory{Term: t})
+	}
+	if insight := strings.TrimSpace(syn.Insight); insight != "" {
+		e.Summary = &atomText{Type: "text", Body: insight}
+	}
+	return e
+}
+
+// atomContent renders the entry body as HTML: the insight, the points and
+// a link to the source.
+func atomContent(item *model.ItemWithArtifacts, syn synthesis) string {
+	var b strings.Builder
+	if insight := strings.TrimSpace(syn.Insight); insight != "" {
+		b.WriteString("<p>" + html.EscapeString(insight) + "</p>\n")
+	}
+	if len(syn.Points) > 0 {
+		b.WriteString("<ul>\n")
+		for _, p := range syn.Points {
+			b.WriteString("<li>" + html.EscapeString(p) + "</li>\n")
+		}
+		b.WriteString("</ul>\n")
+	}
+	source := item.Domain
+	if source == "" {
+		source = item.URL
+	}
+	b.WriteString(`<p><a href="` + html.EscapeString(item.URL) + `">` + html.EscapeString(source) + "</a></p>")
+	return b.String()
+}
+
+// laterThan reports whether RFC 3339 timestamp a is after b. An unparseable
+// a is never later; an unparseable b is always earlier.
+func laterThan(a, b string) bool {
+	ta, err := time.Parse(time.RFC3339, a)
+	if err != nil {
+		return false
+	}
+	tb, err := time.Parse(time.RFC3339, b)
+	return err != nil || ta.After(tb)
+}
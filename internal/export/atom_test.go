@@ -0,0 +1,76 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestRenderAtom(t *testing.T) {
+	feed := model.NewFeed("f-1", "Go", nil, nil, nil, "tok")
+	feed.UpdatedAt = "2026-01-01T00:00:00Z"
+
+	first := model.ItemWithArtifacts{
+		Item: calendarItem("a", model.PriorityDoFirst),
+		Tags: []string{"go"},
+		Artifacts: []model.Artifact{
+			{ArtifactType: model.ArtifactSynthesis, Payload: `{"points":["Use <ctx>","Wrap & return"],"insight":"Errors are values"}`, CreatedAt: "2026-06-03T10:00:00Z"},
+			{ArtifactType: model.ArtifactScore, Payload: `{}`, CreatedAt: "2026-06-02T10:00:00Z"},
+		},
+	}
+	first.Domain = "example.com"
+	bare := model.ItemWithArtifacts{Item: calendarItem("b", model.PriorityPlanIt)}
+	bare.Title = ""
+
+	out := RenderAtom(feed, []model.ItemWithArtifacts{first, bare}, "https://readdo.local/feeds/ready.atom")
+
+	var parsed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID       string `xml:"id"`
+			Title    string `xml:"title"`
+			Updated  string `xml:"updated"`
+			Summary  string `xml:"summary"`
+			Content  string `xml:"content"`
+			Category []struct {
+				Term   string `xml:"term,attr"`
+				Scheme string `xml:"scheme,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("feed is not valid Atom XML: %v\n%s", err, out)
+	}
+	if len(parsed.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(parsed.Entries))
+	}
+	if parsed.Updated != "2026-06-03T10:00:00Z" {
+		t.Errorf("feed updated = %s, want the newest artifact", parsed.Updated)
+	}
+
+	e := parsed.Entries[0]
+	if e.ID != "urn:readdo:item:a" || e.Title != "Item a" || e.Updated != "2026-06-03T10:00:00Z" || e.Summary != "Errors are values" {
+		t.Errorf("entry = %+v", e)
+	}
+	for _, want := range []string{"<p>Errors are values</p>", "<li>Use &lt;ctx&gt;</li>", "<li>Wrap &amp; return</li>", `<a href="https://example.com/a">example.com</a>`} {
+		if !strings.Contains(e.Content, want) {
+			t.Errorf("content missing %q:\n%s", want, e.Content)
+		}
+	}
+	if len(e.Category) != 2 || e.Category[0].Term != model.PriorityDoFirst || e.Category[0].Scheme != atomPriorityScheme || e.Category[1].Term != "go" {
+		t.Errorf("categories = %+v", e.Category)
+	}
+
+	// Without artifacts the entry is as old as the item and titled by its URL.
+	if e := parsed.Entries[1]; e.Updated != "2026-06-01T08:00:00Z" || e.Title != "https://example.com/b" || e.Summary != "" {
+		t.Errorf("bare entry = %+v", e)
+	}
+
+	// An empty feed still has a valid updated time.
+	if out := string(RenderAtom(feed, nil, "https://x/")); !strings.Contains(out, "<updated>2026-01-01T00:00:00Z</updated>") {
+		t.Errorf("empty feed:\n%s", out)
+	}
+}
@@ -0,0 +1,291 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+const (
+	// icsLineLimit is the maximum length of an iCalendar content line in
+	// octets, excluding the line break (RFC 5545 §3.1).
+	icsLineLimit = 75
+	// blockGranularity is what time blocks are aligned to.
+	blockGranularity = 15 * time.Minute
+
+	icsDateTime = "20060102T150405Z"
+	icsDate     = "20060102"
+)
+
+// calendarPriorities are the item priorities exported to the calendar, with
+// their iCalendar PRIORITY (1 is highest).
+var calendarPriorities = map[string]int{
+	model.PriorityDoFirst: 1,
+	model.PriorityPlanIt:  5,
+}
+
+// CalendarPriorities returns the item priorities included in the calendar.
+func CalendarPriorities() []string {
+	return []string{model.PriorityDoFirst, model.PriorityPlanIt}
+}
+
+// WorkingHours is a daily window that calendar entries are time-blocked into.
+type WorkingHours struct {
+	Start    time.Duration // offset from midnight
+	End      time.Duration // offset from midnight, after Start
+	Location *time.Location
+	Weekends bool // also schedule on Saturdays and Sundays
+}
+
+// ParseWorkingHours parses a window such as "09:00-17:30".
+func ParseWorkingHours(s string) (start, end time.Duration, err error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("working hours must look like 09:00-17:00")
+	}
+	if start, err = parseClock(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(to); err != nil {
+		return 0, 0, err
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("working hours must end after they start")
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, want HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// CalendarOptions controls RenderCalendar.
+type CalendarOptions struct {
+	Now   time.Time
+	Hours *WorkingHours // when set, entries are also time-blocked as events
+}
+
+// calendarEntry is one thing to do: an open todo, or a DO_FIRST item
+// without open todos (reading it).
+type calendarEntry struct {
+	uid      string
+	summary  string
+	item     model.Item
+	priority int
+	effort   time.Duration
+	due      *string
+	stamp    time.Time
+}
+
+// RenderCalendar renders the open todos of DO_FIRST and PLAN_IT items as an
+// iCalendar feed. Every todo becomes a VTODO carrying its estimated
+// duration; a DO_FIRST item without open todos becomes a single reading
+// VTODO. With working hours, the same entries are also laid out back to back
+// as VEVENT time blocks, DO_FIRST first and then by rank, starting at the
+// next free slot. Every entry links to the item's URL.
+func RenderCalendar(items []model.Item, openTodos map[string][]model.Todo, opts CalendarOptions) []byte {
+	entries := calendarEntries(items, openTodos, opts.Now)
+
+	var b bytes.Buffer
+	writeICS(&b, "BEGIN", "VCALENDAR")
+	writeICS(&b, "VERSION", "2.0")
+	writeICS(&b, "PRODID", "-//readdo//calendar//EN")
+	writeICS(&b, "CALSCALE", "GREGORIAN")
+	writeICS(&b, "METHOD", "PUBLISH")
+	writeICS(&b, "X-WR-CALNAME", "readdo")
+
+	for _, e := range entries {
+		writeICS(&b, "BEGIN", "VTODO")
+		writeICS(&b, "UID", e.uid)
+		writeICS(&b, "DTSTAMP", e.stamp.UTC().Format(icsDateTime))
+		writeICS(&b, "SUMMARY", icsText(e.summary))
+		writeEntryLinks(&b, e)
+		writeICS(&b, "PRIORITY", fmt.Sprint(e.priority))
+		writeICS(&b, "STATUS", "NEEDS-ACTION")
+		writeICS(&b, "ESTIMATED-DURATION", icsDuration(e.effort))
+		if e.due != nil {
+			if due, err := time.Parse(time.DateOnly, *e.due); err == nil {
+				writeICS(&b, "DUE;VALUE=DATE", due.Format(icsDate))
+			}
+		}
+		writeICS(&b, "END", "VTODO")
+	}
+
+	if opts.Hours != nil {
+		cursor := opts.Now
+		for _, e := range entries {
+			start, end := opts.Hours.next(cursor, e.effort)
+			cursor = end
+			writeICS(&b, "BEGIN", "VEVENT")
+			writeICS(&b, "UID", "block-"+e.uid)
+			writeICS(&b, "DTSTAMP", e.stamp.UTC().Format(icsDateTime))
+			writeICS(&b, "DTSTART", start.UTC().Format(icsDateTime))
+			writeICS(&b, "DTEND", end.UTC().Format(icsDateTime))
+			writeICS(&b, "SUMMARY", icsText(e.summary))
+			writeEntryLinks(&b, e)
+			writeICS(&b, "RELATED-TO", e.uid)
+			writeICS(&b, "TRANSP", "OPAQUE")
+			writeICS(&b, "END", "VEVENT")
+		}
+	}
+
+	writeICS(&b, "END", "VCALENDAR")
+	return b.Bytes()
+}
+
+// calendarEntries collects the entries of the exported items in scheduling
+// order: DO_FIRST before PLAN_IT, then by rank, then by todo position.
+func calendarEntries(items []model.Item, openTodos map[string][]model.Todo, now time.Time) []calendarEntry {
+	type rankedItem struct {
+		item     model.Item
+		priority int
+		rank     float64
+	}
+	var ranked []rankedItem
+	for _, it := range items {
+		if it.Priority == nil {
+			continue
+		}
+		p, ok := calendarPriorities[*it.Priority]
+		if !ok {
+			continue
+		}
+		ranked = append(ranked, rankedItem{it, p, model.RankScore(&it, model.ItemEffort(openTodos[it.ID]), now)})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].priority != ranked[j].priority {
+			return ranked[i].priority < ranked[j].priority
+		}
+		return ranked[i].rank > ranked[j].rank
+	})
+
+	var entries []calendarEntry
+	for _, r := range ranked {
+		todos := openTodos[r.item.ID]
+		if len(todos) == 0 {
+			if *r.item.Priority != model.PriorityDoFirst {
+				continue
+			}
+			entries = append(entries, calendarEntry{
+				uid:      "item-" + r.item.ID + "@readdo",
+				summary:  "Read: " + itemTitle(r.item),
+				item:     r.item,
+				priority: r.priority,
+				effort:   model.DefaultEffort,
+				stamp:    parseStamp(r.item.UpdatedAt, now),
+			})
+			continue
+		}
+		for _, t := range todos {
+			entries = append(entries, calendarEntry{
+				uid:      "todo-" + t.ID + "@readdo",
+				summary:  t.Title,
+				item:     r.item,
+				priority: r.priority,
+				effort:   model.TodoEffort(t),
+				due:      t.DueDate,
+				stamp:    parseStamp(t.UpdatedAt, now),
+			})
+		}
+	}
+	return entries
+}
+
+// next returns the first block of length d starting at or after cursor that
+// fits inside working hours. A block longer than the whole window is cut to
+// the window.
+func (h *WorkingHours) next(cursor time.Time, d time.Duration) (time.Time, time.Time) {
+	loc := h.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	cursor = cursor.In(loc)
+	if rem := cursor.Sub(cursor.Truncate(blockGranularity)); rem > 0 {
+		cursor = cursor.Add(blockGranularity - rem)
+	}
+	if window := h.End - h.Start; d > window {
+		d = window
+	}
+	for {
+		y, m, day := cursor.Date()
+		dayStart := time.Date(y, m, day, 0, 0, 0, 0, loc).Add(h.Start)
+		dayEnd := time.Date(y, m, day, 0, 0, 0, 0, loc).Add(h.End)
+		weekend := cursor.Weekday() == time.Saturday || cursor.Weekday() == time.Sunday
+		if !weekend || h.Weekends {
+			if cursor.Before(dayStart) {
+				cursor = dayStart
+			}
+			if !cursor.Add(d).After(dayEnd) {
+				return cursor, cursor.Add(d)
+			}
+		}
+		cursor = time.Date(y, m, day+1, 0, 0, 0, 0, loc)
+	}
+}
+
+func writeEntryLinks(b *bytes.Buffer, e calendarEntry) {
+	writeICS(b, "DESCRIPTION", icsText(itemTitle(e.item)+"\n"+e.item.URL))
+	writeICS(b, "URL", e.item.URL)
+}
+
+func itemTitle(it model.Item) string {
+	if it.Title != "" {
+		return it.Title
+	}
+	return it.URL
+}
+
+func parseStamp(ts string, fallback time.Time) time.Time {
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		return t
+	}
+	return fallback
+}
+
+// writeICS writes a content line, folded to the line limit with CRLF
+// followed by a space, never splitting a UTF-8 sequence.
+func writeICS(b *bytes.Buffer, name, value string) {
+	line := name + ":" + value
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsLineLimit - 1 // the leading space counts
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool { return c&0xC0 != 0x80 }
+
+// icsText escapes a TEXT value (RFC 5545 §3.3.11).
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func icsText(s string) string { return icsTextEscaper.Replace(s) }
+
+// icsDuration formats d as an iCalendar DURATION, e.g. PT1H30M.
+func icsDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h > 0 && m > 0:
+		return fmt.Sprintf("PT%dH%dM", h, m)
+	case h > 0:
+		return fmt.Sprintf("PT%dH", h)
+	default:
+		return fmt.Sprintf("PT%dM", m)
+	}
+}
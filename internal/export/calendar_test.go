@@ -0,0 +1,150 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func calendarItem(id, priority string) model.Item {
+	score := 80.0
+	return model.Item{
+		ID: id, URL: "https://example.com/" + id, Title: "Item " + id, Status: model.StatusReady,
+		Priority: &priority, MatchScore: &score, SaveCount: 1,
+		CreatedAt: "2026-06-01T08:00:00Z", UpdatedAt: "2026-06-01T08:00:00Z",
+	}
+}
+
+func calendarTodo(id, itemID, title, eta string) model.Todo {
+	return model.Todo{ID: id, ItemID: itemID, Title: title, ETA: eta, UpdatedAt: "2026-06-01T09:00:00Z"}
+}
+
+// unfold reverses line folding so assertions can match whole properties.
+func unfold(ics []byte) string {
+	return strings.ReplaceAll(string(ics), "\r\n ", "")
+}
+
+func TestRenderCalendar_Todos(t *testing.T) {
+	due := "2026-06-05"
+	items := []model.Item{
+		calendarItem("plan", model.PriorityPlanIt),
+		calendarItem("first", model.PriorityDoFirst),
+		calendarItem("skim", model.PrioritySkimIt),
+		calendarItem("reading", model.PriorityDoFirst),
+		calendarItem("empty-plan", model.PriorityPlanIt),
+	}
+	write := calendarTodo("t-2", "first", "Write, then; ship", "1h30m")
+	write.DueDate = &due
+	todos := map[string][]model.Todo{
+		"plan":  {calendarTodo("t-3", "plan", "Plan it", "20m")},
+		"first": {calendarTodo("t-1", "first", "Read", "10m"), write},
+		"skim":  {calendarTodo("t-4", "skim", "Skim", "10m")},
+	}
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	out := unfold(RenderCalendar(items, todos, CalendarOptions{Now: now}))
+
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("not a calendar:\n%s", out)
+	}
+	if strings.Contains(out, "VEVENT") {
+		t.Error("events rendered without working hours")
+	}
+	for _, want := range []string{
+		"UID:todo-t-2@readdo\r\nDTSTAMP:20260601T090000Z\r\nSUMMARY:Write\\, then\\; ship\r\n",
+		"DESCRIPTION:Item first\\nhttps://example.com/first\r\nURL:https://example.com/first\r\nPRIORITY:1\r\n",
+		"ESTIMATED-DURATION:PT1H30M\r\nDUE;VALUE=DATE:20260605\r\n",
+		"UID:item-reading@readdo\r\n",
+		"SUMMARY:Read: Item reading\r\n",
+		"UID:todo-t-3@readdo",
+		"PRIORITY:5\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"t-4", "item-empty-plan", "item-first"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("calendar contains %q", unwanted)
+		}
+	}
+	// DO_FIRST todos come before PLAN_IT ones.
+	if strings.Index(out, "todo-t-3") < strings.Index(out, "todo-t-2") {
+		t.Error("PLAN_IT todo listed before DO_FIRST todos")
+	}
+}
+
+func TestRenderCalendar_TimeBlocks(t *testing.T) {
+	items := []model.Item{calendarItem("a", model.PriorityDoFirst)}
+	todos := map[string][]model.Todo{"a": {
+		calendarTodo("t-1", "a", "First", "1h"),
+		calendarTodo("t-2", "a", "Second", "1h"),
+		calendarTodo("t-3", "a", "Too long", "3h+"),
+	}}
+	// Friday 15:10 with a 09:00-17:00 window: the first block starts at the
+	// next quarter hour, the second no longer fits and moves to Monday, and
+	// the third follows it.
+	now := time.Date(2026, 6, 5, 15, 10, 0, 0, time.UTC)
+	hours := &WorkingHours{Start: 9 * time.Hour, End: 17 * time.Hour, Location: time.UTC}
+	out := unfold(RenderCalendar(items, todos, CalendarOptions{Now: now, Hours: hours}))
+
+	for _, want := range []string{
+		"UID:block-todo-t-1@readdo\r\nDTSTAMP:20260601T090000Z\r\nDTSTART:20260605T151500Z\r\nDTEND:20260605T161500Z\r\n",
+		"UID:block-todo-t-2@readdo\r\nDTSTAMP:20260601T090000Z\r\nDTSTART:20260608T090000Z\r\nDTEND:20260608T100000Z\r\n",
+		"UID:block-todo-t-3@readdo\r\nDTSTAMP:20260601T090000Z\r\nDTSTART:20260608T100000Z\r\nDTEND:20260608T130000Z\r\n",
+		"RELATED-TO:todo-t-1@readdo\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar missing %q:\n%s", want, out)
+		}
+	}
+
+	// With weekends and a 09:00-11:00 window, the blocks start on Saturday
+	// and the long todo is cut to Sunday's window.
+	short := &WorkingHours{Start: 9 * time.Hour, End: 11 * time.Hour, Location: time.UTC, Weekends: true}
+	out = unfold(RenderCalendar(items, todos, CalendarOptions{Now: now, Hours: short}))
+	for _, want := range []string{
+		"UID:block-todo-t-1@readdo\r\nDTSTAMP:20260601T090000Z\r\nDTSTART:20260606T090000Z\r\nDTEND:20260606T100000Z\r\n",
+		"UID:block-todo-t-3@readdo\r\nDTSTAMP:20260601T090000Z\r\nDTSTART:20260607T090000Z\r\nDTEND:20260607T110000Z\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestParseWorkingHours(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end time.Duration
+		wantErr    bool
+	}{
+		{"09:00-17:30", 9 * time.Hour, 17*time.Hour + 30*time.Minute, false},
+		{" 8:00 - 12:00 ", 8 * time.Hour, 12 * time.Hour, false},
+		{"17:00-09:00", 0, 0, true},
+		{"9-5", 0, 0, true},
+		{"09:00", 0, 0, true},
+	}
+	for _, tt := range tests {
+		start, end, err := ParseWorkingHours(tt.in)
+		if (err != nil) != tt.wantErr || start != tt.start || end != tt.end {
+			t.Errorf("ParseWorkingHours(%q) = %v, %v, %v; want %v, %v, err %v", tt.in, start, end, err, tt.start, tt.end, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteICS_Folds(t *testing.T) {
+	title := strings.Repeat("读", 40) // 3 bytes each
+	out := RenderCalendar([]model.Item{calendarItem("a", model.PriorityDoFirst)},
+		map[string][]model.Todo{"a": {calendarTodo("t-1", "a", title, "10m")}}, CalendarOptions{Now: time.Now()})
+	for _, line := range strings.Split(string(out), "\r\n") {
+		if len(line) > icsLineLimit || !utf8.ValidString(line) {
+			t.Errorf("bad folded line of %d octets: %q", len(line), line)
+		}
+	}
+	if !strings.Contains(unfold(out), "SUMMARY:"+title+"\r\n") {
+		t.Error("folded summary does not unfold to the title")
+	}
+}
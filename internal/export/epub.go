@@ -0,0 +1,333 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// epubPriorities orders the book: most important items first, items
+// without a priority last.
+var epubPriorities = map[string]int{
+	model.PriorityDoFirst: 0,
+	model.PriorityPlanIt:  1,
+	model.PrioritySkimIt:  2,
+	model.PriorityLetGo:   3,
+}
+
+// epubUnprioritized is the TOC group of items that have not been scored.
+const epubUnprioritized = "Unprioritized"
+
+// extraction mirrors the payload of the extraction artifact.
+type extraction struct {
+	NormalizedText string `json:"normalized_text"`
+	Meta           struct {
+		Author      string `json:"author"`
+		PublishDate string `json:"publish_date"`
+		Language    string `json:"language"`
+	} `json:"content_meta"`
+}
+
+// epubChapter is one item of the book.
+type epubChapter struct {
+	file     string
+	item     *model.ItemWithArtifacts
+	group    string
+	syn      synthesis
+	ext      extraction
+	priority int
+}
+
+// RenderEPUB writes items as an EPUB 3 book with one chapter per item,
+// ordered by priority and then match score. Each chapter opens with a
+// preface of the user's intents and the synthesis, followed by the stored
+// extracted text. A navigation document and an NCX (for EPUB 2 readers)
+// list the chapters grouped by priority. The book identifier is derived
+// from the item IDs, so re-exporting the same batch updates the same book.
+func RenderEPUB(w io.Writer, title string, items []model.ItemWithArtifacts, now time.Time) error {
+	chapters := epubChapters(items)
+	ids := make([]string, len(chapters))
+	for i, c := range chapters {
+		ids[i] = c.item.ID
+	}
+	bookID := "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte("readdo:epub:"+strings.Join(ids, ","))).String()
+	lang := "en"
+	for _, c := range chapters {
+		if c.ext.Meta.Language != "" {
+			lang = c.ext.Meta.Language
+			break
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype entry must come first, uncompressed and without a data
+	// descriptor, so that the file can be identified by its first bytes.
+	mimetype := []byte("application/epub+zip")
+	mw, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(mimetype),
+		CompressedSize64:   uint64(len(mimetype)),
+		UncompressedSize64: uint64(len(mimetype)),
+	})
+	if err != nil {
+		return fmt.Errorf("write mimetype: %w", err)
+	}
+	if _, err := mw.Write(mimetype); err != nil {
+		return fmt.Errorf("write mimetype: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"META-INF/container.xml", []byte(epubContainer)},
+		{"OEBPS/content.opf", epubPackage(bookID, title, lang, chapters, now)},
+		{"OEBPS/nav.xhtml", epubNav(title, lang, chapters)},
+		{"OEBPS/toc.ncx", epubNCX(bookID, title, chapters)},
+		{"OEBPS/style.css", []byte(epubStyle)},
+	}
+	for _, c := range chapters {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"OEBPS/" + c.file, epubChapterXHTML(c, lang)})
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return fmt.Errorf("write %s: %w", f.name, err)
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return fmt.Errorf("write %s: %w", f.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// epubChapters sorts the items into book order and decodes their artifacts.
+func epubChapters(items []model.ItemWithArtifacts) []epubChapter {
+	chapters := make([]epubChapter, 0, len(items))
+	for i := range items {
+		c := epubChapter{item: &items[i], group: epubUnprioritized, priority: len(epubPriorities)}
+		if p := items[i].Priority; p != nil {
+			if rank, ok := epubPriorities[*p]; ok {
+				c.group, c.priority = *p, rank
+			}
+		}
+		for _, a := range items[i].Artifacts {
+			switch a.ArtifactType {
+			case model.ArtifactSynthesis:
+				json.Unmarshal([]byte(a.Payload), &c.syn)
+			case model.ArtifactExtraction:
+				json.Unmarshal([]byte(a.Payload), &c.ext)
+			}
+		}
+		chapters = append(chapters, c)
+	}
+	score := func(c epubChapter) float64 {
+		if c.item.MatchScore == nil {
+			return 0
+		}
+		return *c.item.MatchScore
+	}
+	sort.SliceStable(chapters, func(i, j int) bool {
+		if chapters[i].priority != chapters[j].priority {
+			return chapters[i].priority < chapters[j].priority
+		}
+		return score(chapters[i]) > score(chapters[j])
+	})
+	for i := range chapters {
+		chapters[i].file = fmt.Sprintf("chapter-%03d.xhtml", i+1)
+	}
+	return chapters
+}
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const epubStyle = `body { font-family: serif; line-height: 1.5; }
+h1 { font-size: 1.4em; }
+.source { font-size: 0.9em; color: #555; }
+.preface { border-left: 3px solid #999; padding-left: 0.8em; margin: 1em 0 2em; }
+`
+
+func epubPackage(bookID, title, lang string, chapters []epubChapter, now time.Time) []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+`)
+	fmt.Fprintf(&b, "    <dc:identifier id=\"book-id\">%s</dc:identifier>\n", xmlText(bookID))
+	fmt.Fprintf(&b, "    <dc:title>%s</dc:title>\n", xmlText(title))
+	fmt.Fprintf(&b, "    <dc:language>%s</dc:language>\n", xmlText(lang))
+	b.WriteString("    <dc:creator>readdo</dc:creator>\n")
+	fmt.Fprintf(&b, "    <meta property=\"dcterms:modified\">%s</meta>\n", now.UTC().Format("2006-01-02T15:04:05Z"))
+	b.WriteString(`  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+`)
+	for i, c := range chapters {
+		fmt.Fprintf(&b, "    <item id=\"ch%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, c.file)
+	}
+	b.WriteString("  </manifest>\n  <spine toc=\"ncx\">\n    <itemref idref=\"nav\"/>\n")
+	for i := range chapters {
+		fmt.Fprintf(&b, "    <itemref idref=\"ch%d\"/>\n", i+1)
+	}
+	b.WriteString("  </spine>\n</package>\n")
+	return b.Bytes()
+}
+
+// epubNav renders the table of contents, one section per priority.
+func epubNav(title, lang string, chapters []epubChapter) []byte {
+	var b bytes.Buffer
+	writeXHTMLHead(&b, "Contents", lang)
+	fmt.Fprintf(&b, "<nav epub:type=\"toc\" id=\"toc\">\n<h1>%s</h1>\n<ol>\n", xmlText(title))
+	for i := 0; i < len(chapters); {
+		group := chapters[i].group
+		fmt.Fprintf(&b, "<li><span>%s</span>\n<ol>\n", xmlText(group))
+		for ; i < len(chapters) && chapters[i].group == group; i++ {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", chapters[i].file, xmlText(itemTitle(chapters[i].item.Item)))
+		}
+		b.WriteString("</ol>\n</li>\n")
+	}
+	b.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return b.Bytes()
+}
+
+func epubNCX(bookID, title string, chapters []epubChapter) []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+<head>
+`)
+	fmt.Fprintf(&b, "<meta name=\"dtb:uid\" content=\"%s\"/>\n</head>\n", xmlText(bookID))
+	fmt.Fprintf(&b, "<docTitle><text>%s</text></docTitle>\n<navMap>\n", xmlText(title))
+	for i, c := range chapters {
+		fmt.Fprintf(&b, "<navPoint id=\"np%d\" playOrder=\"%d\"><navLabel><text>%s</text></navLabel><content src=\"%s\"/></navPoint>\n",
+			i+1, i+1, xmlText(itemTitle(c.item.Item)), c.file)
+	}
+	b.WriteString("</navMap>\n</ncx>\n")
+	return b.Bytes()
+}
+
+// epubChapterXHTML renders one item: title and source, a preface with the
+// intents and synthesis, then the extracted text.
+func epubChapterXHTML(c epubChapter, lang string) []byte {
+	item := c.item
+	title := itemTitle(item.Item)
+
+	var b bytes.Buffer
+	writeXHTMLHead(&b, title, lang)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", xmlText(title))
+
+	source := []string{fmt.Sprintf("<a href=\"%s\">%s</a>", xmlText(item.URL), xmlText(orDefault(item.Domain, item.URL)))}
+	if c.ext.Meta.Author != "" {
+		source = append(source, xmlText(c.ext.Meta.Author))
+	}
+	if c.ext.Meta.PublishDate != "" {
+		source = append(source, xmlText(c.ext.Meta.PublishDate))
+	}
+	if c.group != epubUnprioritized {
+		source = append(source, xmlText(c.group))
+	}
+	fmt.Fprintf(&b, "<p class=\"source\">%s</p>\n", strings.Join(source, " · "))
+
+	intents := make([]string, 0, len(item.Intents))
+	for _, in := range item.Intents {
+		intents = append(intents, in.Text)
+	}
+	if len(intents) == 0 && item.IntentText != "" {
+		intents = append(intents, item.IntentText)
+	}
+	if len(intents) > 0 || c.syn.Insight != "" || len(c.syn.Points) > 0 {
+		b.WriteString("<section class=\"preface\">\n")
+		if len(intents) > 0 {
+			b.WriteString("<h2>Why I saved it</h2>\n")
+			for _, in := range intents {
+				fmt.Fprintf(&b, "<p>%s</p>\n", xmlText(in))
+			}
+		}
+		if insight := strings.TrimSpace(c.syn.Insight); insight != "" {
+			fmt.Fprintf(&b, "<h2>Insight</h2>\n<p>%s</p>\n", xmlText(insight))
+		}
+		if len(c.syn.Points) > 0 {
+			b.WriteString("<h2>Points</h2>\n<ul>\n")
+			for _, p := range c.syn.Points {
+				fmt.Fprintf(&b, "<li>%s</li>\n", xmlText(p))
+			}
+			b.WriteString("</ul>\n")
+		}
+		b.WriteString("</section>\n")
+	}
+
+	paragraphs := textParagraphs(c.ext.NormalizedText)
+	if len(paragraphs) == 0 {
+		fmt.Fprintf(&b, "<p><em>No extracted text is stored for this item. Read it at <a href=\"%s\">%s</a>.</em></p>\n", xmlText(item.URL), xmlText(item.URL))
+	}
+	for _, p := range paragraphs {
+		fmt.Fprintf(&b, "<p>%s</p>\n", xmlText(p))
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.Bytes()
+}
+
+func writeXHTMLHead(b *bytes.Buffer, title, lang string) {
+	fmt.Fprintf(b, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%[2]s" lang="%[2]s">
+<head>
+<title>%[1]s</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+`, xmlText(title), xmlText(lang))
+}
+
+// textParagraphs splits extracted text into paragraphs at line breaks,
+// dropping blank lines.
+func textParagraphs(text string) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// xmlText escapes s for XML text and attributes, dropping characters XML
+// does not allow, such as most control characters found in scraped text.
+func xmlText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r != 0xFFFE && r != 0xFFFF) {
+			return r
+		}
+		return -1
+	}, s)
+	return html.EscapeString(s)
+}
+
+func orDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
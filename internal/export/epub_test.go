@@ -0,0 +1,119 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func readZip(t *testing.T, data []byte) (*zip.Reader, map[string]string) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	return zr, files
+}
+
+func TestRenderEPUB(t *testing.T) {
+	plan := model.ItemWithArtifacts{Item: calendarItem("plan", model.PriorityPlanIt)}
+	first := model.ItemWithArtifacts{
+		Item:    calendarItem("first", model.PriorityDoFirst),
+		Intents: []model.Intent{{Text: "learn <generics>"}},
+		Artifacts: []model.Artifact{
+			{ArtifactType: model.ArtifactSynthesis, Payload: `{"points":["p & q"],"insight":"big idea"}`},
+			{ArtifactType: model.ArtifactExtraction, Payload: `{"normalized_text":"Para one.\n\nPara\u0007 two.","content_meta":{"author":"Ann","language":"de"}}`},
+		},
+	}
+	first.Domain = "example.com"
+	unscored := model.ItemWithArtifacts{Item: model.Item{ID: "new", URL: "https://example.com/new"}}
+
+	var buf bytes.Buffer
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	if err := RenderEPUB(&buf, "Weekend reading", []model.ItemWithArtifacts{unscored, plan, first}, now); err != nil {
+		t.Fatalf("RenderEPUB: %v", err)
+	}
+	zr, files := readZip(t, buf.Bytes())
+
+	// OCF: an uncompressed mimetype entry first.
+	if mt := zr.File[0]; mt.Name != "mimetype" || mt.Method != zip.Store || files["mimetype"] != "application/epub+zip" {
+		t.Errorf("first entry = %s (method %d)", mt.Name, mt.Method)
+	}
+	if !bytes.HasPrefix(buf.Bytes()[30:], []byte("mimetypeapplication/epub+zip")) {
+		t.Error("mimetype is not readable at a fixed offset")
+	}
+
+	// Every XML document is well-formed.
+	for name, content := range files {
+		if !strings.HasSuffix(name, ".xhtml") && !strings.HasSuffix(name, ".opf") && !strings.HasSuffix(name, ".ncx") && !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		d := xml.NewDecoder(strings.NewReader(content))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s is not well-formed: %v\n%s", name, err, content)
+				break
+			}
+		}
+	}
+
+	// Chapters follow priority: DO_FIRST, PLAN_IT, then unscored.
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{"<dc:title>Weekend reading</dc:title>", "<dc:language>de</dc:language>", "2026-06-01T10:00:00Z", `properties="nav"`} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("package missing %q:\n%s", want, opf)
+		}
+	}
+	for file, title := range map[string]string{"chapter-001.xhtml": "Item first", "chapter-002.xhtml": "Item plan", "chapter-003.xhtml": "https://example.com/new"} {
+		if !strings.Contains(files["OEBPS/"+file], "<h1>"+title+"</h1>") {
+			t.Errorf("%s is not %q", file, title)
+		}
+	}
+	nav := files["OEBPS/nav.xhtml"]
+	if i, j, k := strings.Index(nav, "DO_FIRST"), strings.Index(nav, "PLAN_IT"), strings.Index(nav, epubUnprioritized); i < 0 || i > j || j > k {
+		t.Errorf("TOC not grouped by priority:\n%s", nav)
+	}
+
+	ch := files["OEBPS/chapter-001.xhtml"]
+	for _, want := range []string{
+		"learn &lt;generics&gt;", "<p>big idea</p>", "<li>p &amp; q</li>", "Ann",
+		"<p>Para one.</p>\n<p>Para two.</p>",
+	} {
+		if !strings.Contains(ch, want) {
+			t.Errorf("chapter missing %q:\n%s", want, ch)
+		}
+	}
+	if strings.Index(ch, "big idea") > strings.Index(ch, "Para one.") {
+		t.Error("synthesis preface does not come before the text")
+	}
+	if !strings.Contains(files["OEBPS/chapter-002.xhtml"], "No extracted text") {
+		t.Error("chapter without extraction has no note")
+	}
+
+	// The same batch always gets the same book identifier.
+	var again bytes.Buffer
+	RenderEPUB(&again, "Other title", []model.ItemWithArtifacts{unscored, plan, first}, now.Add(time.Hour))
+	_, files2 := readZip(t, again.Bytes())
+	id := func(opf string) string { return opf[strings.Index(opf, "urn:uuid:"):][:45] }
+	if id(opf) != id(files2["OEBPS/content.opf"]) {
+		t.Error("book identifier changed between exports of the same items")
+	}
+}
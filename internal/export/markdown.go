@@ -0,0 +1,211 @@
+// Package export writes items out of readdo in formats other tools can read.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// maxSlugLength bounds the title part of an exported filename, in runes.
+const maxSlugLength = 60
+
+// todoLine matches a task list line ending in a block ID, as written by
+// RenderMarkdown. Obsidian and most editors write "x" for a checked box, some
+// "X"; both count as done.
+var todoLine = regexp.MustCompile(`^\s*[-*+] \[([ xX])\] .*\^([A-Za-z0-9-]+)\s*$`)
+
+// synthesis and score mirror the payloads of the synthesis and score
+// artifacts written by the pipeline.
+type synthesis struct {
+	Points  []string `json:"points"`
+	Insight string   `json:"insight"`
+}
+
+type score struct {
+	IntentScore  *float64 `json:"intent_score"`
+	QualityScore *float64 `json:"quality_score"`
+}
+
+// RenderMarkdown renders an item as a Markdown note: YAML front matter with
+// its metadata, followed by the synthesis points and insight and the todos as
+// task list checkboxes. Each todo line ends with a block ID (" ^<todo id>")
+// so that it can be matched back to its todo. The output only depends on the
+// item, so unchanged items render byte-for-byte identically.
+func RenderMarkdown(item *model.ItemWithArtifacts) []byte {
+	var syn synthesis
+	var sc score
+	for _, a := range item.Artifacts {
+		switch a.ArtifactType {
+		case model.ArtifactSynthesis:
+			json.Unmarshal([]byte(a.Payload), &syn)
+		case model.ArtifactScore:
+			json.Unmarshal([]byte(a.Payload), &sc)
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	writeYAML(&b, "readdo_id", yamlString(item.ID))
+	writeYAML(&b, "title", yamlString(item.Title))
+	writeYAML(&b, "url", yamlString(item.URL))
+	writeYAML(&b, "domain", yamlString(item.Domain))
+	writeYAML(&b, "status", item.Status)
+	if item.Priority != nil {
+		writeYAML(&b, "priority", *item.Priority)
+	}
+	if item.MatchScore != nil {
+		writeYAML(&b, "match_score", yamlNumber(*item.MatchScore))
+	}
+	if sc.IntentScore != nil {
+		writeYAML(&b, "intent_score", yamlNumber(*sc.IntentScore))
+	}
+	if sc.QualityScore != nil {
+		writeYAML(&b, "quality_score", yamlNumber(*sc.QualityScore))
+	}
+	writeYAMLList(&b, "tags", item.Tags)
+	intents := make([]string, 0, len(item.Intents))
+	for _, in := range item.Intents {
+		intents = append(intents, in.Text)
+	}
+	if len(intents) == 0 && item.IntentText != "" {
+		intents = append(intents, item.IntentText)
+	}
+	writeYAMLList(&b, "intents", intents)
+	writeYAML(&b, "created_at", yamlString(item.CreatedAt))
+	writeYAML(&b, "updated_at", yamlString(item.UpdatedAt))
+	b.WriteString("---\n\n")
+
+	title := item.Title
+	if title == "" {
+		title = item.URL
+	}
+	fmt.Fprintf(&b, "# %s\n\n", oneLine(title))
+	fmt.Fprintf(&b, "Source: [%s](%s)\n", oneLine(item.Domain), item.URL)
+
+	if len(syn.Points) > 0 {
+		b.WriteString("\n## Points\n\n")
+		for _, p := range syn.Points {
+			fmt.Fprintf(&b, "- %s\n", oneLine(p))
+		}
+	}
+	if syn.Insight != "" {
+		fmt.Fprintf(&b, "\n## Insight\n\n%s\n", strings.TrimSpace(syn.Insight))
+	}
+	if len(item.Todos) > 0 {
+		b.WriteString("\n## Todos\n\n")
+		for _, t := range item.Todos {
+			box := " "
+			if t.Done {
+				box = "x"
+			}
+			line := oneLine(t.Title)
+			if t.ETA != "" {
+				line += " (" + oneLine(t.ETA) + ")"
+			}
+			if t.DueDate != nil {
+				line += " 📅 " + *t.DueDate
+			}
+			fmt.Fprintf(&b, "- [%s] %s ^%s\n", box, line, t.ID)
+		}
+	}
+	return b.Bytes()
+}
+
+// ParseTodoStates returns the done state of every todo checkbox in a note,
+// keyed by the todo ID in its block ID. Lines without a block ID are not
+// readdo todos and are ignored.
+func ParseTodoStates(data []byte) map[string]bool {
+	states := map[string]bool{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if m := todoLine.FindStringSubmatch(sc.Text()); m != nil {
+			states[m[2]] = m[1] != " "
+		}
+	}
+	return states
+}
+
+// Filename returns the filename a new note for item is written to: a slug of
+// the title followed by the first eight characters of the item ID, which
+// keeps names unique without making them unreadable.
+func Filename(item model.Item) string {
+	slug := slugify(item.Title)
+	if slug == "" {
+		slug = slugify(item.Domain)
+	}
+	if slug == "" {
+		slug = "item"
+	}
+	short := item.ID
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	return slug + "-" + short + ".md"
+}
+
+// slugify keeps letters and digits (including non-Latin scripts, which
+// Obsidian handles fine) and collapses everything else into single dashes.
+func slugify(s string) string {
+	var b strings.Builder
+	n := 0
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if n >= maxSlugLength {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			n++
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+func writeYAML(b *bytes.Buffer, key, value string) {
+	fmt.Fprintf(b, "%s: %s\n", key, value)
+}
+
+func writeYAMLList(b *bytes.Buffer, key string, values []string) {
+	if len(values) == 0 {
+		fmt.Fprintf(b, "%s: []\n", key)
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, v := range values {
+		fmt.Fprintf(b, "  - %s\n", yamlString(v))
+	}
+}
+
+// yamlString quotes s as a YAML double-quoted scalar. JSON strings are valid
+// YAML, so the JSON encoder does the escaping.
+func yamlString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func yamlNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// oneLine collapses whitespace, including newlines, so that a value cannot
+// break out of its Markdown line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func sampleItem() *model.ItemWithArtifacts {
+	priority := model.PriorityDoFirst
+	score := 82.5
+	due := "2026-05-01"
+	return &model.ItemWithArtifacts{
+		Item: model.Item{
+			ID: "0f8fad5b-d9cb-469f-a165-70867728950e", URL: "https://example.com/post", Title: "Go: \"Generics\"\nin practice",
+			Domain: "example.com", Status: model.StatusReady, Priority: &priority, MatchScore: &score,
+			CreatedAt: "2026-04-01T10:00:00Z", UpdatedAt: "2026-04-02T10:00:00Z",
+		},
+		Artifacts: []model.Artifact{
+			model.NewArtifact("a1", "i", model.ArtifactSynthesis, `{"points":["First point","Second\npoint"],"insight":"Use them sparingly."}`),
+			model.NewArtifact("a2", "i", model.ArtifactScore, `{"intent_score":90,"quality_score":75,"final_score":82.5,"priority":"DO_FIRST"}`),
+		},
+		Intents: []model.Intent{{Text: "learn generics"}},
+		Tags:    []string{"go"},
+		Todos: []model.Todo{
+			{ID: "t1", Title: "Read the spec", ETA: "20m"},
+			{ID: "t2", Title: "Write a demo", Done: true, DueDate: &due},
+		},
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	out := string(RenderMarkdown(sampleItem()))
+
+	for _, want := range []string{
+		"---\nreaddo_id: \"0f8fad5b-d9cb-469f-a165-70867728950e\"\n",
+		`title: "Go: \"Generics\"\nin practice"`,
+		"url: \"https://example.com/post\"\n",
+		"priority: DO_FIRST\n",
+		"match_score: 82.5\n",
+		"intent_score: 90\n",
+		"quality_score: 75\n",
+		"tags:\n  - \"go\"\n",
+		"intents:\n  - \"learn generics\"\n",
+		"# Go: \"Generics\" in practice\n",
+		"Source: [example.com](https://example.com/post)\n",
+		"## Points\n\n- First point\n- Second point\n",
+		"## Insight\n\nUse them sparingly.\n",
+		"## Todos\n\n- [ ] Read the spec (20m) ^t1\n- [x] Write a demo 📅 2026-05-01 ^t2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n---\n%s", want, out)
+		}
+	}
+
+	if again := string(RenderMarkdown(sampleItem())); again != out {
+		t.Error("rendering is not deterministic")
+	}
+}
+
+func TestRenderMarkdown_Minimal(t *testing.T) {
+	item := &model.ItemWithArtifacts{Item: model.Item{ID: "i", URL: "https://example.com", Status: model.StatusReady}}
+	out := string(RenderMarkdown(item))
+	if strings.Contains(out, "## Points") || strings.Contains(out, "## Todos") || strings.Contains(out, "priority:") {
+		t.Errorf("empty sections rendered:\n%s", out)
+	}
+	if !strings.Contains(out, "# https://example.com\n") {
+		t.Errorf("title should fall back to the URL:\n%s", out)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		item model.Item
+		want string
+	}{
+		{"title", model.Item{ID: "0f8fad5b-d9cb", Title: "Hello, World!  Go 1.25"}, "hello-world-go-1-25-0f8fad5b.md"},
+		{"cjk title", model.Item{ID: "abc", Title: "读书笔记：Go 并发"}, "读书笔记-go-并发-abc.md"},
+		{"domain fallback", model.Item{ID: "12345678", Domain: "example.com"}, "example-com-12345678.md"},
+		{"nothing", model.Item{ID: "12345678"}, "item-12345678.md"},
+		{"path characters", model.Item{ID: "12345678", Title: "../../etc/passwd"}, "etc-passwd-12345678.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Filename(tt.item); got != tt.want {
+				t.Errorf("Filename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrontMatterID(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"quoted", "---\nreaddo_id: \"abc\"\ntitle: \"x\"\n---\n# x", "abc"},
+		{"unquoted", "---\ntitle: x\nreaddo_id: abc\n---\n", "abc"},
+		{"no front matter", "# readdo_id: abc\n", ""},
+		{"after front matter", "---\ntitle: x\n---\nreaddo_id: abc\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frontMatterID([]byte(tt.data)); got != tt.want {
+				t.Errorf("frontMatterID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTodoStates(t *testing.T) {
+	// A rendered note parses back to its todos' states.
+	states := ParseTodoStates(RenderMarkdown(sampleItem()))
+	if len(states) != 2 || states["t1"] || !states["t2"] {
+		t.Errorf("rendered states = %v, want t1 open, t2 done", states)
+	}
+
+	note := strings.Join([]string{
+		"- [X] Checked by another editor ^t-3",
+		"  * [x] Indented with a trailing space ^t-4 ",
+		"- [ ] Reopened ^t-5",
+		"- [x] A checkbox the user added themselves",
+		"- [?] Not a checkbox ^t-6",
+		"Mentions ^t-7 but is not a task",
+	}, "\n")
+	states = ParseTodoStates([]byte(note))
+	want := map[string]bool{"t-3": true, "t-4": true, "t-5": false}
+	if len(states) != len(want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	for id, done := range want {
+		if got, ok := states[id]; !ok || got != done {
+			t.Errorf("states[%s] = %v, %v; want %v", id, got, ok, done)
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/events"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// Outcomes of exporting a single item.
+const (
+	OutcomeWritten   = "written"   // the note was created or its content changed
+	OutcomeUnchanged = "unchanged" // the note already had this content
+	OutcomeSkipped   = "skipped"   // the item is not READY and has no note yet, or is gone
+)
+
+// Vault file permissions: readable by the owner's group (e.g. a sync
+// daemon), never by others.
+const (
+	vaultDirPerm  = 0o750
+	vaultFilePerm = 0o640
+)
+
+// frontMatterPeek is how much of a note is read to find its readdo_id.
+const frontMatterPeek = 1024
+
+// Store loads items for export and applies the todo changes made in notes.
+type Store interface {
+	GetItem(ctx context.Context, id string) (*model.ItemWithArtifacts, error)
+	ListItems(ctx context.Context, f model.ItemFilter) ([]model.Item, error)
+	UpdateTodo(ctx context.Context, t model.Todo) error
+	UpdateItemStatus(ctx context.Context, id, newStatus string, errorInfo *string) error
+	RecordTodoSync(ctx context.Context, r model.TodoSync) error
+}
+
+// EventPublisher receives the events of changes synced from notes.
+type EventPublisher interface {
+	Publish(e model.Event)
+}
+
+// Summary counts the outcomes of a full export.
+type Summary struct {
+	Written   int `json:"written"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+}
+
+// Vault exports items as Markdown notes into an Obsidian-style vault
+// directory. New notes are created for READY items; once an item has a note,
+// the note keeps its filename (even if the user renamed it) and is kept up
+// to date whatever the item's status. Notes are never deleted.
+//
+// Sync is two-way for todos: before a note edited outside readdo is
+// rewritten, its checkboxes are reconciled with the database, last writer
+// wins (see model.NewTodoSync), and every disagreement is logged.
+type Vault struct {
+	dir    string
+	store  Store
+	events EventPublisher
+
+	mu    sync.Mutex
+	files map[string]string    // item ID → note filename; nil until indexed
+	notes map[string]noteState // item ID → note as last written or checked
+}
+
+// noteState identifies the content of a note readdo has written or already
+// reconciled, so that later edits by the user can be told apart.
+type noteState struct {
+	hash    [sha256.Size]byte
+	modTime time.Time
+}
+
+// NewVault creates a Vault writing to dir.
+func NewVault(dir string, store Store) *Vault {
+	return &Vault{dir: dir, store: store, notes: map[string]noteState{}}
+}
+
+// WithEvents makes the vault publish the item changes it applies from
+// edited notes to pub.
+func (v *Vault) WithEvents(pub EventPublisher) *Vault {
+	v.events = pub
+	return v
+}
+
+// ExportAll exports every READY item and refreshes every existing note.
+// A failing item does not stop the others; their errors are joined.
+func (v *Vault) ExportAll(ctx context.Context) (Summary, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var sum Summary
+	if err := v.index(); err != nil {
+		return sum, err
+	}
+	ready, err := v.store.ListItems(ctx, model.ItemFilter{Status: []string{model.StatusReady}})
+	if err != nil {
+		return sum, fmt.Errorf("list items: %w", err)
+	}
+
+	ids := make([]string, 0, len(ready)+len(v.files))
+	seen := make(map[string]bool, len(ready))
+	for _, it := range ready {
+		ids = append(ids, it.ID)
+		seen[it.ID] = true
+	}
+	for id := range v.files {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+
+	var errs []error
+	for _, id := range ids {
+		outcome, err := v.export(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("item %s: %w", id, err))
+			continue
+		}
+		switch outcome {
+		case OutcomeWritten:
+			sum.Written++
+		case OutcomeUnchanged:
+			sum.Unchanged++
+		default:
+			sum.Skipped++
+		}
+	}
+	return sum, errors.Join(errs...)
+}
+
+// ExportItem exports a single item and reports what happened.
+func (v *Vault) ExportItem(ctx context.Context, id string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.index(); err != nil {
+		return "", err
+	}
+	return v.export(ctx, id)
+}
+
+// Sync re-indexes the vault, picking up renamed notes, and reconciles every
+// note modified since it was last written or checked.
+func (v *Vault) Sync(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.files = nil
+	if err := v.index(); err != nil {
+		return err
+	}
+	var errs []error
+	for id, name := range v.files {
+		info, err := os.Stat(filepath.Join(v.dir, name))
+		if err != nil {
+			continue // removed since indexing; not recreated until the item changes
+		}
+		if state, ok := v.notes[id]; ok && info.ModTime().Equal(state.modTime) {
+			continue
+		}
+		if _, err := v.export(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("item %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Watch exports everything once, then re-exports items as their events
+// arrive on bus and syncs notes edited in the vault every interval. It
+// blocks until ctx is cancelled.
+func (v *Vault) Watch(ctx context.Context, bus *events.Bus, interval time.Duration) {
+	sum, err := v.ExportAll(ctx)
+	if err != nil {
+		slog.Error("vault export failed", "dir", v.dir, "error", err)
+	}
+	slog.Info("vault exported", "dir", v.dir, "written", sum.Written, "unchanged", sum.Unchanged)
+
+	consumed := make(chan struct{})
+	defer func() { <-consumed }()
+	go func() {
+		defer close(consumed)
+		// Events still queued at shutdown are exported too.
+		bus.Consume(ctx, "vault", func(e model.Event) { v.handleEvent(context.WithoutCancel(ctx), e) })
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := v.Sync(ctx); err != nil {
+				slog.Error("vault sync failed", "dir", v.dir, "error", err)
+			}
+		}
+	}
+}
+
+// handleEvent re-exports the item an event is about.
+func (v *Vault) handleEvent(ctx context.Context, e model.Event) {
+	if e.Type == model.EventStepStarted || e.Type == model.EventStepFinished {
+		return
+	}
+	outcome, err := v.ExportItem(ctx, e.ItemID)
+	if err != nil {
+		slog.Error("vault export failed", "item_id", e.ItemID, "error", err)
+		return
+	}
+	if outcome == OutcomeWritten {
+		slog.Info("vault note written", "item_id", e.ItemID)
+	}
+}
+
+// export writes one item's note if its content changed, first reconciling
+// the todos of a note that was edited since readdo last wrote it. Callers
+// hold v.mu and have indexed the vault.
+func (v *Vault) export(ctx context.Context, id string) (string, error) {
+	item, err := v.store.GetItem(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return OutcomeSkipped, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get item: %w", err)
+	}
+
+	name, ok := v.files[id]
+	if !ok {
+		if item.Status != model.StatusReady {
+			return OutcomeSkipped, nil
+		}
+		name = Filename(item.Item)
+	}
+	path := filepath.Join(v.dir, name)
+
+	existing, readErr := os.ReadFile(path)
+	if readErr == nil {
+		state, known := v.notes[id]
+		if !known || sha256.Sum256(existing) != state.hash {
+			info, err := os.Stat(path)
+			if err != nil {
+				return "", fmt.Errorf("stat note: %w", err)
+			}
+			changed, err := v.reconcile(ctx, item, name, existing, info.ModTime())
+			if err != nil {
+				return "", err
+			}
+			if changed {
+				if item, err = v.store.GetItem(ctx, id); err != nil {
+					return "", fmt.Errorf("reload item: %w", err)
+				}
+			}
+		}
+	}
+
+	content := RenderMarkdown(item)
+	outcome := OutcomeWritten
+	if readErr == nil && sha256.Sum256(existing) == sha256.Sum256(content) {
+		outcome = OutcomeUnchanged
+	} else if err := writeFileAtomic(path, content); err != nil {
+		return "", err
+	}
+	v.files[id] = name
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("stat note: %w", err)
+	}
+	v.notes[id] = noteState{hash: sha256.Sum256(content), modTime: info.ModTime()}
+	return outcome, nil
+}
+
+// reconcile compares the checkboxes of an edited note with the item's todos.
+// Each disagreement is resolved last writer wins and logged; when the note
+// wins, the todo is updated and the item moves to DONE (or back to READY)
+// as if the change had been made through the API. It reports whether any
+// todo changed.
+func (v *Vault) reconcile(ctx context.Context, item *model.ItemWithArtifacts, name string, data []byte, modTime time.Time) (bool, error) {
+	states := ParseTodoStates(data)
+	changed, reopened := false, false
+	for _, t := range item.Todos {
+		fileDone, ok := states[t.ID]
+		if !ok || fileDone == t.Done {
+			continue
+		}
+		rec := model.NewTodoSync(uuid.New().String(), t, name, fileDone, modTime)
+		if rec.Winner == model.SyncSideVault {
+			if err := t.Apply(model.TodoPatch{Done: &fileDone}); err != nil {
+				return changed, err
+			}
+			if err := v.store.UpdateTodo(ctx, t); err != nil {
+				return changed, fmt.Errorf("update todo %s: %w", t.ID, err)
+			}
+			changed = true
+			reopened = reopened || !fileDone
+		}
+		if err := v.store.RecordTodoSync(ctx, rec); err != nil {
+			return changed, fmt.Errorf("record todo sync: %w", err)
+		}
+		slog.Info("vault todo synced", "item_id", item.ID, "todo_id", t.ID, "done", fileDone, "winner", rec.Winner)
+	}
+	if !changed {
+		return false, nil
+	}
+	v.publish(model.NewEvent(model.EventItemUpdated, item.ID))
+	return true, v.syncCompletion(ctx, item.ID, reopened)
+}
+
+// syncCompletion moves the item to DONE when all its todos are done, or back
+// to READY when reopened reports that a todo of a DONE item was unticked.
+func (v *Vault) syncCompletion(ctx context.Context, id string, reopened bool) error {
+	item, err := v.store.GetItem(ctx, id)
+	if err != nil {
+		return fmt.Errorf("reload item: %w", err)
+	}
+	done := 0
+	for _, t := range item.Todos {
+		if t.Done {
+			done++
+		}
+	}
+	status, ok := item.CompletionStatus(len(item.Todos), done, reopened)
+	if !ok {
+		return nil
+	}
+	if err := v.store.UpdateItemStatus(ctx, id, status, nil); err != nil {
+		return fmt.Errorf("update item status: %w", err)
+	}
+	v.publish(model.StatusEvent(id, status))
+	return nil
+}
+
+func (v *Vault) publish(e model.Event) {
+	if v.events != nil {
+		v.events.Publish(e)
+	}
+}
+
+// index creates the vault directory if needed and maps existing notes to
+// their items by the readdo_id in their front matter. It runs once.
+func (v *Vault) index() error {
+	if v.files != nil {
+		return nil
+	}
+	if err := os.MkdirAll(v.dir, vaultDirPerm); err != nil {
+		return fmt.Errorf("create vault dir: %w", err)
+	}
+	entries, err := os.ReadDir(v.dir)
+	if err != nil {
+		return fmt.Errorf("read vault dir: %w", err)
+	}
+
+	files := map[string]string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		id, err := readNoteID(filepath.Join(v.dir, e.Name()))
+		if err != nil {
+			slog.Warn("skipping unreadable vault note", "file", e.Name(), "error", err)
+			continue
+		}
+		if id != "" {
+			files[id] = e.Name()
+		}
+	}
+	v.files = files
+	return nil
+}
+
+// readNoteID returns the readdo_id from a note's front matter, or "" if the
+// note was not written by readdo.
+func readNoteID(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head, err := io.ReadAll(io.LimitReader(f, frontMatterPeek))
+	if err != nil {
+		return "", err
+	}
+	return frontMatterID(head), nil
+}
+
+// frontMatterID extracts readdo_id from the front matter at the start of data.
+func frontMatterID(data []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	if !sc.Scan() || strings.TrimSpace(sc.Text()) != "---" {
+		return ""
+	}
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "---" {
+			break
+		}
+		value, ok := strings.CutPrefix(line, "readdo_id:")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		var id string
+		if err := json.Unmarshal([]byte(value), &id); err != nil {
+			return value // unquoted
+		}
+		return id
+	}
+	return ""
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so that readers never see a partially written note.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".readdo-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write note: %w", err)
+	}
+	if err := tmp.Chmod(vaultFilePerm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod note: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close note: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename note: %w", err)
+	}
+	return nil
+}
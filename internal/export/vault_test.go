@@ -0,0 +1,304 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/events"
+	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := store.New(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	return s
+}
+
+func createItem(t *testing.T, s *store.Store, id, title, status string) {
+	t.Helper()
+	now := time.Now().UTC().Format(time.RFC3339)
+	item := model.Item{ID: id, URL: "https://example.com/" + id, Title: title, Domain: "example.com",
+		SourceType: "web", Status: status, SaveCount: 1, CreatedAt: now, UpdatedAt: now}
+	if err := s.CreateItem(context.Background(), item); err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+}
+
+func addTodos(t *testing.T, s *store.Store, itemID string, titles ...string) {
+	t.Helper()
+	var todos []model.Todo
+	for i, title := range titles {
+		todos = append(todos, model.NewTodo(fmt.Sprintf("%s-t%d", itemID, i+1), itemID, title, "10m", model.TodoTypeRead, i))
+	}
+	if err := s.SyncGeneratedTodos(context.Background(), itemID, todos); err != nil {
+		t.Fatalf("SyncGeneratedTodos: %v", err)
+	}
+}
+
+// editNote rewrites a note as the user would, with the given modification time.
+func editNote(t *testing.T, path, old, new string, modTime time.Time) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), old) {
+		t.Fatalf("note has no %q:\n%s", old, data)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), old, new, 1)), vaultFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type recordingPublisher struct{ events []model.Event }
+
+func (p *recordingPublisher) Publish(e model.Event) { p.events = append(p.events, e) }
+
+func TestVault_ExportAll(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "vault")
+	createItem(t, s, "ready-1", "First note", model.StatusReady)
+	createItem(t, s, "captured-1", "Not yet", model.StatusCaptured)
+
+	v := NewVault(dir, s)
+	sum, err := v.ExportAll(ctx)
+	if err != nil {
+		t.Fatalf("ExportAll: %v", err)
+	}
+	if sum.Written != 1 || sum.Unchanged != 0 {
+		t.Errorf("first export = %+v, want 1 written", sum)
+	}
+	path := filepath.Join(dir, "first-note-ready-1.md")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("note not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != vaultFilePerm {
+		t.Errorf("note permissions = %o, want %o", perm, vaultFilePerm)
+	}
+
+	// Nothing changed: the file is left alone.
+	sum, _ = v.ExportAll(ctx)
+	if sum.Written != 0 || sum.Unchanged != 1 {
+		t.Errorf("second export = %+v, want 1 unchanged", sum)
+	}
+
+	// Items without a note are only exported once READY.
+	if outcome, _ := v.ExportItem(ctx, "captured-1"); outcome != OutcomeSkipped {
+		t.Errorf("captured item outcome = %s, want skipped", outcome)
+	}
+	if outcome, _ := v.ExportItem(ctx, "missing"); outcome != OutcomeSkipped {
+		t.Errorf("missing item outcome = %s, want skipped", outcome)
+	}
+}
+
+func TestVault_KeepsExistingNotesCurrent(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	createItem(t, s, "item-1", "Original title", model.StatusReady)
+
+	if _, err := NewVault(dir, s).ExportItem(ctx, "item-1"); err != nil {
+		t.Fatalf("ExportItem: %v", err)
+	}
+
+	// The user renames the note; a fresh vault (e.g. after a restart) finds
+	// it by its readdo_id and keeps writing there, also after archiving.
+	renamed := filepath.Join(dir, "My reading note.md")
+	if err := os.Rename(filepath.Join(dir, "original-title-item-1.md"), renamed); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateItemStatus(ctx, "item-1", model.StatusArchived, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	outcome, err := NewVault(dir, s).ExportItem(ctx, "item-1")
+	if err != nil || outcome != OutcomeWritten {
+		t.Fatalf("ExportItem = %s, %v; want written", outcome, err)
+	}
+	data, err := os.ReadFile(renamed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "status: ARCHIVED\n") {
+		t.Errorf("renamed note not updated:\n%s", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("vault has %d files, want 1", len(entries))
+	}
+}
+
+func TestVault_WatchExportsOnEvents(t *testing.T) {
+	s := newTestStore(t)
+	dir := t.TempDir()
+	bus := events.NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewVault(dir, s).Watch(ctx, bus, time.Hour)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	createItem(t, s, "item-1", "Fresh", model.StatusReady)
+	path := filepath.Join(dir, "fresh-item-1.md")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		// Keep publishing until the watcher has subscribed and caught one.
+		bus.Publish(model.StatusEvent("item-1", model.StatusReady))
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("note not written after event")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestVault_SyncAppliesCheckedTodos(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	createItem(t, s, "item-1", "Tasks", model.StatusReady)
+	addTodos(t, s, "item-1", "Read", "Write")
+
+	pub := &recordingPublisher{}
+	v := NewVault(dir, s).WithEvents(pub)
+	if _, err := v.ExportItem(ctx, "item-1"); err != nil {
+		t.Fatalf("ExportItem: %v", err)
+	}
+
+	// Checking both boxes in the note completes the todos and the item.
+	path := filepath.Join(dir, "tasks-item-1.md")
+	later := time.Now().Add(time.Minute)
+	editNote(t, path, "- [ ] Read (10m) ^item-1-t1", "- [x] Read (10m) ^item-1-t1", later)
+	editNote(t, path, "- [ ] Write (10m) ^item-1-t2", "- [x] Write (10m) ^item-1-t2", later)
+	if err := v.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	item, _ := s.GetItem(ctx, "item-1")
+	if item.Status != model.StatusDone {
+		t.Errorf("item status = %s, want DONE", item.Status)
+	}
+	for _, td := range item.Todos {
+		if !td.Done || td.CompletedAt == nil || !td.Edited {
+			t.Errorf("todo %s = %+v, want done and edited", td.ID, td)
+		}
+	}
+	syncs, _ := s.ListTodoSyncs(ctx, "item-1")
+	if len(syncs) != 2 || syncs[0].Winner != model.SyncSideVault || syncs[0].File != "tasks-item-1.md" {
+		t.Errorf("sync log = %+v, want 2 vault wins", syncs)
+	}
+	if len(pub.events) != 2 || pub.events[1].Status != model.StatusDone {
+		t.Errorf("events = %+v, want item.updated then DONE", pub.events)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "status: DONE\n") {
+		t.Errorf("note not refreshed after sync:\n%s", data)
+	}
+
+	// Once reconciled, an untouched note is not synced again.
+	if err := v.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if syncs, _ := s.ListTodoSyncs(ctx, ""); len(syncs) != 2 {
+		t.Errorf("sync log has %d entries after a no-op sync, want 2", len(syncs))
+	}
+}
+
+func TestVault_SyncConflictAPIWins(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	createItem(t, s, "item-1", "Tasks", model.StatusReady)
+	addTodos(t, s, "item-1", "Read", "Write")
+
+	v := NewVault(dir, s)
+	if _, err := v.ExportItem(ctx, "item-1"); err != nil {
+		t.Fatalf("ExportItem: %v", err)
+	}
+
+	// The user checked "Read" in the note a minute ago, but the todo has
+	// since been renamed through the API, so the API holds the newer value.
+	path := filepath.Join(dir, "tasks-item-1.md")
+	editNote(t, path, "- [ ] Read (10m) ^item-1-t1", "- [x] Read (10m) ^item-1-t1", time.Now().Add(-time.Minute))
+	todo, _ := s.GetTodo(ctx, "item-1-t1")
+	title := "Read carefully"
+	todo.Apply(model.TodoPatch{Title: &title})
+	if err := s.UpdateTodo(ctx, *todo); err != nil {
+		t.Fatal(err)
+	}
+
+	// The change event re-exports the item: the edited note is reconciled
+	// first, and the older checkbox loses.
+	if _, err := v.ExportItem(ctx, "item-1"); err != nil {
+		t.Fatalf("ExportItem: %v", err)
+	}
+	todo, _ = s.GetTodo(ctx, "item-1-t1")
+	if todo.Done {
+		t.Error("todo completed from an older note edit")
+	}
+	syncs, _ := s.ListTodoSyncs(ctx, "item-1")
+	if len(syncs) != 1 || syncs[0].Winner != model.SyncSideAPI || !syncs[0].FileDone || syncs[0].APIDone {
+		t.Errorf("sync log = %+v, want one api win", syncs)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "- [ ] Read carefully (10m) ^item-1-t1") {
+		t.Errorf("note not restored to the database state:\n%s", data)
+	}
+}
+
+func TestVault_UneditedNoteFollowsAPI(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	createItem(t, s, "item-1", "Tasks", model.StatusReady)
+	addTodos(t, s, "item-1", "Read")
+
+	v := NewVault(dir, s)
+	if _, err := v.ExportItem(ctx, "item-1"); err != nil {
+		t.Fatalf("ExportItem: %v", err)
+	}
+
+	// A todo completed through the API just updates the untouched note.
+	done := true
+	todo, _ := s.GetTodo(ctx, "item-1-t1")
+	todo.Apply(model.TodoPatch{Done: &done})
+	if err := s.UpdateTodo(ctx, *todo); err != nil {
+		t.Fatal(err)
+	}
+	if outcome, err := v.ExportItem(ctx, "item-1"); err != nil || outcome != OutcomeWritten {
+		t.Fatalf("ExportItem = %s, %v; want written", outcome, err)
+	}
+	if syncs, _ := s.ListTodoSyncs(ctx, ""); len(syncs) != 0 {
+		t.Errorf("sync log = %+v, want empty", syncs)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "tasks-item-1.md"))
+	if !strings.Contains(string(data), "- [x] Read (10m) ^item-1-t1") {
+		t.Errorf("note not updated:\n%s", data)
+	}
+}
@@ -0,0 +1,233 @@
+// Package importer brings saved links from other read-later apps and
+// browsers into readdo. Imported links go through the same duplicate
+// handling as capture, and are queued at a throttled rate so that a large
+// import does not flood the extraction and LLM pipeline.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// maxReportErrors caps how many row errors a report lists.
+const maxReportErrors = 100
+
+// Store creates and merges imported items.
+type Store interface {
+	FindItemByURL(ctx context.Context, url string) (*model.Item, error)
+	FindArchivedItemByURL(ctx context.Context, url string) (*model.Item, error)
+	CreateItem(ctx context.Context, item model.Item, intents ...model.Intent) error
+	UpdateItemForReprocess(ctx context.Context, id, intentText string, saveCount int, intents ...model.Intent) error
+	ReviveItem(ctx context.Context, id, intentText string, saveCount int, intents ...model.Intent) error
+	DeferProcessing(ctx context.Context, id, at string) error
+	LatestProcessAfter(ctx context.Context) (string, error)
+	AddItemTags(ctx context.Context, itemID string, tags []string) error
+}
+
+// EventPublisher receives the events of imported items.
+type EventPublisher interface {
+	Publish(e model.Event)
+}
+
+// RowError describes a bookmark that could not be imported.
+type RowError struct {
+	Row   int    `json:"row"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error"`
+}
+
+// Report summarizes an import.
+type Report struct {
+	Format  string `json:"format"`
+	Total   int    `json:"total"`
+	Created int    `json:"created"`
+	Merged  int    `json:"merged"`
+	Revived int    `json:"revived"` // merged into archived items, which were brought back
+	Skipped int    `json:"skipped"` // links that are not http(s), e.g. bookmarklets
+	Failed  int    `json:"failed"`
+	// Errors lists the first failures; Failed counts all of them.
+	Errors []RowError `json:"errors"`
+	// FirstProcessAt and LastProcessAt bound when the worker will pick up
+	// the queued items. They are omitted when nothing was queued.
+	FirstProcessAt string `json:"first_process_at,omitempty"`
+	LastProcessAt  string `json:"last_process_at,omitempty"`
+}
+
+// Importer turns parsed bookmarks into items.
+type Importer struct {
+	store  Store
+	events EventPublisher
+	rate   int
+	now    func() time.Time
+}
+
+// New creates an Importer that queues at most rate items per minute for
+// processing. A rate of zero or less queues everything at once.
+func New(store Store, rate int) *Importer {
+	return &Importer{store: store, rate: rate, now: time.Now}
+}
+
+// WithEvents publishes an item.captured event for each imported item.
+func (im *Importer) WithEvents(pub EventPublisher) *Importer {
+	im.events = pub
+	return im
+}
+
+// Import creates an item for each new bookmark and merges the others into
+// the existing item with the same URL, reviving it if it was archived, as
+// capture does. extraTags are added
+// to every imported item and must already be normalized. A failed bookmark
+// is reported and skipped; only a cancelled context stops the import.
+func (im *Importer) Import(ctx context.Context, format string, bookmarks []Bookmark, extraTags []string) (Report, error) {
+	rep := Report{Format: format, Total: len(bookmarks), Errors: []RowError{}}
+	next, err := im.firstSlot(ctx)
+	if err != nil {
+		return rep, err
+	}
+
+	for _, b := range bookmarks {
+		if err := ctx.Err(); err != nil {
+			return rep, err
+		}
+		u, err := url.Parse(b.URL)
+		switch {
+		case b.URL == "" || err != nil:
+			rep.fail(b, "invalid url")
+			continue
+		case u.Scheme != "http" && u.Scheme != "https":
+			rep.Skipped++
+			continue
+		}
+
+		var at string
+		if !next.IsZero() {
+			at = next.UTC().Format(time.RFC3339)
+		}
+		tags, _ := model.NormalizeTags(append(importTags(b.Tags), extraTags...))
+		merged, revived, err := im.importOne(ctx, b, u.Hostname(), tags, at)
+		if err != nil {
+			rep.fail(b, err.Error())
+			continue
+		}
+		if merged {
+			rep.Merged++
+			if revived {
+				rep.Revived++
+			}
+		} else {
+			rep.Created++
+		}
+		if at != "" {
+			if rep.FirstProcessAt == "" {
+				rep.FirstProcessAt = at
+			}
+			rep.LastProcessAt = at
+			next = next.Add(time.Minute / time.Duration(im.rate))
+		}
+	}
+	return rep, nil
+}
+
+// firstSlot returns when the first imported item may be processed: now, or
+// right after the items still queued by an earlier import. It returns the
+// zero time when imports are not throttled.
+func (im *Importer) firstSlot(ctx context.Context) (time.Time, error) {
+	if im.rate <= 0 {
+		return time.Time{}, nil
+	}
+	now := im.now().UTC().Truncate(time.Second)
+	latest, err := im.store.LatestProcessAfter(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("find import queue: %w", err)
+	}
+	if t, err := time.Parse(time.RFC3339, latest); err == nil && !t.Before(now) {
+		return t.Add(time.Minute / time.Duration(im.rate)), nil
+	}
+	return now, nil
+}
+
+// importOne creates or merges the item for b, holding it back until at
+// when at is set, and reports whether it was merged into an existing item
+// and whether that item was revived from the archive.
+func (im *Importer) importOne(ctx context.Context, b Bookmark, domain string, tags []string, at string) (merged, revived bool, err error) {
+	existing, err := im.store.FindItemByURL(ctx, b.URL)
+	if err != nil || existing == nil {
+		existing, err = im.store.FindArchivedItemByURL(ctx, b.URL)
+		revived = err == nil && existing != nil
+	}
+	if err == nil && existing != nil {
+		existing.MergeIntent(b.Note)
+		update := im.store.UpdateItemForReprocess
+		if revived {
+			update = im.store.ReviveItem
+		}
+		if err := update(ctx, existing.ID, existing.IntentText, existing.SaveCount, noteIntents(existing.ID, b.Note)...); err != nil {
+			return false, false, errors.New("failed to update item")
+		}
+		if at != "" {
+			if err := im.store.DeferProcessing(ctx, existing.ID, at); err != nil {
+				return false, false, errors.New("failed to queue item")
+			}
+		}
+		if revived && im.events != nil {
+			im.events.Publish(model.NewEvent(model.EventItemRevived, existing.ID))
+		}
+		return true, revived, im.record(ctx, existing.ID, tags)
+	}
+
+	item := model.NewItem(uuid.New().String(), b.URL, b.Title, domain, "web", b.Note)
+	if !b.SavedAt.IsZero() {
+		item.CreatedAt = b.SavedAt.UTC().Format(time.RFC3339)
+	}
+	if at != "" {
+		item.ProcessAfter = &at
+	}
+	if err := im.store.CreateItem(ctx, item, noteIntents(item.ID, b.Note)...); err != nil {
+		return false, false, errors.New("failed to create item")
+	}
+	return false, false, im.record(ctx, item.ID, tags)
+}
+
+// noteIntents returns the intent recording a bookmark's note, if it has one.
+func noteIntents(itemID, note string) []model.Intent {
+	if note == "" {
+		return nil
+	}
+	return []model.Intent{model.NewIntent(uuid.New().String(), itemID, note)}
+}
+
+// record saves the tags and announces the item.
+func (im *Importer) record(ctx context.Context, itemID string, tags []string) error {
+	if im.events != nil {
+		im.events.Publish(model.StatusEvent(itemID, model.StatusCaptured))
+	}
+	if err := im.store.AddItemTags(ctx, itemID, tags); err != nil {
+		return errors.New("failed to save tags")
+	}
+	return nil
+}
+
+func (r *Report) fail(b Bookmark, msg string) {
+	r.Failed++
+	if len(r.Errors) < maxReportErrors {
+		r.Errors = append(r.Errors, RowError{Row: b.Row, URL: b.URL, Error: msg})
+	}
+}
+
+// importTags normalizes each tag on its own, so that one overlong folder
+// name drops only that tag rather than failing the bookmark.
+func importTags(names []string) []string {
+	var out []string
+	for _, n := range names {
+		if t, err := model.NormalizeTags([]string{n}); err == nil {
+			out = append(out, t...)
+		}
+	}
+	return out
+}
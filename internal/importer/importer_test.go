@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := store.New(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	return s
+}
+
+type recordingPublisher struct{ events []model.Event }
+
+func (p *recordingPublisher) Publish(e model.Event) { p.events = append(p.events, e) }
+
+func TestImport(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	existing := model.NewItem("existing", "https://example.com/dup", "Dup", "example.com", "web", "first intent")
+	if err := s.CreateItem(ctx, existing); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	bookmarks := []Bookmark{
+		{Row: 2, URL: "https://example.com/new", Title: "New", Tags: []string{"Go", strings.Repeat("x", 60)}, Note: "to learn", SavedAt: saved},
+		{Row: 3, URL: "https://example.com/dup", Tags: []string{"db"}, Note: "again"},
+		{Row: 4, URL: "place:sort=8"},
+		{Row: 5, URL: "https://exa mple.com/"},
+	}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	pub := &recordingPublisher{}
+	im := New(s, 2).WithEvents(pub)
+	im.now = func() time.Time { return now }
+
+	rep, err := im.Import(ctx, FormatRaindrop, bookmarks, []string{"imported"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if rep.Total != 4 || rep.Created != 1 || rep.Merged != 1 || rep.Skipped != 1 || rep.Failed != 1 {
+		t.Errorf("report = %+v", rep)
+	}
+	if len(rep.Errors) != 1 || rep.Errors[0].Row != 5 {
+		t.Errorf("errors = %+v, want row 5", rep.Errors)
+	}
+	if rep.FirstProcessAt != "2026-05-01T12:00:00Z" || rep.LastProcessAt != "2026-05-01T12:00:30Z" {
+		t.Errorf("processing window = %s .. %s", rep.FirstProcessAt, rep.LastProcessAt)
+	}
+	if len(pub.events) != 2 {
+		t.Errorf("published %d events, want 2", len(pub.events))
+	}
+
+	created, err := s.FindItemByURL(ctx, "https://example.com/new")
+	if err != nil || created == nil {
+		t.Fatalf("imported item not found: %v", err)
+	}
+	if created.CreatedAt != "2021-03-04T05:06:07Z" || created.IntentText != "to learn" || created.Domain != "example.com" {
+		t.Errorf("created item = %+v", created)
+	}
+	if created.ProcessAfter == nil || *created.ProcessAfter != rep.FirstProcessAt {
+		t.Errorf("process_after = %v, want %s", created.ProcessAfter, rep.FirstProcessAt)
+	}
+	full, _ := s.GetItem(ctx, created.ID)
+	if strings.Join(full.Tags, ",") != "go,imported" {
+		t.Errorf("tags = %v, want the valid tags plus the extra tag", full.Tags)
+	}
+
+	dup, _ := s.GetItem(ctx, "existing")
+	if dup.SaveCount != 2 || dup.IntentText != "first intent\n---\nagain" {
+		t.Errorf("merged item = %+v", dup.Item)
+	}
+	if dup.ProcessAfter == nil || *dup.ProcessAfter != rep.LastProcessAt {
+		t.Errorf("merged process_after = %v, want %s", dup.ProcessAfter, rep.LastProcessAt)
+	}
+
+	// A second import queues behind the first.
+	rep, err = im.Import(ctx, FormatRaindrop, []Bookmark{{Row: 2, URL: "https://example.com/later"}}, nil)
+	if err != nil {
+		t.Fatalf("second Import: %v", err)
+	}
+	if rep.FirstProcessAt != "2026-05-01T12:01:00Z" {
+		t.Errorf("second import starts at %s, want after the first", rep.FirstProcessAt)
+	}
+}
+
+func TestImport_RevivesArchived(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	old := model.NewItem("old", "https://example.com/old", "Old", "example.com", "web", "first")
+	if err := s.CreateItem(ctx, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateItemStatus(ctx, "old", model.StatusArchived, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	pub := &recordingPublisher{}
+	rep, err := New(s, 0).WithEvents(pub).Import(ctx, FormatNetscape, []Bookmark{{Row: 1, URL: "https://example.com/old/", Note: "again"}}, nil)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if rep.Created != 0 || rep.Merged != 1 || rep.Revived != 1 {
+		t.Errorf("report = %+v, want the bookmark merged into the archived item", rep)
+	}
+	got, _ := s.GetItem(ctx, "old")
+	if got.Status != model.StatusCaptured || got.RevivedAt == nil || got.SaveCount != 2 || got.IntentText != "first\n---\nagain" {
+		t.Errorf("revived item = %+v", got.Item)
+	}
+	if n, _ := s.CountItems(ctx, model.ItemFilter{Status: []string{model.StatusCaptured, model.StatusArchived}}); n != 1 {
+		t.Errorf("%d items, want the archived one revived rather than a duplicate", n)
+	}
+	if len(pub.events) != 2 || pub.events[0].Type != model.EventItemRevived {
+		t.Errorf("events = %+v, want item.revived then item.captured", pub.events)
+	}
+}
+
+func TestImport_Unthrottled(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	rep, err := New(s, 0).Import(ctx, FormatNetscape, []Bookmark{{Row: 1, URL: "https://example.com/1"}}, nil)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if rep.Created != 1 || rep.FirstProcessAt != "" {
+		t.Errorf("report = %+v", rep)
+	}
+	claimed, err := s.ClaimNextCaptured(ctx)
+	if err != nil || claimed == nil {
+		t.Errorf("unthrottled import not claimable: %v, %v", claimed, err)
+	}
+}
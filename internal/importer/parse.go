@@ -0,0 +1,343 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Supported export formats.
+const (
+	FormatNetscape   = "netscape"    // browser bookmarks (Chrome, Firefox, Safari, Raindrop HTML)
+	FormatPocketHTML = "pocket-html" // Pocket's ril_export.html
+	FormatPocketCSV  = "pocket-csv"  // Pocket's part_000000.csv
+	FormatInstapaper = "instapaper"  // Instapaper's CSV export
+	FormatRaindrop   = "raindrop"    // Raindrop.io's CSV export
+)
+
+var formats = map[string]bool{
+	FormatNetscape:   true,
+	FormatPocketHTML: true,
+	FormatPocketCSV:  true,
+	FormatInstapaper: true,
+	FormatRaindrop:   true,
+}
+
+// IsFormat reports whether f is a supported format name.
+func IsFormat(f string) bool {
+	return formats[f]
+}
+
+// ErrUnknownFormat is returned when the format of an export cannot be detected.
+var ErrUnknownFormat = errors.New("unrecognized export format: expected bookmark HTML or a Pocket, Instapaper or Raindrop CSV")
+
+// Bookmark is one saved link read from an export.
+type Bookmark struct {
+	Row     int // CSV line number, or 1-based link position in HTML
+	URL     string
+	Title   string
+	Tags    []string  // tags and folder names, not yet normalized
+	Note    string    // the user's own note, recorded as the intent
+	SavedAt time.Time // zero when the export has no date
+}
+
+// defaultFolders are folder names that say where a link sat in the source
+// app rather than what it is about, so they do not become tags.
+var defaultFolders = map[string]bool{
+	"unread":            true,
+	"read archive":      true,
+	"archive":           true,
+	"starred":           true,
+	"unsorted":          true,
+	"bookmarks":         true,
+	"bookmarks bar":     true,
+	"bookmarks toolbar": true,
+	"bookmarks menu":    true,
+	"favorites bar":     true,
+	"other bookmarks":   true,
+	"mobile bookmarks":  true,
+}
+
+// Parse reads an export in the given format, or detects the format when
+// format is empty. It returns the format used.
+func Parse(data []byte, format string) (string, []Bookmark, error) {
+	if format == "" {
+		format = DetectFormat(data)
+		if format == "" {
+			return "", nil, ErrUnknownFormat
+		}
+	}
+	var (
+		bookmarks []Bookmark
+		err       error
+	)
+	switch format {
+	case FormatNetscape, FormatPocketHTML:
+		bookmarks, err = parseHTML(data)
+	case FormatPocketCSV:
+		bookmarks, err = parseCSV(data, pocketRow)
+	case FormatInstapaper:
+		bookmarks, err = parseCSV(data, instapaperRow)
+	case FormatRaindrop:
+		bookmarks, err = parseCSV(data, raindropRow)
+	default:
+		return "", nil, fmt.Errorf("unsupported format %q", format)
+	}
+	return format, bookmarks, err
+}
+
+// DetectFormat guesses the format of an export from its content, returning
+// "" if it is not recognized. HTML exports are told apart by Pocket's page
+// title; CSV exports by their header row.
+func DetectFormat(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		if bytes.Contains(trimmed[:min(len(trimmed), 512)], []byte("Pocket Export")) {
+			return FormatPocketHTML
+		}
+		return FormatNetscape
+	}
+	header, err := csv.NewReader(bytes.NewReader(data)).Read()
+	if err != nil {
+		return ""
+	}
+	cols := columnIndex(header)
+	switch {
+	case has(cols, "url", "time_added"):
+		return FormatPocketCSV
+	case has(cols, "url", "folder", "timestamp"):
+		return FormatInstapaper
+	case has(cols, "url", "folder", "created"):
+		return FormatRaindrop
+	}
+	return ""
+}
+
+// ---------------------------------------------------------------------------
+// HTML
+// ---------------------------------------------------------------------------
+
+// parseHTML reads the Netscape bookmark format, which Pocket's HTML export
+// follows loosely: folders are <H3> headings each followed by a <DL> of
+// their links, and Pocket groups its list under <H1> sections instead.
+func parseHTML(data []byte) ([]Bookmark, error) {
+	z := html.NewTokenizer(bytes.NewReader(data))
+	var (
+		out     []Bookmark
+		section string   // current <H1> text
+		folders []string // enclosing <H3> folders
+		pending string   // last <H3> text, waiting for its <DL>
+		heading atom.Atom
+		text    strings.Builder
+		link    *Bookmark
+	)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, fmt.Errorf("parse html: %w", err)
+			}
+			return out, nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.DataAtom {
+			case atom.H1, atom.H3:
+				heading = tok.DataAtom
+				text.Reset()
+			case atom.Dl:
+				folders = append(folders, pending)
+				pending = ""
+			case atom.A:
+				b := Bookmark{Row: len(out) + 1}
+				for _, a := range tok.Attr {
+					switch a.Key {
+					case "href":
+						b.URL = strings.TrimSpace(a.Val)
+					case "add_date", "time_added":
+						b.SavedAt = unixTime(a.Val)
+					case "tags":
+						b.Tags = append(b.Tags, splitTags(a.Val, ",")...)
+					}
+				}
+				b.Tags = append(b.Tags, folderTags(section, folders)...)
+				link = &b
+				text.Reset()
+			}
+
+		case html.EndTagToken:
+			tok := z.Token()
+			switch tok.DataAtom {
+			case atom.H1:
+				section = strings.TrimSpace(text.String())
+				folders = folders[:0]
+				heading = 0
+			case atom.H3:
+				pending = strings.TrimSpace(text.String())
+				heading = 0
+			case atom.Dl:
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case atom.A:
+				if link != nil {
+					link.Title = strings.TrimSpace(text.String())
+					out = append(out, *link)
+					link = nil
+				}
+			}
+
+		case html.TextToken:
+			if heading != 0 || link != nil {
+				text.Write(z.Text())
+			}
+		}
+	}
+}
+
+// folderTags turns the folder path of a link into tags, leaving out the
+// source app's default folders.
+func folderTags(section string, folders []string) []string {
+	var out []string
+	for _, f := range append([]string{section}, folders...) {
+		if f != "" && !defaultFolders[strings.ToLower(f)] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// unixTime parses a Unix timestamp in seconds, tolerating the milli- and
+// microsecond values some browsers write. It returns the zero time if v is
+// empty or invalid.
+func unixTime(v string) time.Time {
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	switch {
+	case n > 1e14:
+		return time.UnixMicro(n).UTC()
+	case n > 1e11:
+		return time.UnixMilli(n).UTC()
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// ---------------------------------------------------------------------------
+// CSV
+// ---------------------------------------------------------------------------
+
+// csvRow maps one CSV record to a bookmark, given the header's column index.
+type csvRow func(cols map[string]int, rec []string) Bookmark
+
+// parseCSV reads a CSV export with a header row. Column names are matched
+// case-insensitively, so reordered or extra columns are fine.
+func parseCSV(data []byte, row csvRow) ([]Bookmark, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	cols := columnIndex(header)
+	if _, ok := cols["url"]; !ok {
+		return nil, errors.New("csv has no url column")
+	}
+
+	var out []Bookmark
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		b := row(cols, rec)
+		b.Row = line
+		out = append(out, b)
+	}
+}
+
+// pocketRow reads title,url,time_added,tags,status; tags are "|"-separated.
+func pocketRow(cols map[string]int, rec []string) Bookmark {
+	return Bookmark{
+		URL:     field(cols, rec, "url"),
+		Title:   field(cols, rec, "title"),
+		Tags:    splitTags(field(cols, rec, "tags"), "|"),
+		SavedAt: unixTime(field(cols, rec, "time_added")),
+	}
+}
+
+// instapaperRow reads URL,Title,Selection,Folder,Timestamp.
+func instapaperRow(cols map[string]int, rec []string) Bookmark {
+	return Bookmark{
+		URL:     field(cols, rec, "url"),
+		Title:   field(cols, rec, "title"),
+		Tags:    folderTags("", []string{field(cols, rec, "folder")}),
+		SavedAt: unixTime(field(cols, rec, "timestamp")),
+	}
+}
+
+// raindropRow reads id,title,note,excerpt,url,folder,tags,created,...;
+// nested collections are written as "Parent / Child".
+func raindropRow(cols map[string]int, rec []string) Bookmark {
+	b := Bookmark{
+		URL:   field(cols, rec, "url"),
+		Title: field(cols, rec, "title"),
+		Note:  field(cols, rec, "note"),
+		Tags:  append(splitTags(field(cols, rec, "tags"), ","), folderTags("", splitTags(field(cols, rec, "folder"), "/"))...),
+	}
+	if t, err := time.Parse(time.RFC3339, field(cols, rec, "created")); err == nil {
+		b.SavedAt = t.UTC()
+	}
+	return b
+}
+
+func columnIndex(header []string) map[string]int {
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	return cols
+}
+
+func has(cols map[string]int, names ...string) bool {
+	for _, n := range names {
+		if _, ok := cols[n]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func field(cols map[string]int, rec []string, name string) string {
+	i, ok := cols[name]
+	if !ok || i >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[i])
+}
+
+func splitTags(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, sep)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
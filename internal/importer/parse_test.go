@@ -0,0 +1,146 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const netscapeExport = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1700000000">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://go.dev/blog" ADD_DATE="1700000000">Go &amp; You</A>
+        <DT><H3>Reading</H3>
+        <DL><p>
+            <DT><A HREF="https://example.com/a" ADD_DATE="1600000000000" TAGS="db,Go">A</A>
+        </DL><p>
+        <DT><A HREF="javascript:alert(1)">Bookmarklet</A>
+    </DL><p>
+    <DT><A HREF="https://example.com/top">Top</A>
+</DL><p>
+`
+
+func TestParse_Netscape(t *testing.T) {
+	format, got, err := Parse([]byte(netscapeExport), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if format != FormatNetscape {
+		t.Errorf("format = %q, want %q", format, FormatNetscape)
+	}
+	want := []Bookmark{
+		{Row: 1, URL: "https://go.dev/blog", Title: "Go & You", SavedAt: time.Unix(1700000000, 0).UTC()},
+		{Row: 2, URL: "https://example.com/a", Title: "A", Tags: []string{"db", "Go", "Reading"}, SavedAt: time.Unix(1600000000, 0).UTC()},
+		{Row: 3, URL: "javascript:alert(1)", Title: "Bookmarklet"},
+		{Row: 4, URL: "https://example.com/top", Title: "Top"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParse_PocketHTML(t *testing.T) {
+	data := `<!DOCTYPE html>
+<html><head><title>Pocket Export</title></head><body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/1" time_added="1650000000" tags="rust,tools">One</a></li>
+</ul>
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/2" time_added="1650000001" tags="">Two</a></li>
+</ul>
+</body></html>`
+	format, got, err := Parse([]byte(data), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if format != FormatPocketHTML {
+		t.Errorf("format = %q, want %q", format, FormatPocketHTML)
+	}
+	want := []Bookmark{
+		{Row: 1, URL: "https://example.com/1", Title: "One", Tags: []string{"rust", "tools"}, SavedAt: time.Unix(1650000000, 0).UTC()},
+		{Row: 2, URL: "https://example.com/2", Title: "Two", SavedAt: time.Unix(1650000001, 0).UTC()},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParse_CSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+		want   []Bookmark
+	}{
+		{
+			name:   "pocket",
+			data:   "title,url,time_added,tags,status\nOne,https://example.com/1,1650000000,go|Deep Dive,unread\n",
+			format: FormatPocketCSV,
+			want: []Bookmark{{Row: 2, URL: "https://example.com/1", Title: "One",
+				Tags: []string{"go", "Deep Dive"}, SavedAt: time.Unix(1650000000, 0).UTC()}},
+		},
+		{
+			name: "instapaper",
+			data: "\ufeffURL,Title,Selection,Folder,Timestamp\n" +
+				"https://example.com/1,One,,Unread,1650000000\n" +
+				"https://example.com/2,\"Two, quoted\",some text,Research,1650000001\n",
+			format: FormatInstapaper,
+			want: []Bookmark{
+				{Row: 2, URL: "https://example.com/1", Title: "One", SavedAt: time.Unix(1650000000, 0).UTC()},
+				{Row: 3, URL: "https://example.com/2", Title: "Two, quoted", Tags: []string{"Research"}, SavedAt: time.Unix(1650000001, 0).UTC()},
+			},
+		},
+		{
+			name: "raindrop",
+			data: "id,title,note,excerpt,url,folder,tags,created,cover,highlights,favorite\n" +
+				"1,One,why I saved it,,https://example.com/1,Work / Infra,\"k8s, ops\",2023-04-05T06:07:08.000Z,,,false\n",
+			format: FormatRaindrop,
+			want: []Bookmark{{Row: 2, URL: "https://example.com/1", Title: "One", Note: "why I saved it",
+				Tags: []string{"k8s", "ops", "Work", "Infra"}, SavedAt: time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, got, err := Parse([]byte(tt.data), "")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if format != tt.format {
+				t.Errorf("format = %q, want %q", format, tt.format)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	if _, _, err := Parse([]byte("just some text\n"), ""); err != ErrUnknownFormat {
+		t.Errorf("undetectable export: err = %v, want ErrUnknownFormat", err)
+	}
+	if _, _, err := Parse([]byte("title,link\nx,y\n"), FormatPocketCSV); err == nil {
+		t.Error("CSV without a url column parsed")
+	}
+	if _, _, err := Parse([]byte("<a>"), "delicious"); err == nil {
+		t.Error("unsupported format parsed")
+	}
+}
+
+func TestUnixTime(t *testing.T) {
+	want := time.Unix(1700000000, 0).UTC()
+	for _, v := range []string{"1700000000", "1700000000000", "1700000000000000"} {
+		if got := unixTime(v); !got.Equal(want) {
+			t.Errorf("unixTime(%q) = %v, want %v", v, got, want)
+		}
+	}
+	if got := unixTime("yesterday"); !got.IsZero() {
+		t.Errorf("unixTime(invalid) = %v, want zero", got)
+	}
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Auto-archive rule bases: the timestamp an item's age is measured from.
+const (
+	ArchiveBasisCreated = "created" // "older than N days"
+	ArchiveBasisUpdated = "updated" // "not touched in N days"
+)
+
+var validPriorities = map[string]bool{
+	PriorityDoFirst: true,
+	PriorityPlanIt:  true,
+	PrioritySkimIt:  true,
+	PriorityLetGo:   true,
+}
+
+// ArchiveRule archives READY items of the given priorities once they are
+// older than Days, measured from creation or last update (Basis).
+// Items the user restored from the archive are never auto-archived again.
+type ArchiveRule struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Priorities []string `json:"priorities"`
+	Basis      string   `json:"basis"`
+	Days       int      `json:"days"`
+	Enabled    bool     `json:"enabled"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+// NewArchiveRule creates a new, enabled ArchiveRule.
+func NewArchiveRule(id, name string, priorities []string, basis string, days int) ArchiveRule {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return ArchiveRule{
+		ID:         id,
+		Name:       name,
+		Priorities: priorities,
+		Basis:      basis,
+		Days:       days,
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// Validate checks the rule's name, priorities, basis and age.
+func (r *ArchiveRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.Priorities) == 0 {
+		return fmt.Errorf("priorities is required")
+	}
+	for _, p := range r.Priorities {
+		if !validPriorities[p] {
+			return fmt.Errorf("invalid priority %q", p)
+		}
+	}
+	if r.Basis != ArchiveBasisCreated && r.Basis != ArchiveBasisUpdated {
+		return fmt.Errorf("basis must be created or updated")
+	}
+	if r.Days < 1 {
+		return fmt.Errorf("days must be at least 1")
+	}
+	return nil
+}
+
+// Cutoff returns the time before which matching items are archived.
+func (r *ArchiveRule) Cutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -r.Days)
+}
+
+// Reason describes why the rule archived an item, for the auto-archive log.
+func (r *ArchiveRule) Reason() string {
+	age := fmt.Sprintf("created more than %d days ago", r.Days)
+	if r.Basis == ArchiveBasisUpdated {
+		age = fmt.Sprintf("not updated in %d days", r.Days)
+	}
+	return fmt.Sprintf("rule %q: %s %s", r.Name, strings.Join(r.Priorities, "/"), age)
+}
+
+// AutoArchive records one item archived by a rule, so it can be undone.
+type AutoArchive struct {
+	ID             string  `json:"id"`
+	ItemID         string  `json:"item_id"`
+	RuleID         string  `json:"rule_id"`
+	Reason         string  `json:"reason"`
+	PreviousStatus string  `json:"previous_status"`
+	ArchivedAt     string  `json:"archived_at"`
+	UndoneAt       *string `json:"undone_at,omitempty"`
+}
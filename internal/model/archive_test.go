@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArchiveRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    ArchiveRule
+		wantErr bool
+	}{
+		{"valid created", NewArchiveRule("r", "let go", []string{PriorityLetGo}, ArchiveBasisCreated, 7), false},
+		{"valid updated", NewArchiveRule("r", "skim", []string{PrioritySkimIt, PriorityLetGo}, ArchiveBasisUpdated, 30), false},
+
+		{"missing name", NewArchiveRule("r", " ", []string{PriorityLetGo}, ArchiveBasisCreated, 7), true},
+		{"no priorities", NewArchiveRule("r", "x", nil, ArchiveBasisCreated, 7), true},
+		{"bad priority", NewArchiveRule("r", "x", []string{"SOMEDAY"}, ArchiveBasisCreated, 7), true},
+		{"bad basis", NewArchiveRule("r", "x", []string{PriorityLetGo}, "archived", 7), true},
+		{"zero days", NewArchiveRule("r", "x", []string{PriorityLetGo}, ArchiveBasisCreated, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArchiveRuleCutoffAndReason(t *testing.T) {
+	r := NewArchiveRule("r", "Stale skims", []string{PrioritySkimIt}, ArchiveBasisUpdated, 30)
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	if got, want := r.Cutoff(now), time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Cutoff = %v, want %v", got, want)
+	}
+	if got, want := r.Reason(), `rule "Stale skims": SKIM_IT not updated in 30 days`; got != want {
+		t.Errorf("Reason = %q, want %q", got, want)
+	}
+}
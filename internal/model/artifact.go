@@ -8,6 +8,7 @@ const (
 	ArtifactSynthesis  = "synthesis"
 	ArtifactScore      = "score"
 	ArtifactTodos      = "todos"
+	ArtifactTags       = "tags"
 )
 
 // Created-by constants
@@ -24,6 +25,17 @@ type Artifact struct {
 	Payload      string `json:"payload"` // JSON string
 	CreatedBy    string `json:"created_by"`
 	CreatedAt    string `json:"created_at"`
+	// Model and PromptVersion identify how a system artifact was generated.
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+	Version       int    `json:"version,omitempty"` // position in the artifact's history; 0 if it has none
+}
+
+// HasHistory reports whether versions of artifacts of type artifactType are
+// kept. Extractions are not: they are the fetched page rather than generated
+// output, and by far the largest artifacts.
+func HasHistory(artifactType string) bool {
+	return artifactType != ArtifactExtraction
 }
 
 // NewArtifact creates a new system-generated Artifact.
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultTrackingParams are the query parameters dropped from URLs by
+// default. A trailing "*" matches any parameter with that prefix.
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "igshid", "mkt_tok", "_hsenc", "_hsmi", "__s",
+	"ref", "ref_src", "ref_url", "spm", "share_id", "si",
+}
+
+// mobileHostPrefixes are host labels that serve the same pages as the bare
+// domain.
+var mobileHostPrefixes = []string{"www.", "m.", "mobile.", "amp."}
+
+// URLCanonicalizer reduces URLs to a canonical form, so that links to the
+// same page with different tracking parameters, schemes, hosts or
+// fragments are recognized as one item.
+type URLCanonicalizer struct {
+	exact  map[string]bool
+	prefix []string
+}
+
+// NewURLCanonicalizer returns a canonicalizer dropping DefaultTrackingParams
+// and the given rules. A rule "name" drops that parameter, "name*" drops
+// every parameter starting with name, and "-name" keeps a parameter that a
+// default rule would drop. Parameter names are compared case-insensitively.
+func NewURLCanonicalizer(rules []string) *URLCanonicalizer {
+	keep := map[string]bool{}
+	var add []string
+	for _, r := range rules {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if name, ok := strings.CutPrefix(r, "-"); ok {
+			keep[name] = true
+		} else if r != "" {
+			add = append(add, r)
+		}
+	}
+
+	c := &URLCanonicalizer{exact: map[string]bool{}}
+	for _, r := range append(append([]string{}, DefaultTrackingParams...), add...) {
+		if keep[r] {
+			continue
+		}
+		if p, ok := strings.CutSuffix(r, "*"); ok {
+			c.prefix = append(c.prefix, p)
+		} else {
+			c.exact[r] = true
+		}
+	}
+	return c
+}
+
+// Canonicalize returns the canonical form of raw: https scheme, lower-case
+// host without "www."/"m." style prefixes or default port, no trailing
+// slash, tracking parameters dropped and the rest sorted, and no fragment
+// unless it is a "#!" or "#/" client-side route. Strings that are not
+// absolute http(s) URLs are returned trimmed but otherwise unchanged.
+func (c *URLCanonicalizer) Canonicalize(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return raw
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, p := range mobileHostPrefixes {
+		if rest, ok := strings.CutPrefix(host, p); ok && strings.Contains(rest, ".") {
+			host = rest
+			break
+		}
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6 literal
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	if path == "" {
+		path = "/"
+	}
+
+	out := "https://" + host + path
+	if q := c.query(u.RawQuery); q != "" {
+		out += "?" + q
+	}
+	if f := u.EscapedFragment(); strings.HasPrefix(f, "!") || strings.HasPrefix(f, "/") {
+		out += "#" + f
+	}
+	return out
+}
+
+// query drops tracking parameters from a raw query and sorts the rest by
+// name, keeping the order of repeated values.
+func (c *URLCanonicalizer) query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	var params []string
+	for _, p := range strings.Split(raw, "&") {
+		if p == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(p, "=")
+		if key, err := url.QueryUnescape(name); err == nil && c.tracking(key) {
+			continue
+		}
+		params = append(params, p)
+	}
+	sort.SliceStable(params, func(i, j int) bool {
+		ni, _, _ := strings.Cut(params[i], "=")
+		nj, _, _ := strings.Cut(params[j], "=")
+		return ni < nj
+	})
+	return strings.Join(params, "&")
+}
+
+func (c *URLCanonicalizer) tracking(name string) bool {
+	name = strings.ToLower(name)
+	if c.exact[name] {
+		return true
+	}
+	for _, p := range c.prefix {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
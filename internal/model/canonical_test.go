@@ -0,0 +1,48 @@
+package model
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	c := NewURLCanonicalizer(nil)
+	tests := []struct {
+		in, want string
+	}{
+		{"https://example.com/post", "https://example.com/post"},
+		{"http://example.com/post/", "https://example.com/post"},
+		{"https://WWW.Example.com/post?utm_source=x&utm_medium=y", "https://example.com/post"},
+		{"https://m.example.com/post#comments", "https://example.com/post"},
+		{"https://example.com:443/post", "https://example.com/post"},
+		{"https://example.com:8443/post", "https://example.com:8443/post"},
+		{"https://example.com", "https://example.com/"},
+		{"https://example.com/?fbclid=abc", "https://example.com/"},
+		{"https://example.com/search?q=go&b=2&a=1&a=0", "https://example.com/search?a=1&a=0&b=2&q=go"},
+		{"https://example.com/app#/inbox", "https://example.com/app#/inbox"},
+		{"https://example.com/Case/Path", "https://example.com/Case/Path"},
+		{"https://m.example/post", "https://m.example/post"},
+		{"https://[::1]:80/x", "https://[::1]/x"},
+		{"  https://example.com/a?UTM_Campaign=z  ", "https://example.com/a"},
+		{"mailto:me@example.com", "mailto:me@example.com"},
+		{"not a url", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := c.Canonicalize(tt.in); got != tt.want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalize_Rules(t *testing.T) {
+	c := NewURLCanonicalizer([]string{"session", "trk_*", "-ref", " "})
+	tests := []struct {
+		in, want string
+	}{
+		{"https://example.com/a?session=1&id=2", "https://example.com/a?id=2"},
+		{"https://example.com/a?trk_src=feed", "https://example.com/a"},
+		{"https://example.com/a?ref=home&utm_source=x", "https://example.com/a?ref=home"},
+	}
+	for _, tt := range tests {
+		if got := c.Canonicalize(tt.in); got != tt.want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+// Duplicate cluster reasons.
+const (
+	DuplicateURL       = "url"       // the items share a canonical URL
+	DuplicateSuspected = "suspected" // an item's rel=canonical points at another item
+	DuplicateContent   = "content"   // the items' extracted texts are near-duplicates
+)
+
+// DuplicateCluster is a group of items that look like the same content.
+type DuplicateCluster struct {
+	Reason string `json:"reason"`
+	Key    string `json:"key"` // the shared value, e.g. the canonical URL
+	Items  []Item `json:"items"`
+}
+
+// MergedError reports that processing stopped because the item was folded
+// into an existing item, IntoID, which was queued for processing instead.
+type MergedError struct {
+	IntoID string
+}
+
+func (e *MergedError) Error() string {
+	return "merged into existing item " + e.IntoID
+}
@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// Item lifecycle event types streamed to clients.
+const (
+	EventItemCaptured  = "item.captured"
+	EventStepStarted   = "item.step_started"
+	EventStepFinished  = "item.step_finished"
+	EventItemReady     = "item.ready"
+	EventItemFailed    = "item.failed"
+	EventItemArchived  = "item.archived"
+	EventItemRevived   = "item.revived"        // an archived item was captured again
+	EventStatusChanged = "item.status_changed" // any other status change, e.g. DONE or SNOOZED
+	EventItemUpdated   = "item.updated"        // user edit of todos, tags or artifacts
+	EventItemDeleted   = "item.deleted"
+)
+
+var eventTypes = map[string]bool{
+	EventItemCaptured:  true,
+	EventStepStarted:   true,
+	EventStepFinished:  true,
+	EventItemReady:     true,
+	EventItemFailed:    true,
+	EventItemArchived:  true,
+	EventItemRevived:   true,
+	EventStatusChanged: true,
+	EventItemUpdated:   true,
+	EventItemDeleted:   true,
+}
+
+// IsEventType reports whether t is a known event type.
+func IsEventType(t string) bool {
+	return eventTypes[t]
+}
+
+// Event is an item lifecycle notification. ID is assigned by the event bus
+// and increases monotonically, so clients can resume after it.
+type Event struct {
+	ID     int64  `json:"id"`
+	Type   string `json:"type"`
+	ItemID string `json:"item_id"`
+	Status string `json:"status,omitempty"`
+	Step   string `json:"step,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Time   string `json:"time"`
+}
+
+// NewEvent creates an event of the given type for an item.
+func NewEvent(eventType, itemID string) Event {
+	return Event{Type: eventType, ItemID: itemID, Time: time.Now().UTC().Format(time.RFC3339Nano)}
+}
+
+// StatusEvent creates the event announcing that an item moved to status.
+func StatusEvent(itemID, status string) Event {
+	eventType := EventStatusChanged
+	switch status {
+	case StatusCaptured:
+		eventType = EventItemCaptured
+	case StatusReady:
+		eventType = EventItemReady
+	case StatusFailed:
+		eventType = EventItemFailed
+	case StatusArchived:
+		eventType = EventItemArchived
+	}
+	e := NewEvent(eventType, itemID)
+	e.Status = status
+	return e
+}
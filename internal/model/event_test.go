@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestStatusEvent(t *testing.T) {
+	tests := []struct {
+		status   string
+		wantType string
+	}{
+		{StatusCaptured, EventItemCaptured},
+		{StatusReady, EventItemReady},
+		{StatusFailed, EventItemFailed},
+		{StatusArchived, EventItemArchived},
+		{StatusProcessing, EventStatusChanged},
+		{StatusDone, EventStatusChanged},
+		{StatusSnoozed, EventStatusChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			e := StatusEvent("item-1", tt.status)
+			if e.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", e.Type, tt.wantType)
+			}
+			if e.ItemID != "item-1" || e.Status != tt.status || e.Time == "" {
+				t.Errorf("event = %+v, want item-1 with status %s and a time", e, tt.status)
+			}
+		})
+	}
+}
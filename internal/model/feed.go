@@ -0,0 +1,112 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Feed is an Atom feed of READY items for feed readers. Readers present the
+// feed's secret token, of which only the SHA-256 hash is stored. Priority
+// and Tags narrow the items further, on top of the saved view's filter when
+// ViewID is set.
+type Feed struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	ViewID    *string  `json:"view_id,omitempty"`
+	Priority  []string `json:"priority"`
+	Tags      []string `json:"tags"`
+	TokenHash string   `json:"-"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// NewFeed creates a new Feed read with token.
+func NewFeed(id, name string, viewID *string, priority, tags []string, token string) Feed {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return Feed{
+		ID:        id,
+		Name:      name,
+		ViewID:    viewID,
+		Priority:  priority,
+		Tags:      tags,
+		TokenHash: HashFeedToken(token),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// HashFeedToken returns the hex SHA-256 hash under which a feed token is
+// stored and looked up.
+func HashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate checks the feed's name and priorities.
+func (f *Feed) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	for _, p := range f.Priority {
+		if !validPriorities[p] {
+			return fmt.Errorf("invalid priority %q", p)
+		}
+	}
+	if f.ViewID != nil && *f.ViewID == "" {
+		return fmt.Errorf("view_id must not be empty")
+	}
+	return nil
+}
+
+// Filter returns the filter selecting the feed's items: the view's filter
+// (view may be nil), narrowed by the feed's own priorities and tags, and
+// always restricted to READY items. Priorities set on both are intersected
+// and tags set on both must each match. ok is false when the feed selects
+// nothing: the view excludes READY items, or the intersection leaves no
+// priority.
+func (f *Feed) Filter(view *View) (filter ItemFilter, ok bool) {
+	if view != nil {
+		filter = view.Filter
+	}
+	if len(filter.Status) > 0 && !slices.Contains(filter.Status, StatusReady) {
+		return ItemFilter{}, false
+	}
+	if len(f.Priority) > 0 {
+		if len(filter.Priority) == 0 {
+			filter.Priority = f.Priority
+		} else {
+			filter.Priority = intersect(filter.Priority, f.Priority)
+			if len(filter.Priority) == 0 {
+				return ItemFilter{}, false
+			}
+		}
+	}
+	if len(f.Tags) > 0 {
+		if len(filter.Tags) == 0 {
+			filter.Tags = f.Tags
+		} else {
+			filter.AlsoTags = f.Tags
+		}
+	}
+	filter.Status = []string{StatusReady}
+	return filter, true
+}
+
+// intersect returns the values of a that are also in b, in a's order.
+func intersect(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, v := range b {
+		in[v] = true
+	}
+	var out []string
+	for _, v := range a {
+		if in[v] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFeedValidate(t *testing.T) {
+	empty := ""
+	tests := []struct {
+		name    string
+		feed    Feed
+		wantErr bool
+	}{
+		{"valid", NewFeed("f", "Reading", nil, []string{PriorityDoFirst}, []string{"go"}, "tok"), false},
+		{"no filters", NewFeed("f", "Everything", nil, nil, nil, "tok"), false},
+
+		{"missing name", NewFeed("f", " ", nil, nil, nil, "tok"), true},
+		{"bad priority", NewFeed("f", "x", nil, []string{"SOMEDAY"}, nil, "tok"), true},
+		{"empty view", NewFeed("f", "x", &empty, nil, nil, "tok"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.feed.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeedTokenHash(t *testing.T) {
+	f := NewFeed("f", "x", nil, nil, nil, "secret")
+	if f.TokenHash != HashFeedToken("secret") || f.TokenHash == "secret" || len(f.TokenHash) != 64 {
+		t.Errorf("TokenHash = %q, want the hex SHA-256 of the token", f.TokenHash)
+	}
+}
+
+func TestFeedFilter(t *testing.T) {
+	view := &View{Filter: ItemFilter{Status: []string{StatusReady, StatusDone}, Priority: []string{PriorityDoFirst, PriorityLetGo}, Query: "go", Tags: []string{"db"}}}
+
+	f := NewFeed("f", "x", nil, nil, nil, "tok")
+	if got, ok := f.Filter(nil); !ok || !reflect.DeepEqual(got, ItemFilter{Status: []string{StatusReady}}) {
+		t.Errorf("Filter(nil) = %+v, %v", got, ok)
+	}
+
+	// The view's filter applies, but only to READY items; the feed's own
+	// priorities intersect with the view's and its tags must match too.
+	f.Priority = []string{PrioritySkimIt, PriorityDoFirst}
+	f.Tags = []string{"sql"}
+	want := ItemFilter{Status: []string{StatusReady}, Priority: []string{PriorityDoFirst}, Query: "go", Tags: []string{"db"}, AlsoTags: []string{"sql"}}
+	if got, ok := f.Filter(view); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter(view) = %+v, %v, want %+v", got, ok, want)
+	}
+
+	// Without a view the feed's own filters stand alone.
+	want = ItemFilter{Status: []string{StatusReady}, Priority: []string{PrioritySkimIt, PriorityDoFirst}, Tags: []string{"sql"}}
+	if got, ok := f.Filter(nil); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter(nil) = %+v, %v, want %+v", got, ok, want)
+	}
+
+	// Disjoint priorities select nothing.
+	f.Priority = []string{PrioritySkimIt}
+	if got, ok := f.Filter(view); ok {
+		t.Errorf("Filter(view) with disjoint priorities = %+v, want ok = false", got)
+	}
+
+	// A view that never shows READY items leaves the feed empty.
+	f.Priority = nil
+	archived := &View{Filter: ItemFilter{Status: []string{StatusArchived}, Query: "go"}}
+	if got, ok := f.Filter(archived); ok {
+		t.Errorf("Filter(ARCHIVED view) = %+v, want ok = false", got)
+	}
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,8 @@ const (
 	StatusReady      = "READY"
 	StatusFailed     = "FAILED"
 	StatusArchived   = "ARCHIVED"
+	StatusDone       = "DONE"
+	StatusSnoozed    = "SNOOZED"
 )
 
 // Priority constants
@@ -24,19 +27,27 @@ const (
 
 // Item represents a captured content item.
 type Item struct {
-	ID         string   `json:"id"`
-	URL        string   `json:"url"`
-	Title      string   `json:"title"`
-	Domain     string   `json:"domain"`
-	SourceType string   `json:"source_type"`
-	IntentText string   `json:"intent_text"`
-	Status     string   `json:"status"`
-	Priority   *string  `json:"priority,omitempty"`
-	MatchScore *float64 `json:"match_score,omitempty"`
-	ErrorInfo  *string  `json:"error_info,omitempty"`
-	SaveCount  int      `json:"save_count"`
-	CreatedAt  string   `json:"created_at"`
-	UpdatedAt  string   `json:"updated_at"`
+	ID           string   `json:"id"`
+	URL          string   `json:"url"`
+	CanonicalURL string   `json:"canonical_url"` // URL reduced by URLCanonicalizer; duplicates share it
+	Title        string   `json:"title"`
+	Domain       string   `json:"domain"`
+	SourceType   string   `json:"source_type"`
+	IntentText   string   `json:"intent_text"`
+	Status       string   `json:"status"`
+	Priority     *string  `json:"priority,omitempty"`
+	MatchScore   *float64 `json:"match_score,omitempty"`
+	ErrorInfo    *string  `json:"error_info,omitempty"`
+	SaveCount    int      `json:"save_count"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+	CompletedAt  *string  `json:"completed_at,omitempty"`  // set while the item is DONE
+	SnoozeUntil  *string  `json:"snooze_until,omitempty"`  // set while the item is SNOOZED
+	RestoredAt   *string  `json:"restored_at,omitempty"`   // last time the user restored it from the archive
+	RevivedAt    *string  `json:"revived_at,omitempty"`    // last time a repeated capture brought it back from the archive
+	ProcessAfter *string  `json:"process_after,omitempty"` // throttled import: not processed before this time
+	ResolvedURL  string   `json:"resolved_url,omitempty"`  // canonical form of where the URL led: redirects and rel=canonical
+	DuplicateOf  *string  `json:"duplicate_of,omitempty"`  // suspected duplicate of this item, awaiting the user's confirmation
 }
 
 // Intent represents a single capture event with its own timestamp.
@@ -57,23 +68,46 @@ func NewIntent(id, itemID, text string) Intent {
 	}
 }
 
-// ItemWithArtifacts is an Item together with its associated artifacts and intents.
+// FormatIntents renders an item's intents for a prompt: most recent first,
+// each prefixed with the date it was captured, separated as in intent_text.
+func FormatIntents(intents []Intent) string {
+	parts := make([]string, 0, len(intents))
+	for i := len(intents) - 1; i >= 0; i-- {
+		date := intents[i].CreatedAt
+		if len(date) > len("2006-01-02") {
+			date = date[:len("2006-01-02")]
+		}
+		parts = append(parts, "["+date+"] "+intents[i].Text)
+	}
+	return strings.Join(parts, "\n---\n")
+}
+
+// ItemWithArtifacts is an Item together with its associated artifacts, intents, tags and todos.
 type ItemWithArtifacts struct {
 	Item
 	Artifacts []Artifact `json:"artifacts"`
+	Proposals []Artifact `json:"proposals,omitempty"` // regenerated versions of artifacts the user has edited
 	Intents   []Intent   `json:"intents"`
+	Tags      []string   `json:"tags"`
+	Todos     []Todo     `json:"todos"`
 }
 
 // ItemFilter holds query parameters for listing items.
+// It is also persisted as JSON inside saved views.
 type ItemFilter struct {
-	Status   []string
-	Priority []string
-	Query    string
+	Status   []string `json:"status,omitempty"`
+	Priority []string `json:"priority,omitempty"`
+	Query    string   `json:"q,omitempty"`
+	Tags     []string `json:"tags,omitempty"`   // items carrying any of these tags
+	Within   string   `json:"within,omitempty"` // relative window on updated_at, e.g. "24h" or "7d"
+	AlsoTags []string `json:"-"`                // items must also carry one of these, e.g. a feed's tags on top of its view's
 }
 
 // allowedTransitions defines which status transitions are valid for user-initiated actions.
 var allowedTransitions = map[string]map[string]bool{
-	StatusReady:    {StatusArchived: true},
+	StatusReady:    {StatusArchived: true, StatusDone: true, StatusSnoozed: true},
+	StatusDone:     {StatusReady: true, StatusArchived: true},
+	StatusSnoozed:  {StatusReady: true, StatusArchived: true},
 	StatusFailed:   {StatusCaptured: true, StatusArchived: true},
 	StatusArchived: {StatusReady: true},
 }
@@ -82,13 +116,20 @@ var allowedTransitions = map[string]map[string]bool{
 var userSettableStatuses = map[string]bool{
 	StatusArchived: true,
 	StatusReady:    true,
+	StatusDone:     true,
+	StatusSnoozed:  true,
+}
+
+// IsUserSettableStatus reports whether status can be set directly by the user.
+func IsUserSettableStatus(status string) bool {
+	return userSettableStatuses[status]
 }
 
 // ValidateTransition checks whether transitioning from the item's current status
 // to target is allowed. Returns nil if the transition is valid.
 func (i *Item) ValidateTransition(target string) error {
 	if !userSettableStatuses[target] {
-		return fmt.Errorf("status must be ARCHIVED or READY")
+		return fmt.Errorf("status must be ARCHIVED, READY, DONE or SNOOZED")
 	}
 	if i.Status == StatusProcessing {
 		return fmt.Errorf("cannot change status while PROCESSING")
@@ -100,6 +141,42 @@ func (i *Item) ValidateTransition(target string) error {
 	return nil
 }
 
+// ParseSnoozeUntil parses a snooze deadline given as RFC 3339 or YYYY-MM-DD
+// (midnight UTC) and returns it normalized to RFC 3339 UTC. The deadline must
+// be after now.
+func ParseSnoozeUntil(s string, now time.Time) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("snooze_until is required when snoozing")
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, s)
+		if err != nil {
+			return "", fmt.Errorf("snooze_until must be RFC 3339 or YYYY-MM-DD")
+		}
+	}
+	if !t.After(now) {
+		return "", fmt.Errorf("snooze_until must be in the future")
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// CompletionStatus returns the status the item should move to given its todo
+// progress: a READY item with all todos done becomes DONE, and a DONE item
+// goes back to READY when reopened reports that a todo was just unticked.
+// Other todo changes leave a DONE item alone, so an item the user marked
+// DONE with todos still open stays DONE. ok is false when no change is
+// needed.
+func (i *Item) CompletionStatus(totalTodos, doneTodos int, reopened bool) (status string, ok bool) {
+	switch {
+	case i.Status == StatusReady && totalTodos > 0 && doneTodos == totalTodos:
+		return StatusDone, true
+	case i.Status == StatusDone && reopened && doneTodos < totalTodos:
+		return StatusReady, true
+	}
+	return i.Status, false
+}
+
 // NewItem creates a new Item with CAPTURED status.
 func NewItem(id, url, title, domain, sourceType, intentText string) Item {
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -117,6 +194,19 @@ func NewItem(id, url, title, domain, sourceType, intentText string) Item {
 	}
 }
 
+// Absorb folds a duplicate into the item: its intent text is appended and
+// its saves are added to the item's save count.
+func (i *Item) Absorb(dup Item) {
+	switch {
+	case dup.IntentText == "":
+	case i.IntentText == "":
+		i.IntentText = dup.IntentText
+	default:
+		i.IntentText = i.IntentText + "\n---\n" + dup.IntentText
+	}
+	i.SaveCount += dup.SaveCount
+}
+
 // MergeIntent appends a new intent to the item's existing intent text.
 // It uses newline separator and increments the save count.
 func (i *Item) MergeIntent(newIntent string) {
@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNewItem(t *testing.T) {
@@ -66,6 +67,40 @@ func TestMergeIntent(t *testing.T) {
 	})
 }
 
+func TestAbsorb(t *testing.T) {
+	tests := []struct {
+		into, dup, want string
+	}{
+		{"first", "second", "first\n---\nsecond"},
+		{"", "second", "second"},
+		{"first", "", "first"},
+	}
+	for _, tt := range tests {
+		item := NewItem("a", "https://example.com/a", "A", "example.com", "web", tt.into)
+		item.SaveCount = 2
+		dup := NewItem("b", "https://example.com/b", "B", "example.com", "web", tt.dup)
+		dup.SaveCount = 3
+		item.Absorb(dup)
+		if item.IntentText != tt.want || item.SaveCount != 5 {
+			t.Errorf("Absorb(%q, %q) = %q, %d; want %q, 5", tt.into, tt.dup, item.IntentText, item.SaveCount, tt.want)
+		}
+	}
+}
+
+func TestFormatIntents(t *testing.T) {
+	intents := []Intent{
+		{ID: "1", Text: "learn generics", CreatedAt: "2026-09-01T08:00:00Z"},
+		{ID: "2", Text: "compare with Rust", CreatedAt: "2026-10-15T21:30:00Z"},
+	}
+	want := "[2026-10-15] compare with Rust\n---\n[2026-09-01] learn generics"
+	if got := FormatIntents(intents); got != want {
+		t.Errorf("FormatIntents = %q, want %q", got, want)
+	}
+	if got := FormatIntents(nil); got != "" {
+		t.Errorf("FormatIntents(nil) = %q", got)
+	}
+}
+
 func TestValidateTransition(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -76,6 +111,12 @@ func TestValidateTransition(t *testing.T) {
 		{"READY to ARCHIVED", StatusReady, StatusArchived, false},
 		{"FAILED to ARCHIVED", StatusFailed, StatusArchived, false},
 		{"ARCHIVED to READY", StatusArchived, StatusReady, false},
+		{"READY to DONE", StatusReady, StatusDone, false},
+		{"DONE to READY (reopen)", StatusDone, StatusReady, false},
+		{"DONE to ARCHIVED", StatusDone, StatusArchived, false},
+		{"READY to SNOOZED", StatusReady, StatusSnoozed, false},
+		{"SNOOZED to READY (wake)", StatusSnoozed, StatusReady, false},
+		{"SNOOZED to ARCHIVED", StatusSnoozed, StatusArchived, false},
 
 		{"PROCESSING blocks transition", StatusProcessing, StatusArchived, true},
 		{"CAPTURED to ARCHIVED forbidden", StatusCaptured, StatusArchived, true},
@@ -83,6 +124,10 @@ func TestValidateTransition(t *testing.T) {
 		{"invalid target status", StatusReady, StatusProcessing, true},
 		{"READY to CAPTURED forbidden", StatusReady, StatusCaptured, true},
 		{"FAILED to CAPTURED not user-settable", StatusFailed, StatusCaptured, true},
+		{"CAPTURED to DONE forbidden", StatusCaptured, StatusDone, true},
+		{"ARCHIVED to DONE forbidden", StatusArchived, StatusDone, true},
+		{"CAPTURED to SNOOZED forbidden", StatusCaptured, StatusSnoozed, true},
+		{"DONE to SNOOZED forbidden", StatusDone, StatusSnoozed, true},
 	}
 
 	for _, tt := range tests {
@@ -96,6 +141,65 @@ func TestValidateTransition(t *testing.T) {
 	}
 }
 
+func TestParseSnoozeUntil(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"RFC 3339 UTC", "2026-03-02T09:00:00Z", "2026-03-02T09:00:00Z", false},
+		{"RFC 3339 offset normalized", "2026-03-02T09:00:00+08:00", "2026-03-02T01:00:00Z", false},
+		{"date only", "2026-03-05", "2026-03-05T00:00:00Z", false},
+
+		{"empty", "", "", true},
+		{"garbage", "next week", "", true},
+		{"in the past", "2026-02-28", "", true},
+		{"exactly now", "2026-03-01T12:00:00Z", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSnoozeUntil(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSnoozeUntil(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSnoozeUntil(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletionStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        string
+		total, done int
+		reopened    bool
+		want        string
+		wantOK      bool
+	}{
+		{"READY all done → DONE", StatusReady, 3, 3, false, StatusDone, true},
+		{"READY partially done stays", StatusReady, 3, 2, false, StatusReady, false},
+		{"READY without todos stays", StatusReady, 0, 0, false, StatusReady, false},
+		{"DONE with reopened todo → READY", StatusDone, 3, 2, true, StatusReady, true},
+		{"DONE with open todo otherwise stays", StatusDone, 3, 2, false, StatusDone, false},
+		{"DONE all done stays", StatusDone, 3, 3, false, StatusDone, false},
+		{"ARCHIVED never changes", StatusArchived, 3, 3, false, StatusArchived, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &Item{Status: tt.from}
+			got, ok := item.CompletionStatus(tt.total, tt.done, tt.reopened)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("CompletionStatus(%d, %d, %v) = %q, %v; want %q, %v", tt.total, tt.done, tt.reopened, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestNewIntent(t *testing.T) {
 	intent := NewIntent("int-1", "item-1", "learn Go context")
 	if intent.ID != "int-1" {
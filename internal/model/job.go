@@ -0,0 +1,22 @@
+package model
+
+// Job run outcomes recorded in Job.LastStatus.
+const (
+	JobStatusOK    = "ok"
+	JobStatusError = "error"
+)
+
+// Job is the persisted state of a recurring maintenance job.
+// NextRunAt is stored so that a restart neither re-fires nor skips a run.
+type Job struct {
+	Name           string  `json:"name"`
+	Schedule       string  `json:"schedule"` // cron expression, e.g. "0 3 * * *"
+	NextRunAt      string  `json:"next_run_at"`
+	LastRunAt      *string `json:"last_run_at,omitempty"`
+	LastStatus     string  `json:"last_status,omitempty"` // "ok" or "error"
+	LastError      *string `json:"last_error,omitempty"`
+	LastDurationMS int64   `json:"last_duration_ms"`
+	RunCount       int     `json:"run_count"`
+	Running        bool    `json:"running"` // in-memory only, not persisted
+	UpdatedAt      string  `json:"updated_at"`
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSON diff operations, named as in JSON Patch (RFC 6902).
+const (
+	DiffAdd     = "add"
+	DiffRemove  = "remove"
+	DiffReplace = "replace"
+)
+
+// JSONChange is one difference between two JSON documents.
+type JSONChange struct {
+	Path string `json:"path"` // JSON Pointer (RFC 6901) to the value; "" is the whole document
+	Op   string `json:"op"`
+	From any    `json:"from,omitempty"` // the old value, for remove and replace
+	To   any    `json:"to,omitempty"`   // the new value, for add and replace
+}
+
+// DiffJSON lists the changes that turn JSON document a into b. Objects are
+// compared key by key in sorted order and arrays element by element, so an
+// element inserted into an array shows as replacements of the elements
+// after it and an addition at the end.
+func DiffJSON(a, b string) ([]JSONChange, error) {
+	var va, vb any
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		return nil, fmt.Errorf("decode old document: %w", err)
+	}
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		return nil, fmt.Errorf("decode new document: %w", err)
+	}
+	changes := []JSONChange{}
+	diffValues("", va, vb, &changes)
+	return changes, nil
+}
+
+func diffValues(path string, a, b any, changes *[]JSONChange) {
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			keys := make([]string, 0, len(av)+len(bv))
+			for k := range av {
+				keys = append(keys, k)
+			}
+			for k := range bv {
+				if _, ok := av[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				p := path + "/" + escapePointer(k)
+				from, inA := av[k]
+				to, inB := bv[k]
+				switch {
+				case !inA:
+					*changes = append(*changes, JSONChange{Path: p, Op: DiffAdd, To: to})
+				case !inB:
+					*changes = append(*changes, JSONChange{Path: p, Op: DiffRemove, From: from})
+				default:
+					diffValues(p, from, to, changes)
+				}
+			}
+			return
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			for i := 0; i < len(av) || i < len(bv); i++ {
+				p := path + "/" + strconv.Itoa(i)
+				switch {
+				case i >= len(av):
+					*changes = append(*changes, JSONChange{Path: p, Op: DiffAdd, To: bv[i]})
+				case i >= len(bv):
+					*changes = append(*changes, JSONChange{Path: p, Op: DiffRemove, From: av[i]})
+				default:
+					diffValues(p, av[i], bv[i], changes)
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, JSONChange{Path: path, Op: DiffReplace, From: a, To: b})
+	}
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name, a, b string
+		want       []JSONChange
+	}{
+		{"equal", `{"insight":"x","points":["a"]}`, `{"points":["a"],"insight":"x"}`, []JSONChange{}},
+		{
+			"field changed",
+			`{"insight":"old","points":["a","b"]}`,
+			`{"insight":"new","points":["a","b"]}`,
+			[]JSONChange{{Path: "/insight", Op: DiffReplace, From: "old", To: "new"}},
+		},
+		{
+			"array grows and shrinks",
+			`{"points":["a","b"],"gone":1}`,
+			`{"points":["a","c","d"],"a/b~":true}`,
+			[]JSONChange{
+				{Path: "/a~1b~0", Op: DiffAdd, To: true},
+				{Path: "/gone", Op: DiffRemove, From: float64(1)},
+				{Path: "/points/1", Op: DiffReplace, From: "b", To: "c"},
+				{Path: "/points/2", Op: DiffAdd, To: "d"},
+			},
+		},
+		{
+			"type changed",
+			`{"todos":[{"title":"Read"}]}`,
+			`{"todos":{"title":"Read"}}`,
+			[]JSONChange{{Path: "/todos", Op: DiffReplace, From: []any{map[string]any{"title": "Read"}}, To: map[string]any{"title": "Read"}}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := DiffJSON(tt.a, tt.b)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: DiffJSON = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := DiffJSON(`{`, `{}`); err == nil {
+		t.Error("malformed document: expected error")
+	}
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ranking parameters.
+const (
+	// rankHalfLife is how long it takes an item's rank to halve with age.
+	rankHalfLife = 14 * 24 * time.Hour
+	// rankRecentWindow and rankRecentBoost favour items touched recently,
+	// e.g. just woken from a snooze or restored from the archive.
+	rankRecentWindow = 48 * time.Hour
+	rankRecentBoost  = 1.25
+	// rankDefaultScore is used for items that have not been scored yet.
+	rankDefaultScore = 50
+	// DefaultEffort is the assumed effort of an item without open todos
+	// (just reading it) or of a todo without a usable ETA.
+	DefaultEffort = 15 * time.Minute
+)
+
+// ParseETA parses a todo ETA such as "20m", "1h" or "3h+" (treated as 3h).
+func ParseETA(eta string) (time.Duration, bool) {
+	d, err := time.ParseDuration(strings.TrimSuffix(strings.TrimSpace(eta), "+"))
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// TodoEffort returns the estimated effort of a todo, falling back to DefaultEffort.
+func TodoEffort(t Todo) time.Duration {
+	if d, ok := ParseETA(t.ETA); ok {
+		return d
+	}
+	return DefaultEffort
+}
+
+// ItemEffort returns the estimated effort to finish an item: the sum of its
+// open todo ETAs, or DefaultEffort when it has none.
+func ItemEffort(openTodos []Todo) time.Duration {
+	if len(openTodos) == 0 {
+		return DefaultEffort
+	}
+	var total time.Duration
+	for _, t := range openTodos {
+		total += TodoEffort(t)
+	}
+	return total
+}
+
+// RankScore combines an item's signals into a single, time-dependent rank:
+//
+//   - match score (0-100), defaulting to 50 when unscored;
+//   - exponential age decay with a 14-day half-life, counted from creation or
+//     from the last restore out of the archive, whichever is later;
+//   - a logarithmic boost for items saved more than once;
+//   - a penalty for large remaining effort, so quick wins surface;
+//   - a boost for items updated within the last 48 hours.
+func RankScore(item *Item, effort time.Duration, now time.Time) float64 {
+	score := float64(rankDefaultScore)
+	if item.MatchScore != nil {
+		score = *item.MatchScore
+	}
+
+	anchor := parseRFC3339(item.CreatedAt)
+	if item.RestoredAt != nil {
+		if t := parseRFC3339(*item.RestoredAt); t.After(anchor) {
+			anchor = t
+		}
+	}
+	age := max(now.Sub(anchor), 0)
+	decay := math.Pow(0.5, float64(age)/float64(rankHalfLife))
+
+	saves := 1 + 0.3*math.Log(float64(max(item.SaveCount, 1)))
+	effortFactor := 1 / (1 + effort.Hours()/4)
+
+	recency := 1.0
+	if now.Sub(parseRFC3339(item.UpdatedAt)) < rankRecentWindow {
+		recency = rankRecentBoost
+	}
+
+	return score * decay * saves * effortFactor * recency
+}
+
+// NextItem is an item recommended by PlanNext, with the open todos that fit
+// into the time budget.
+type NextItem struct {
+	Item
+	Rank          float64 `json:"rank"`
+	EffortMinutes int     `json:"effort_minutes"` // planned minutes for this item
+	Todos         []Todo  `json:"todos"`
+}
+
+// NextPlan is the answer to "what should I do next with this much time".
+type NextPlan struct {
+	BudgetMinutes  int        `json:"budget_minutes"`
+	PlannedMinutes int        `json:"planned_minutes"`
+	Items          []NextItem `json:"items"`
+}
+
+// PlanNext ranks items and greedily fills budget with the best ones.
+// openTodos maps item IDs to their open todos in position order. An item with
+// open todos contributes the todos that still fit; an item without any is
+// planned as a DefaultEffort read. At most limit items are returned.
+func PlanNext(items []Item, openTodos map[string][]Todo, budget time.Duration, limit int, now time.Time) NextPlan {
+	type ranked struct {
+		item Item
+		rank float64
+	}
+	candidates := make([]ranked, len(items))
+	for i, item := range items {
+		candidates[i] = ranked{item: item, rank: RankScore(&item, ItemEffort(openTodos[item.ID]), now)}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].rank > candidates[j].rank })
+
+	plan := NextPlan{BudgetMinutes: int(budget.Minutes()), Items: []NextItem{}}
+	remaining := budget
+	for _, c := range candidates {
+		if len(plan.Items) >= limit || remaining <= 0 {
+			break
+		}
+
+		next := NextItem{Item: c.item, Rank: math.Round(c.rank*100) / 100, Todos: []Todo{}}
+		var planned time.Duration
+		if todos := openTodos[c.item.ID]; len(todos) > 0 {
+			for _, t := range todos {
+				if d := TodoEffort(t); d <= remaining-planned {
+					next.Todos = append(next.Todos, t)
+					planned += d
+				}
+			}
+		} else if DefaultEffort <= remaining {
+			planned = DefaultEffort
+		}
+		if planned == 0 {
+			continue
+		}
+
+		next.EffortMinutes = int(planned.Minutes())
+		remaining -= planned
+		plan.Items = append(plan.Items, next)
+	}
+	plan.PlannedMinutes = int((budget - remaining).Minutes())
+	return plan
+}
+
+// parseRFC3339 parses an RFC 3339 timestamp, returning the zero time on error.
+func parseRFC3339(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseETA(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"20m", 20 * time.Minute, true},
+		{"1h", time.Hour, true},
+		{"3h+", 3 * time.Hour, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseETA(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseETA(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func rankItem(score float64, created time.Time, saves int) Item {
+	ts := created.UTC().Format(time.RFC3339)
+	return Item{ID: ts, MatchScore: &score, SaveCount: saves, CreatedAt: ts, UpdatedAt: ts}
+}
+
+func TestRankScore(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// A 3-month-old top item no longer outranks yesterday's decent one.
+	old := rankItem(95, now.AddDate(0, -3, 0), 1)
+	fresh := rankItem(65, now.AddDate(0, 0, -1), 1)
+	if RankScore(&old, DefaultEffort, now) >= RankScore(&fresh, DefaultEffort, now) {
+		t.Error("old item should rank below a fresh one")
+	}
+
+	// Saving an item again boosts it.
+	once := rankItem(70, now.AddDate(0, 0, -5), 1)
+	thrice := rankItem(70, now.AddDate(0, 0, -5), 3)
+	if RankScore(&thrice, DefaultEffort, now) <= RankScore(&once, DefaultEffort, now) {
+		t.Error("save count should boost the rank")
+	}
+
+	// Less remaining effort ranks higher.
+	if RankScore(&once, 15*time.Minute, now) <= RankScore(&once, 3*time.Hour, now) {
+		t.Error("quick items should rank above long ones")
+	}
+
+	// A restore from the archive restarts the age decay.
+	restored := rankItem(70, now.AddDate(0, -2, 0), 1)
+	before := RankScore(&restored, DefaultEffort, now)
+	at := now.AddDate(0, 0, -3).Format(time.RFC3339)
+	restored.RestoredAt = &at
+	if RankScore(&restored, DefaultEffort, now) <= before {
+		t.Error("restoring should refresh the rank")
+	}
+
+	// Recently touched items get a boost.
+	touched := once
+	touched.UpdatedAt = now.Add(-time.Hour).Format(time.RFC3339)
+	if RankScore(&touched, DefaultEffort, now) <= RankScore(&once, DefaultEffort, now) {
+		t.Error("recent updates should boost the rank")
+	}
+}
+
+func TestPlanNext(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	best := rankItem(90, now.AddDate(0, 0, -1), 1)
+	best.ID = "best"
+	mid := rankItem(60, now.AddDate(0, 0, -1), 1)
+	mid.ID = "mid"
+	low := rankItem(40, now.AddDate(0, 0, -1), 1)
+	low.ID = "low"
+
+	todos := map[string][]Todo{
+		"best": {
+			{ID: "b1", ItemID: "best", ETA: "20m"},
+			{ID: "b2", ItemID: "best", ETA: "1h"},
+			{ID: "b3", ItemID: "best", ETA: "10m"},
+		},
+	}
+
+	plan := PlanNext([]Item{low, mid, best}, todos, 45*time.Minute, 5, now)
+	if plan.BudgetMinutes != 45 {
+		t.Errorf("budget = %d, want 45", plan.BudgetMinutes)
+	}
+	if len(plan.Items) != 2 || plan.Items[0].ID != "best" || plan.Items[1].ID != "mid" {
+		t.Fatalf("items = %+v, want best then mid", plan.Items)
+	}
+	// The 1h todo does not fit; the 20m and 10m ones do.
+	if got := plan.Items[0].Todos; len(got) != 2 || got[0].ID != "b1" || got[1].ID != "b3" {
+		t.Errorf("best todos = %+v, want b1 and b3", got)
+	}
+	if plan.Items[0].EffortMinutes != 30 || plan.Items[1].EffortMinutes != 15 {
+		t.Errorf("efforts = %d/%d, want 30/15", plan.Items[0].EffortMinutes, plan.Items[1].EffortMinutes)
+	}
+	if plan.PlannedMinutes != 45 {
+		t.Errorf("planned = %d, want 45", plan.PlannedMinutes)
+	}
+
+	if plan := PlanNext([]Item{low, mid, best}, todos, 2*time.Hour, 1, now); len(plan.Items) != 1 {
+		t.Errorf("limit 1 items = %d, want 1", len(plan.Items))
+	}
+}
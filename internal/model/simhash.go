@@ -0,0 +1,99 @@
+package model
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// NearDuplicateDistance is the largest Hamming distance between two
+// fingerprints for their texts to count as near-duplicates.
+const NearDuplicateDistance = 3
+
+// FingerprintBands is how many bands a fingerprint is split into for
+// lookup. Two fingerprints within NearDuplicateDistance bits agree on at
+// least one band, so candidates are found by exact band matches.
+const FingerprintBands = 4
+
+// minFingerprintTokens is the shortest text that gets a fingerprint; the
+// fingerprints of shorter texts collide too easily.
+const minFingerprintTokens = 50
+
+// shingleSize is how many consecutive tokens are hashed together.
+const shingleSize = 3
+
+// Fingerprint is a 64-bit SimHash of a text: texts that differ only in a
+// few words have fingerprints that differ in only a few bits.
+type Fingerprint uint64
+
+// SimHash fingerprints text from its overlapping three-token shingles.
+// Tokens are lower-cased words, or single characters in scripts written
+// without spaces, such as Chinese and Japanese. ok is false when the text
+// is too short for a reliable fingerprint.
+func SimHash(text string) (fp Fingerprint, ok bool) {
+	tokens := fingerprintTokens(text)
+	if len(tokens) < minFingerprintTokens {
+		return 0, false
+	}
+
+	var weights [64]int
+	h := fnv.New64a()
+	for i := 0; i+shingleSize <= len(tokens); i++ {
+		h.Reset()
+		h.Write([]byte(strings.Join(tokens[i:i+shingleSize], " ")))
+		sum := h.Sum64()
+		for b := range weights {
+			if sum&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+	for b, w := range weights {
+		if w > 0 {
+			fp |= 1 << b
+		}
+	}
+	return fp, true
+}
+
+// Distance returns the number of bits in which f and other differ.
+func (f Fingerprint) Distance(other Fingerprint) int {
+	return bits.OnesCount64(uint64(f ^ other))
+}
+
+// Band returns the i-th 16-bit band of the fingerprint.
+func (f Fingerprint) Band(i int) int64 {
+	return int64(uint64(f) >> (16 * i) & 0xffff)
+}
+
+func (f Fingerprint) String() string {
+	return fmt.Sprintf("%016x", uint64(f))
+}
+
+func fingerprintTokens(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const article = `Errors are values in Go. Values can be programmed, and since errors are values, errors can be programmed.
+A common interaction with an error value is to test whether it is nil, but there are countless other things one can do
+with an error value, and application of some of those other things can make your program better, eliminating much of the
+boilerplate that arises if every error is checked with a rote if statement. Here is a simple example from the bufio
+package's Scanner type. Its Scan method performs the underlying I/O, which can of course lead to an error.`
+
+// longArticle is article followed by enough distinct sentences to be the
+// length of a typical post.
+func longArticle() string {
+	var b strings.Builder
+	b.WriteString(article)
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&b, " In step %d the scanner reads token number %d and reports whether input %d remains.", i, i*7, i*13)
+	}
+	return b.String()
+}
+
+func TestSimHash(t *testing.T) {
+	text := longArticle()
+	base, ok := SimHash(text)
+	if !ok {
+		t.Fatal("article too short for a fingerprint")
+	}
+
+	// The same article republished with a different header and footer.
+	copy, _ := SimHash("Originally published on my blog.\n\n" + text + "\n\nSubscribe to the newsletter!")
+	if d := base.Distance(copy); d > NearDuplicateDistance {
+		t.Errorf("republished copy: distance = %d, want <= %d", d, NearDuplicateDistance)
+	}
+	// Case and punctuation do not matter.
+	if fp, _ := SimHash(strings.ToUpper(strings.ReplaceAll(text, ",", ";"))); fp != base {
+		t.Errorf("case/punctuation changed the fingerprint: distance = %d", base.Distance(fp))
+	}
+
+	other, _ := SimHash(strings.Repeat("Generics let functions and types work with any of a set of types. ", 8))
+	if d := base.Distance(other); d <= NearDuplicateDistance*3 {
+		t.Errorf("unrelated text: distance = %d, want far apart", d)
+	}
+
+	if _, ok := SimHash("Too short to tell."); ok {
+		t.Error("short text got a fingerprint")
+	}
+	// Chinese text is tokenized per character.
+	if _, ok := SimHash(strings.Repeat("错误也是值，可以像其他值一样编程处理。", 3)); !ok {
+		t.Error("Chinese text got no fingerprint")
+	}
+}
+
+func TestFingerprintBands(t *testing.T) {
+	fp := Fingerprint(0x1234_5678_9abc_def0)
+	want := []int64{0xdef0, 0x9abc, 0x5678, 0x1234}
+	for i := 0; i < FingerprintBands; i++ {
+		if got := fp.Band(i); got != want[i] {
+			t.Errorf("Band(%d) = %#x, want %#x", i, got, want[i])
+		}
+	}
+	if fp.String() != "123456789abcdef0" {
+		t.Errorf("String() = %s", fp)
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTagLength is the maximum number of runes in a tag name.
+const maxTagLength = 50
+
+// NormalizeTags trims, lower-cases and de-duplicates tag names, dropping empty ones.
+// Order of first appearance is preserved. It returns an error if any tag is too long.
+func NormalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		n = strings.ToLower(strings.Join(strings.Fields(n), " "))
+		if n == "" || seen[n] {
+			continue
+		}
+		if utf8.RuneCountInString(n) > maxTagLength {
+			return nil, fmt.Errorf("tag %q exceeds %d characters", n, maxTagLength)
+		}
+		seen[n] = true
+		out = append(out, n)
+	}
+	return out, nil
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{" Go ", "go", "", "System  Design", "架构"})
+	if err != nil {
+		t.Fatalf("NormalizeTags: %v", err)
+	}
+	want := []string{"go", "system design", "架构"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeTags = %v, want %v", got, want)
+	}
+
+	if _, err := NormalizeTags([]string{strings.Repeat("x", maxTagLength+1)}); err == nil {
+		t.Error("expected error for overlong tag")
+	}
+}
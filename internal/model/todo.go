@@ -0,0 +1,149 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Todo type constants (mirrors the types the todo step asks the model for).
+const (
+	TodoTypeRead  = "READ"
+	TodoTypeWrite = "WRITE"
+	TodoTypeBuild = "BUILD"
+	TodoTypeShare = "SHARE"
+)
+
+// Todo is a single actionable task belonging to an Item, tracked with completion state.
+type Todo struct {
+	ID          string  `json:"id"`
+	ItemID      string  `json:"item_id"`
+	Title       string  `json:"title"`
+	ETA         string  `json:"eta"`
+	Type        string  `json:"type"`
+	Position    int     `json:"position"`
+	Done        bool    `json:"done"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+	DueDate     *string `json:"due_date,omitempty"` // YYYY-MM-DD
+	Edited      bool    `json:"edited"`             // set once the user changes the todo; protects it on reprocess
+	CreatedBy   string  `json:"created_by"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+// NewTodo creates a new system-generated Todo at the given position.
+func NewTodo(id, itemID, title, eta, todoType string, position int) Todo {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return Todo{
+		ID:        id,
+		ItemID:    itemID,
+		Title:     title,
+		ETA:       eta,
+		Type:      todoType,
+		Position:  position,
+		CreatedBy: CreatedBySystem,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// TodoPatch holds the user-editable fields of a Todo. Nil fields are left unchanged.
+// An empty DueDate clears the due date.
+type TodoPatch struct {
+	Title    *string `json:"title"`
+	Done     *bool   `json:"done"`
+	DueDate  *string `json:"due_date"`
+	Position *int    `json:"position"`
+}
+
+// Apply validates the patch and applies it to the todo, maintaining completed_at
+// and marking the todo as user-edited.
+func (t *Todo) Apply(p TodoPatch) error {
+	if p.Title != nil {
+		title := strings.TrimSpace(*p.Title)
+		if title == "" {
+			return fmt.Errorf("title must not be empty")
+		}
+		t.Title = title
+	}
+	if p.DueDate != nil {
+		if *p.DueDate == "" {
+			t.DueDate = nil
+		} else {
+			if _, err := time.Parse(time.DateOnly, *p.DueDate); err != nil {
+				return fmt.Errorf("due_date must be YYYY-MM-DD")
+			}
+			due := *p.DueDate
+			t.DueDate = &due
+		}
+	}
+	if p.Position != nil {
+		if *p.Position < 0 {
+			return fmt.Errorf("position must not be negative")
+		}
+		t.Position = *p.Position
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if p.Done != nil && *p.Done != t.Done {
+		t.Done = *p.Done
+		if t.Done {
+			t.CompletedAt = &now
+		} else {
+			t.CompletedAt = nil
+		}
+	}
+	t.Edited = true
+	t.UpdatedAt = now
+	return nil
+}
+
+// TodoFilter holds query parameters for listing todos across items.
+type TodoFilter struct {
+	Type   []string
+	Done   *bool
+	ItemID string
+}
+
+// Todo sync sides: where a todo's done state was last changed.
+const (
+	SyncSideVault = "vault" // a checkbox in an exported Markdown note
+	SyncSideAPI   = "api"   // the API (or anything else writing to the database)
+)
+
+// TodoSync audits a todo whose done state disagreed between its vault note
+// and the database, and which side won.
+type TodoSync struct {
+	ID             string `json:"id"`
+	TodoID         string `json:"todo_id"`
+	ItemID         string `json:"item_id"`
+	File           string `json:"file"`
+	FileDone       bool   `json:"file_done"`
+	APIDone        bool   `json:"api_done"`
+	FileModifiedAt string `json:"file_modified_at"`
+	TodoUpdatedAt  string `json:"todo_updated_at"`
+	Winner         string `json:"winner"` // SyncSideVault or SyncSideAPI
+	CreatedAt      string `json:"created_at"`
+}
+
+// NewTodoSync resolves a disagreement between todo and the done state found
+// in its note, last writer wins: the note wins if it was modified after the
+// todo was last updated, otherwise the database keeps its value.
+func NewTodoSync(id string, t Todo, file string, fileDone bool, fileModified time.Time) TodoSync {
+	winner := SyncSideAPI
+	if updated, err := time.Parse(time.RFC3339, t.UpdatedAt); err != nil || fileModified.After(updated) {
+		winner = SyncSideVault
+	}
+	return TodoSync{
+		ID:             id,
+		TodoID:         t.ID,
+		ItemID:         t.ItemID,
+		File:           file,
+		FileDone:       fileDone,
+		APIDone:        t.Done,
+		FileModifiedAt: fileModified.UTC().Format(time.RFC3339),
+		TodoUpdatedAt:  t.UpdatedAt,
+		Winner:         winner,
+		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+	}
+}
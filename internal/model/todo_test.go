@@ -0,0 +1,97 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTodoApply(t *testing.T) {
+	done, undone := true, false
+	title, blank := "Write summary", "  "
+	due, badDue, clear := "2026-11-01", "next week", ""
+	neg := -1
+
+	tests := []struct {
+		name    string
+		patch   TodoPatch
+		wantErr bool
+		check   func(t *testing.T, td Todo)
+	}{
+		{"mark done sets completed_at", TodoPatch{Done: &done}, false, func(t *testing.T, td Todo) {
+			if !td.Done || td.CompletedAt == nil {
+				t.Errorf("done=%v completed_at=%v, want done with timestamp", td.Done, td.CompletedAt)
+			}
+		}},
+		{"rename", TodoPatch{Title: &title}, false, func(t *testing.T, td Todo) {
+			if td.Title != title {
+				t.Errorf("Title = %q, want %q", td.Title, title)
+			}
+		}},
+		{"set due date", TodoPatch{DueDate: &due}, false, func(t *testing.T, td Todo) {
+			if td.DueDate == nil || *td.DueDate != due {
+				t.Errorf("DueDate = %v, want %s", td.DueDate, due)
+			}
+		}},
+		{"clear due date", TodoPatch{DueDate: &clear}, false, func(t *testing.T, td Todo) {
+			if td.DueDate != nil {
+				t.Errorf("DueDate = %v, want nil", td.DueDate)
+			}
+		}},
+		{"blank title", TodoPatch{Title: &blank}, true, nil},
+		{"bad due date", TodoPatch{DueDate: &badDue}, true, nil},
+		{"negative position", TodoPatch{Position: &neg}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := NewTodo("t-1", "item-1", "Read", "20m", TodoTypeRead, 0)
+			err := td.Apply(tt.patch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !td.Edited {
+				t.Error("Edited should be set after Apply")
+			}
+			tt.check(t, td)
+		})
+	}
+
+	t.Run("undo clears completed_at", func(t *testing.T) {
+		td := NewTodo("t-1", "item-1", "Read", "20m", TodoTypeRead, 0)
+		td.Apply(TodoPatch{Done: &done})
+		td.Apply(TodoPatch{Done: &undone})
+		if td.Done || td.CompletedAt != nil {
+			t.Errorf("done=%v completed_at=%v, want undone without timestamp", td.Done, td.CompletedAt)
+		}
+	})
+}
+
+func TestNewTodoSync(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		updatedAt  string
+		modified   time.Time
+		wantWinner string
+	}{
+		{"file edited later", updated.Format(time.RFC3339), updated.Add(time.Minute), SyncSideVault},
+		{"api edited later", updated.Format(time.RFC3339), updated.Add(-time.Minute), SyncSideAPI},
+		{"same second keeps api", updated.Format(time.RFC3339), updated, SyncSideAPI},
+		{"unparseable updated_at", "", updated, SyncSideVault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := NewTodo("t-1", "item-1", "Read", "20m", TodoTypeRead, 0)
+			td.UpdatedAt = tt.updatedAt
+			rec := NewTodoSync("s-1", td, "note.md", true, tt.modified)
+			if rec.Winner != tt.wantWinner {
+				t.Errorf("Winner = %s, want %s", rec.Winner, tt.wantWinner)
+			}
+			if !rec.FileDone || rec.APIDone || rec.TodoID != "t-1" || rec.ItemID != "item-1" {
+				t.Errorf("record = %+v", rec)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// View is a named, persisted ItemFilter (a "smart list").
+// The filter is evaluated live every time the view is queried.
+type View struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Filter    ItemFilter `json:"filter"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+}
+
+// NewView creates a new View with the given name and filter.
+func NewView(id, name string, filter ItemFilter) View {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return View{
+		ID:        id,
+		Name:      name,
+		Filter:    filter,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate checks that the view has a name and a well-formed filter.
+func (v *View) Validate() error {
+	if strings.TrimSpace(v.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	return v.Filter.Validate()
+}
+
+// Validate checks that the filter's relative time window can be parsed.
+func (f ItemFilter) Validate() error {
+	if f.Within == "" {
+		return nil
+	}
+	if _, err := ParseWithin(f.Within); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseWithin parses a relative time window such as "24h", "90m" or "7d".
+// In addition to time.ParseDuration units, a "d" suffix means whole days.
+func ParseWithin(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid within %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid within %q", s)
+	}
+	return d, nil
+}
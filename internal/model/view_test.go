@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWithin(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"24h", 24 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"week", 0, true},
+		{"xd", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseWithin(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWithin(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWithin(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		view    View
+		wantErr bool
+	}{
+		{"valid", NewView("v-1", "Failed last 24h", ItemFilter{Status: []string{StatusFailed}, Within: "24h"}), false},
+		{"empty filter is valid", NewView("v-1", "Everything", ItemFilter{}), false},
+		{"missing name", NewView("v-1", "  ", ItemFilter{}), true},
+		{"bad within", NewView("v-1", "Bad", ItemFilter{Within: "soon"}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.view.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// WebhookAllEvents subscribes a webhook to every event type.
+const WebhookAllEvents = "*"
+
+// Webhook delivery states.
+const (
+	DeliveryPending   = "pending"   // waiting for its first or next attempt
+	DeliveryDelivered = "delivered" // the endpoint answered 2xx
+	DeliveryFailed    = "failed"    // gave up after WebhookMaxAttempts
+)
+
+// Webhook delivery retry policy: attempt n (1-based) that fails is retried
+// after webhookBaseBackoff·2^(n-1), capped at webhookMaxBackoff.
+const (
+	WebhookMaxAttempts = 8
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = time.Hour
+)
+
+// Webhook is an outbound subscription to item lifecycle events.
+// Deliveries are signed with Secret, which is never serialized.
+type Webhook struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"` // event types, or ["*"] for all
+	Secret    string   `json:"-"`
+	Enabled   bool     `json:"enabled"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// NewWebhook creates a new, enabled Webhook.
+func NewWebhook(id, rawURL string, events []string, secret string) Webhook {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return Webhook{
+		ID:        id,
+		URL:       rawURL,
+		Events:    events,
+		Secret:    secret,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate checks the webhook's URL, event filter and secret.
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	if u.User != nil {
+		return fmt.Errorf("url must not contain credentials")
+	}
+	if len(w.Events) == 0 {
+		return fmt.Errorf("events is required")
+	}
+	for _, e := range w.Events {
+		if e != WebhookAllEvents && !IsEventType(e) {
+			return fmt.Errorf("invalid event %q", e)
+		}
+	}
+	if w.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+	return nil
+}
+
+// Matches reports whether the webhook is subscribed to eventType.
+func (w *Webhook) Matches(eventType string) bool {
+	for _, e := range w.Events {
+		if e == WebhookAllEvents || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook. Item is nil when the
+// item no longer exists (e.g. item.deleted); Synthesis and Todos hold the
+// item's artifacts of those types, if any.
+type WebhookPayload struct {
+	Event     Event           `json:"event"`
+	Item      *Item           `json:"item"`
+	Synthesis json.RawMessage `json:"synthesis,omitempty"`
+	Todos     json.RawMessage `json:"todos,omitempty"`
+}
+
+// NewWebhookPayload builds the payload for e from the item's current state.
+// item may be nil.
+func NewWebhookPayload(e Event, item *ItemWithArtifacts) WebhookPayload {
+	p := WebhookPayload{Event: e}
+	if item == nil {
+		return p
+	}
+	p.Item = &item.Item
+	for _, a := range item.Artifacts {
+		switch a.ArtifactType {
+		case ArtifactSynthesis:
+			p.Synthesis = json.RawMessage(a.Payload)
+		case ArtifactTodos:
+			p.Todos = json.RawMessage(a.Payload)
+		}
+	}
+	return p
+}
+
+// WebhookDelivery is one event queued for, or delivered to, one webhook.
+// Pending deliveries form the outbox; all of them form the delivery log.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	EventType      string          `json:"event_type"`
+	ItemID         string          `json:"item_id"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	CreatedAt      string          `json:"created_at"`
+	UpdatedAt      string          `json:"updated_at"`
+	DeliveredAt    *string         `json:"delivered_at"`
+}
+
+// NewWebhookDelivery creates a pending delivery, due immediately.
+func NewWebhookDelivery(id, webhookID string, e Event, payload json.RawMessage) WebhookDelivery {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return WebhookDelivery{
+		ID:            id,
+		WebhookID:     webhookID,
+		EventType:     e.Type,
+		ItemID:        e.ItemID,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// RecordAttempt updates the delivery with the outcome of an attempt made at
+// now. statusCode is 0 if no response was received; an empty errMsg means the
+// attempt succeeded. Failed attempts are rescheduled with exponential backoff
+// until WebhookMaxAttempts is reached.
+func (d *WebhookDelivery) RecordAttempt(statusCode int, errMsg string, now time.Time) {
+	ts := now.UTC().Format(time.RFC3339)
+	d.Attempts++
+	d.UpdatedAt = ts
+	d.LastStatusCode = nil
+	if statusCode != 0 {
+		d.LastStatusCode = &statusCode
+	}
+
+	if errMsg == "" {
+		d.Status = DeliveryDelivered
+		d.LastError = nil
+		d.DeliveredAt = &ts
+		return
+	}
+
+	d.LastError = &errMsg
+	if d.Attempts >= WebhookMaxAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+	backoff := min(webhookBaseBackoff<<(d.Attempts-1), webhookMaxBackoff)
+	d.NextAttemptAt = now.Add(backoff).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Webhook
+		wantErr bool
+	}{
+		{"valid", NewWebhook("w", "https://example.com/hook", []string{EventItemReady, EventItemFailed}, "s3cret"), false},
+		{"all events", NewWebhook("w", "http://localhost:9000/hook", []string{WebhookAllEvents}, "s3cret"), false},
+
+		{"relative url", NewWebhook("w", "/hook", []string{EventItemReady}, "s3cret"), true},
+		{"bad scheme", NewWebhook("w", "ftp://example.com/hook", []string{EventItemReady}, "s3cret"), true},
+		{"credentials in url", NewWebhook("w", "https://user:pw@example.com/hook", []string{EventItemReady}, "s3cret"), true},
+		{"no events", NewWebhook("w", "https://example.com/hook", nil, "s3cret"), true},
+		{"unknown event", NewWebhook("w", "https://example.com/hook", []string{"item.exploded"}, "s3cret"), true},
+		{"no secret", NewWebhook("w", "https://example.com/hook", []string{EventItemReady}, ""), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	h := NewWebhook("w", "https://example.com", []string{EventItemReady, EventItemFailed}, "s")
+	if !h.Matches(EventItemReady) || !h.Matches(EventItemFailed) {
+		t.Error("expected ready and failed to match")
+	}
+	if h.Matches(EventItemCaptured) {
+		t.Error("captured should not match")
+	}
+	all := NewWebhook("w", "https://example.com", []string{WebhookAllEvents}, "s")
+	if !all.Matches(EventItemDeleted) {
+		t.Error("* should match every event")
+	}
+}
+
+func TestNewWebhookPayload(t *testing.T) {
+	e := StatusEvent("item-1", StatusReady)
+	item := &ItemWithArtifacts{
+		Item: Item{ID: "item-1", URL: "https://example.com"},
+		Artifacts: []Artifact{
+			NewArtifact("a1", "item-1", ArtifactExtraction, `{"text":"long"}`),
+			NewArtifact("a2", "item-1", ArtifactSynthesis, `{"headline":"H"}`),
+			NewArtifact("a3", "item-1", ArtifactTodos, `{"todos":[]}`),
+		},
+	}
+
+	p := NewWebhookPayload(e, item)
+	if p.Item == nil || p.Item.ID != "item-1" {
+		t.Fatalf("Item = %+v, want item-1", p.Item)
+	}
+	if string(p.Synthesis) != `{"headline":"H"}` || string(p.Todos) != `{"todos":[]}` {
+		t.Errorf("Synthesis = %s, Todos = %s", p.Synthesis, p.Todos)
+	}
+
+	deleted := NewWebhookPayload(NewEvent(EventItemDeleted, "item-1"), nil)
+	b, err := json.Marshal(deleted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	json.Unmarshal(b, &got)
+	if got["item"] != nil || got["synthesis"] != nil {
+		t.Errorf("deleted payload = %s, want null item and no artifacts", b)
+	}
+}
+
+func TestWebhookDeliveryRecordAttempt(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	d := NewWebhookDelivery("d", "w", StatusEvent("item-1", StatusReady), json.RawMessage(`{}`))
+
+	d.RecordAttempt(500, "unexpected status 500", now)
+	if d.Status != DeliveryPending || d.Attempts != 1 {
+		t.Fatalf("after failure: status=%s attempts=%d, want pending/1", d.Status, d.Attempts)
+	}
+	if want := now.Add(30 * time.Second).Format(time.RFC3339); d.NextAttemptAt != want {
+		t.Errorf("NextAttemptAt = %s, want %s", d.NextAttemptAt, want)
+	}
+	if d.LastStatusCode == nil || *d.LastStatusCode != 500 || d.LastError == nil {
+		t.Errorf("last status/error not recorded: %v %v", d.LastStatusCode, d.LastError)
+	}
+
+	d.RecordAttempt(0, "connection refused", now)
+	if want := now.Add(time.Minute).Format(time.RFC3339); d.NextAttemptAt != want {
+		t.Errorf("second backoff: NextAttemptAt = %s, want %s", d.NextAttemptAt, want)
+	}
+	if d.LastStatusCode != nil {
+		t.Errorf("LastStatusCode = %d, want nil without a response", *d.LastStatusCode)
+	}
+
+	d.RecordAttempt(204, "", now)
+	if d.Status != DeliveryDelivered || d.DeliveredAt == nil || d.LastError != nil {
+		t.Errorf("after success: %+v", d)
+	}
+
+	failing := NewWebhookDelivery("d2", "w", StatusEvent("item-1", StatusReady), json.RawMessage(`{}`))
+	for i := 0; i < WebhookMaxAttempts; i++ {
+		failing.RecordAttempt(503, "unexpected status 503", now)
+	}
+	if failing.Status != DeliveryFailed {
+		t.Errorf("after %d failures: status = %s, want failed", WebhookMaxAttempts, failing.Status)
+	}
+}
@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros maps the supported shorthand specs to their five-field form.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxCronYears bounds the search in Next so an impossible spec such as
+// "0 0 30 2 *" terminates.
+const maxCronYears = 5
+
+// Cron is a parsed five-field cron expression:
+// minute hour day-of-month month day-of-week.
+type Cron struct {
+	minute, hour, dom, month, dow uint64 // bit i set = value i allowed
+	domAny, dowAny                bool
+}
+
+// ParseCron parses a standard five-field cron expression or one of the
+// @hourly/@daily/@weekly/@monthly/@yearly macros. Each field accepts "*",
+// single values, ranges ("1-5"), lists ("1,15") and steps ("*/10", "0-30/5").
+// Day-of-week is 0-6 with Sunday as 0 (7 is accepted as Sunday too).
+func ParseCron(spec string) (*Cron, error) {
+	spec = strings.TrimSpace(spec)
+	if m, ok := cronMacros[spec]; ok {
+		spec = m
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	var c Cron
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron %q: minute: %w", spec, err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron %q: hour: %w", spec, err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron %q: day of month: %w", spec, err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron %q: month: %w", spec, err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron %q: day of week: %w", spec, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is an alias for Sunday
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return &c, nil
+}
+
+// parseCronField parses one comma-separated cron field into a bit set.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = cronValue(a, min, max); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(b, min, max); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := cronValue(rng, min, max)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, min, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, min, max)
+	}
+	return v, nil
+}
+
+// Next returns the first matching time strictly after t, in t's location.
+// It returns the zero time if nothing matches within the next few years.
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxCronYears
+
+	for t.Year() <= limit {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the usual cron rule: when both day-of-month and
+// day-of-week are restricted, a day matching either one is enough.
+func (c *Cron) dayMatches(t time.Time) bool {
+	domOK := c.dom&(1<<uint(t.Day())) != 0
+	dowOK := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@reboot",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) should fail", spec)
+		}
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	// 2026-03-04 is a Wednesday.
+	from := time.Date(2026, 3, 4, 10, 17, 42, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 4, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"30 3 * * *", time.Date(2026, 3, 5, 3, 30, 0, 0, time.UTC)},
+		{"0 9-17 * * *", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1-5", time.Date(2026, 3, 5, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 0", time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 7", time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		// Day-of-month OR day-of-week when both are restricted: the 10th or a Friday.
+		{"0 0 10 * 5", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := ParseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCron: %v", err)
+			}
+			if got := c.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCron_NextImpossible(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	if got := c.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next = %v, want zero time", got)
+	}
+}
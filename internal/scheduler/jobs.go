@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// Built-in job names.
+const (
+	JobSnoozeWake  = "snooze-wake"
+	JobDBOptimize  = "db-optimize"
+	JobAutoArchive = "auto-archive"
+)
+
+// SnoozeWaker returns snoozed items to READY once their deadline has passed.
+type SnoozeWaker interface {
+	WakeSnoozedItems(ctx context.Context, now time.Time) ([]string, error)
+}
+
+// ArchiveRuleRunner lists auto-archive rules and applies them.
+type ArchiveRuleRunner interface {
+	ListArchiveRules(ctx context.Context) ([]model.ArchiveRule, error)
+	ApplyArchiveRule(ctx context.Context, r model.ArchiveRule, now time.Time) ([]string, error)
+}
+
+// EventPublisher receives item lifecycle events.
+type EventPublisher interface {
+	Publish(e model.Event)
+}
+
+// publishStatus announces that items moved to status, if pub is set.
+func publishStatus(pub EventPublisher, ids []string, status string) {
+	if pub == nil {
+		return
+	}
+	for _, id := range ids {
+		pub.Publish(model.StatusEvent(id, status))
+	}
+}
+
+// Optimizer refreshes database statistics.
+type Optimizer interface {
+	Optimize(ctx context.Context) error
+}
+
+// WakeSnoozedJob returns a job that wakes snoozed items that have come due
+// and publishes their status change to pub, which may be nil.
+func WakeSnoozedJob(w SnoozeWaker, pub EventPublisher, schedule string) Job {
+	return Job{
+		Name:     JobSnoozeWake,
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			ids, err := w.WakeSnoozedItems(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if len(ids) > 0 {
+				slog.Info("woke snoozed items", "count", len(ids))
+			}
+			publishStatus(pub, ids, model.StatusReady)
+			return nil
+		},
+	}
+}
+
+// OptimizeDBJob returns a job that runs database maintenance.
+func OptimizeDBJob(o Optimizer, schedule string) Job {
+	return Job{Name: JobDBOptimize, Schedule: schedule, Run: o.Optimize}
+}
+
+// AutoArchiveJob returns a job that applies every enabled auto-archive rule
+// and publishes each archived item to pub, which may be nil. A failing rule
+// does not stop the others; their errors are joined.
+func AutoArchiveJob(a ArchiveRuleRunner, pub EventPublisher, schedule string) Job {
+	return Job{
+		Name:     JobAutoArchive,
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			rules, err := a.ListArchiveRules(ctx)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			var errs []error
+			for _, r := range rules {
+				if !r.Enabled {
+					continue
+				}
+				ids, err := a.ApplyArchiveRule(ctx, r, now)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("rule %s: %w", r.ID, err))
+					continue
+				}
+				if len(ids) > 0 {
+					slog.Info("auto-archived items", "rule", r.Name, "count", len(ids))
+				}
+				publishStatus(pub, ids, model.StatusArchived)
+			}
+			return errors.Join(errs...)
+		},
+	}
+}
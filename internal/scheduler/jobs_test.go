@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// recordingPublisher records published events.
+type recordingPublisher struct {
+	events []model.Event
+}
+
+func (p *recordingPublisher) Publish(e model.Event) { p.events = append(p.events, e) }
+
+type fakeWaker struct{ ids []string }
+
+func (f *fakeWaker) WakeSnoozedItems(_ context.Context, _ time.Time) ([]string, error) {
+	return f.ids, nil
+}
+
+// fakeRules archives the items listed for each rule, or fails the rule.
+type fakeRules struct {
+	rules    []model.ArchiveRule
+	archived map[string][]string
+}
+
+func (f *fakeRules) ListArchiveRules(_ context.Context) ([]model.ArchiveRule, error) {
+	return f.rules, nil
+}
+
+func (f *fakeRules) ApplyArchiveRule(_ context.Context, r model.ArchiveRule, _ time.Time) ([]string, error) {
+	ids, ok := f.archived[r.ID]
+	if !ok {
+		return nil, errors.New("boom")
+	}
+	return ids, nil
+}
+
+func TestWakeSnoozedJob_PublishesWokenItems(t *testing.T) {
+	pub := &recordingPublisher{}
+	job := WakeSnoozedJob(&fakeWaker{ids: []string{"a", "b"}}, pub, "* * * * *")
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.events) != 2 || pub.events[1].ItemID != "b" || pub.events[1].Status != model.StatusReady {
+		t.Errorf("events = %+v, want READY for a and b", pub.events)
+	}
+
+	// Publishing is optional.
+	if err := WakeSnoozedJob(&fakeWaker{ids: []string{"a"}}, nil, "* * * * *").Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoArchiveJob_PublishesArchivedItems(t *testing.T) {
+	pub := &recordingPublisher{}
+	rules := &fakeRules{
+		rules: []model.ArchiveRule{
+			{ID: "r1", Enabled: true},
+			{ID: "r2", Enabled: true},
+			{ID: "off", Enabled: false},
+		},
+		archived: map[string][]string{"r1": {"a"}, "off": {"never"}},
+	}
+	err := AutoArchiveJob(rules, pub, "* * * * *").Run(context.Background())
+	if err == nil {
+		t.Error("failing rule: err = nil")
+	}
+	if len(pub.events) != 1 || pub.events[0].ItemID != "a" || pub.events[0].Type != model.EventItemArchived {
+		t.Errorf("events = %+v, want one archived event for a", pub.events)
+	}
+}
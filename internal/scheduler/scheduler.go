@@ -0,0 +1,249 @@
+// Package scheduler runs recurring maintenance jobs inside the server on
+// cron schedules. Run state is persisted through a JobStore so that each
+// scheduled run fires once, even across restarts.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// maxWait caps how long the loop sleeps between checks, so that schedule
+// changes made by another process are picked up reasonably quickly.
+const maxWait = time.Minute
+
+// Errors returned by Trigger.
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+// JobStore persists job schedules and run outcomes.
+type JobStore interface {
+	RegisterJob(ctx context.Context, name, schedule, nextRunAt string) error
+	GetJob(ctx context.Context, name string) (*model.Job, error)
+	ListJobs(ctx context.Context) ([]model.Job, error)
+	ClaimJobRun(ctx context.Context, name, dueAt, nextRunAt string) (bool, error)
+	FinishJobRun(ctx context.Context, name, startedAt string, durationMS int64, errMsg *string) error
+}
+
+// Job is a named recurring task.
+type Job struct {
+	Name     string
+	Schedule string // cron expression, see ParseCron
+	Run      func(ctx context.Context) error
+}
+
+type entry struct {
+	job  Job
+	cron *Cron
+}
+
+// Scheduler fires registered jobs when they come due.
+type Scheduler struct {
+	store JobStore
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	order   []string
+	running map[string]bool
+	ctx     context.Context // set by Start; used for manual triggers
+	wg      sync.WaitGroup
+}
+
+// New creates a Scheduler backed by store.
+func New(store JobStore) *Scheduler {
+	return &Scheduler{
+		store:   store,
+		now:     time.Now,
+		entries: map[string]*entry{},
+		running: map[string]bool{},
+		ctx:     context.Background(),
+	}
+}
+
+// Register adds a job. It must be called before Start.
+func (s *Scheduler) Register(job Job) error {
+	c, err := ParseCron(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if c.Next(s.now()).IsZero() {
+		return fmt.Errorf("job %s: schedule %q never fires", job.Name, job.Schedule)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("job %s: already registered", job.Name)
+	}
+	s.entries[job.Name] = &entry{job: job, cron: c}
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Start persists the registered jobs and runs the scheduling loop.
+// It blocks until ctx is cancelled and in-flight runs have finished.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+	s.persist(ctx)
+
+	slog.Info("scheduler started", "jobs", len(s.order))
+	for {
+		wait := s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			slog.Info("scheduler stopped")
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// persist records every registered job in the store.
+func (s *Scheduler) persist(ctx context.Context) {
+	now := s.now()
+	for _, name := range s.order {
+		e := s.entries[name]
+		next := formatTime(e.cron.Next(now))
+		if err := s.store.RegisterJob(ctx, name, e.job.Schedule, next); err != nil {
+			slog.Error("register job failed", "job", name, "error", err)
+		}
+	}
+}
+
+// tick fires every due job and returns how long to wait before the next check.
+func (s *Scheduler) tick(ctx context.Context) time.Duration {
+	now := s.now()
+	wait := maxWait
+
+	for _, name := range s.order {
+		e := s.entries[name]
+		state, err := s.store.GetJob(ctx, name)
+		if err != nil {
+			slog.Error("load job state failed", "job", name, "error", err)
+			continue
+		}
+		dueAt, err := time.Parse(time.RFC3339, state.NextRunAt)
+		if err != nil {
+			slog.Error("invalid job next_run_at", "job", name, "next_run_at", state.NextRunAt)
+			continue
+		}
+
+		if !now.Before(dueAt) {
+			next := e.cron.Next(now)
+			claimed, err := s.store.ClaimJobRun(ctx, name, state.NextRunAt, formatTime(next))
+			if err != nil {
+				slog.Error("claim job failed", "job", name, "error", err)
+				continue
+			}
+			if claimed {
+				s.start(e)
+			}
+			dueAt = next
+		}
+		if d := dueAt.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	return max(wait, time.Second)
+}
+
+// Trigger runs a job immediately in the background, outside its schedule.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	if !s.start(e) {
+		return ErrJobRunning
+	}
+	return nil
+}
+
+// Jobs returns the persisted state of every registered job, in registration order.
+func (s *Scheduler) Jobs(ctx context.Context) ([]model.Job, error) {
+	rows, err := s.store.ListJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]model.Job, len(rows))
+	for _, j := range rows {
+		byName[j.Name] = j
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]model.Job, 0, len(s.order))
+	for _, name := range s.order {
+		j, ok := byName[name]
+		if !ok {
+			continue
+		}
+		j.Running = s.running[name]
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// start launches a run unless the job is already running. It reports whether
+// a run was started.
+func (s *Scheduler) start(e *entry) bool {
+	s.mu.Lock()
+	if s.running[e.job.Name] {
+		s.mu.Unlock()
+		slog.Warn("job still running, skipping run", "job", e.job.Name)
+		return false
+	}
+	s.running[e.job.Name] = true
+	ctx := s.ctx
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, e.job.Name)
+			s.mu.Unlock()
+		}()
+		s.run(ctx, e.job)
+	}()
+	return true
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	started := s.now()
+	slog.Info("job started", "job", job.Name)
+	err := job.Run(ctx)
+	elapsed := s.now().Sub(started)
+
+	var errMsg *string
+	if err != nil {
+		msg := err.Error()
+		errMsg = &msg
+		slog.Error("job failed", "job", job.Name, "duration", elapsed.String(), "error", err)
+	} else {
+		slog.Info("job finished", "job", job.Name, "duration", elapsed.String())
+	}
+
+	// Record the outcome even if ctx was cancelled while the job ran.
+	if fErr := s.store.FinishJobRun(context.WithoutCancel(ctx), job.Name, formatTime(started), elapsed.Milliseconds(), errMsg); fErr != nil {
+		slog.Error("record job run failed", "job", job.Name, "error", fErr)
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
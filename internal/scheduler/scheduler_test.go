@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// memJobStore is an in-memory JobStore.
+type memJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*model.Job
+}
+
+func newMemJobStore() *memJobStore {
+	return &memJobStore{jobs: map[string]*model.Job{}}
+}
+
+func (m *memJobStore) RegisterJob(_ context.Context, name, schedule, nextRunAt string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if j, ok := m.jobs[name]; ok && j.Schedule == schedule {
+		return nil
+	}
+	m.jobs[name] = &model.Job{Name: name, Schedule: schedule, NextRunAt: nextRunAt}
+	return nil
+}
+
+func (m *memJobStore) GetJob(_ context.Context, name string) (*model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[name]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	cp := *j
+	return &cp, nil
+}
+
+func (m *memJobStore) ListJobs(_ context.Context) ([]model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []model.Job
+	for _, j := range m.jobs {
+		out = append(out, *j)
+	}
+	return out, nil
+}
+
+func (m *memJobStore) ClaimJobRun(_ context.Context, name, dueAt, nextRunAt string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[name]
+	if !ok || j.NextRunAt != dueAt {
+		return false, nil
+	}
+	j.NextRunAt = nextRunAt
+	return true, nil
+}
+
+func (m *memJobStore) FinishJobRun(_ context.Context, name, startedAt string, durationMS int64, errMsg *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := m.jobs[name]
+	j.LastRunAt = &startedAt
+	j.LastError = errMsg
+	j.RunCount++
+	return nil
+}
+
+func newTestScheduler(store JobStore, now *time.Time, jobs ...Job) *Scheduler {
+	s := New(store)
+	s.now = func() time.Time { return *now }
+	for _, j := range jobs {
+		if err := s.Register(j); err != nil {
+			panic(err)
+		}
+	}
+	s.persist(context.Background())
+	return s
+}
+
+func TestScheduler_FiresWhenDue(t *testing.T) {
+	store := newMemJobStore()
+	now := time.Date(2026, 3, 4, 10, 0, 30, 0, time.UTC)
+	var runs atomic.Int32
+	s := newTestScheduler(store, &now, Job{Name: "tick", Schedule: "* * * * *", Run: func(context.Context) error {
+		runs.Add(1)
+		return errors.New("boom")
+	}})
+	ctx := context.Background()
+
+	if wait := s.tick(ctx); wait != 30*time.Second {
+		t.Errorf("wait = %v, want 30s until the next minute", wait)
+	}
+	s.wg.Wait()
+	if runs.Load() != 0 {
+		t.Fatalf("runs = %d before due, want 0", runs.Load())
+	}
+
+	now = now.Add(35 * time.Second) // 10:01:05
+	s.tick(ctx)
+	s.tick(ctx)
+	s.wg.Wait()
+	if runs.Load() != 1 {
+		t.Fatalf("runs = %d, want 1", runs.Load())
+	}
+
+	j, _ := store.GetJob(ctx, "tick")
+	if j.NextRunAt != "2026-03-04T10:02:00Z" {
+		t.Errorf("next_run_at = %s, want 2026-03-04T10:02:00Z", j.NextRunAt)
+	}
+	if j.RunCount != 1 || j.LastError == nil || *j.LastError != "boom" {
+		t.Errorf("job state = %+v, want one failed run", j)
+	}
+}
+
+func TestScheduler_SharedStoreFiresOnce(t *testing.T) {
+	store := newMemJobStore()
+	now := time.Date(2026, 3, 4, 10, 0, 30, 0, time.UTC)
+	var runs atomic.Int32
+	job := Job{Name: "tick", Schedule: "* * * * *", Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}
+	// Two schedulers (e.g. before and after a restart) share the persisted state.
+	a := newTestScheduler(store, &now, job)
+	b := newTestScheduler(store, &now, job)
+
+	now = now.Add(time.Minute)
+	a.tick(context.Background())
+	b.tick(context.Background())
+	a.wg.Wait()
+	b.wg.Wait()
+	if runs.Load() != 1 {
+		t.Errorf("runs = %d, want 1", runs.Load())
+	}
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	store := newMemJobStore()
+	now := time.Now()
+	release := make(chan struct{})
+	s := newTestScheduler(store, &now, Job{Name: "slow", Schedule: "@daily", Run: func(context.Context) error {
+		<-release
+		return nil
+	}})
+
+	if err := s.Trigger("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Trigger(missing) = %v, want ErrJobNotFound", err)
+	}
+	if err := s.Trigger("slow"); err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	if err := s.Trigger("slow"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("second Trigger = %v, want ErrJobRunning", err)
+	}
+
+	jobs, err := s.Jobs(context.Background())
+	if err != nil {
+		t.Fatalf("Jobs: %v", err)
+	}
+	if len(jobs) != 1 || !jobs[0].Running {
+		t.Errorf("jobs = %+v, want one running job", jobs)
+	}
+
+	close(release)
+	s.wg.Wait()
+	j, _ := store.GetJob(context.Background(), "slow")
+	if j.RunCount != 1 {
+		t.Errorf("run_count = %d, want 1", j.RunCount)
+	}
+}
+
+func TestScheduler_RegisterInvalid(t *testing.T) {
+	s := New(newMemJobStore())
+	if err := s.Register(Job{Name: "bad", Schedule: "not a cron"}); err == nil {
+		t.Error("Register with invalid schedule should fail")
+	}
+	if err := s.Register(Job{Name: "never", Schedule: "0 0 31 2 *"}); err == nil {
+		t.Error("Register with a schedule that never fires should fail")
+	}
+	s.Register(Job{Name: "dup", Schedule: "@daily"})
+	if err := s.Register(Job{Name: "dup", Schedule: "@daily"}); err == nil {
+		t.Error("Register duplicate should fail")
+	}
+}
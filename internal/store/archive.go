@@ -0,0 +1,284 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ErrUndoConflict is returned when an auto-archive was already undone or the
+// item has left the archive since.
+var ErrUndoConflict = errors.New("auto-archive can no longer be undone")
+
+// maxAutoArchives caps how many auto-archive log entries are listed.
+const maxAutoArchives = 200
+
+// archiveBasisColumns maps a rule basis to the item timestamp it measures.
+var archiveBasisColumns = map[string]string{
+	model.ArchiveBasisCreated: "created_at",
+	model.ArchiveBasisUpdated: "updated_at",
+}
+
+const archiveRuleColumns = `id, name, priorities, basis, days, enabled, created_at, updated_at`
+
+// CreateArchiveRule inserts a new auto-archive rule.
+func (s *Store) CreateArchiveRule(ctx context.Context, r model.ArchiveRule) error {
+	priorities, err := json.Marshal(r.Priorities)
+	if err != nil {
+		return fmt.Errorf("marshal rule priorities: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO archive_rules (`+archiveRuleColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.Name, string(priorities), r.Basis, r.Days, r.Enabled, r.CreatedAt, r.UpdatedAt,
+	)
+	return err
+}
+
+// GetArchiveRule returns a rule by ID. It returns sql.ErrNoRows if not found.
+func (s *Store) GetArchiveRule(ctx context.Context, id string) (*model.ArchiveRule, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+archiveRuleColumns+` FROM archive_rules WHERE id = ?`, id)
+	return scanArchiveRule(row)
+}
+
+// ListArchiveRules returns all auto-archive rules ordered by name.
+func (s *Store) ListArchiveRules(ctx context.Context) ([]model.ArchiveRule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+archiveRuleColumns+` FROM archive_rules ORDER BY name ASC, created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []model.ArchiveRule
+	for rows.Next() {
+		r, err := scanArchiveRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *r)
+	}
+	return rules, rows.Err()
+}
+
+// UpdateArchiveRule replaces a rule's settings.
+// It returns sql.ErrNoRows if the rule does not exist.
+func (s *Store) UpdateArchiveRule(ctx context.Context, r model.ArchiveRule) error {
+	priorities, err := json.Marshal(r.Priorities)
+	if err != nil {
+		return fmt.Errorf("marshal rule priorities: %w", err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE archive_rules SET name = ?, priorities = ?, basis = ?, days = ?, enabled = ?, updated_at = ? WHERE id = ?`,
+		r.Name, string(priorities), r.Basis, r.Days, r.Enabled, now, r.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// DeleteArchiveRule removes a rule. Its auto-archive log entries are kept.
+// It returns sql.ErrNoRows if the rule does not exist.
+func (s *Store) DeleteArchiveRule(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM archive_rules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// MatchArchiveRule returns the items the rule would archive at now,
+// without changing anything (dry run).
+func (s *Store) MatchArchiveRule(ctx context.Context, r model.ArchiveRule, now time.Time) ([]model.Item, error) {
+	where, args, err := archiveRuleWhere(r, now)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+itemColumns+` FROM items WHERE `+where+` ORDER BY created_at ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.Item{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// ApplyArchiveRule archives every item the rule matches at now and logs each
+// one with the rule's reason. It returns the IDs of the items archived.
+func (s *Store) ApplyArchiveRule(ctx context.Context, r model.ArchiveRule, now time.Time) ([]string, error) {
+	where, args, err := archiveRuleWhere(r, now)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, status FROM items WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	type match struct{ id, status string }
+	var matches []match
+	for rows.Next() {
+		var m match
+		if err := rows.Scan(&m.id, &m.status); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ts := now.UTC().Format(time.RFC3339)
+	reason := r.Reason()
+	for _, m := range matches {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE items SET status = ?, updated_at = ? WHERE id = ?`,
+			model.StatusArchived, ts, m.id,
+		); err != nil {
+			return nil, fmt.Errorf("archive item: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO auto_archives (id, item_id, rule_id, reason, previous_status, archived_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			uuid.New().String(), m.id, r.ID, reason, m.status, ts,
+		); err != nil {
+			return nil, fmt.Errorf("log auto-archive: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.id
+	}
+	return ids, nil
+}
+
+// ListAutoArchives returns the most recent auto-archive log entries, newest first.
+func (s *Store) ListAutoArchives(ctx context.Context) ([]model.AutoArchive, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, item_id, rule_id, reason, previous_status, archived_at, undone_at
+		FROM auto_archives ORDER BY archived_at DESC, id ASC LIMIT ?`, maxAutoArchives)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.AutoArchive{}
+	for rows.Next() {
+		a, err := scanAutoArchive(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *a)
+	}
+	return entries, rows.Err()
+}
+
+// UndoAutoArchive returns an auto-archived item to its previous status and
+// marks it as restored, so rules leave it alone from then on.
+// It returns sql.ErrNoRows if the entry does not exist and ErrUndoConflict if
+// it was already undone or the item is no longer archived.
+func (s *Store) UndoAutoArchive(ctx context.Context, id string) (*model.AutoArchive, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	a, err := scanAutoArchive(tx.QueryRowContext(ctx, `
+		SELECT id, item_id, rule_id, reason, previous_status, archived_at, undone_at
+		FROM auto_archives WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	if a.UndoneAt != nil {
+		return nil, ErrUndoConflict
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := tx.ExecContext(ctx,
+		`UPDATE items SET status = ?, restored_at = ?, updated_at = ? WHERE id = ? AND status = ?`,
+		a.PreviousStatus, now, now, a.ItemID, model.StatusArchived,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("restore item: %w", err)
+	}
+	if err := requireAffected(res); errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUndoConflict
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE auto_archives SET undone_at = ? WHERE id = ?`, now, id); err != nil {
+		return nil, fmt.Errorf("mark undone: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	a.UndoneAt = &now
+	return a, nil
+}
+
+// archiveRuleWhere builds the WHERE clause (without the keyword) selecting
+// READY items the rule applies to: matching priority, older than the cutoff
+// and never restored from the archive by the user.
+func archiveRuleWhere(r model.ArchiveRule, now time.Time) (string, []interface{}, error) {
+	col, ok := archiveBasisColumns[r.Basis]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid basis %q", r.Basis)
+	}
+	if len(r.Priorities) == 0 {
+		return "", nil, fmt.Errorf("rule has no priorities")
+	}
+	args := []interface{}{model.StatusReady}
+	for _, p := range r.Priorities {
+		args = append(args, p)
+	}
+	args = append(args, r.Cutoff(now).UTC().Format(time.RFC3339))
+	where := fmt.Sprintf(`status = ? AND restored_at IS NULL AND priority IN (%s) AND %s <= ?`, placeholders(len(r.Priorities)), col)
+	return where, args, nil
+}
+
+func scanArchiveRule(row scanner) (*model.ArchiveRule, error) {
+	var r model.ArchiveRule
+	var priorities string
+	if err := row.Scan(&r.ID, &r.Name, &priorities, &r.Basis, &r.Days, &r.Enabled, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(priorities), &r.Priorities); err != nil {
+		return nil, fmt.Errorf("unmarshal rule priorities: %w", err)
+	}
+	return &r, nil
+}
+
+func scanAutoArchive(row scanner) (*model.AutoArchive, error) {
+	var a model.AutoArchive
+	if err := row.Scan(&a.ID, &a.ItemID, &a.RuleID, &a.Reason, &a.PreviousStatus, &a.ArchivedAt, &a.UndoneAt); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// createAgedItem inserts a READY item with the given priority, created and
+// last updated daysAgo days before now.
+func createAgedItem(t *testing.T, s *Store, id, priority string, daysAgo int) {
+	t.Helper()
+	item := makeItem(id, "https://example.com/"+id)
+	item.Status = model.StatusReady
+	item.Priority = &priority
+	ts := time.Now().AddDate(0, 0, -daysAgo).UTC().Format(time.RFC3339)
+	item.CreatedAt, item.UpdatedAt = ts, ts
+	if err := s.CreateItem(context.Background(), item); err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+}
+
+func TestArchiveRulesCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	r := model.NewArchiveRule("rule-1", "Let go", []string{model.PriorityLetGo}, model.ArchiveBasisCreated, 7)
+	if err := s.CreateArchiveRule(ctx, r); err != nil {
+		t.Fatalf("CreateArchiveRule: %v", err)
+	}
+
+	r.Days = 14
+	r.Enabled = false
+	if err := s.UpdateArchiveRule(ctx, r); err != nil {
+		t.Fatalf("UpdateArchiveRule: %v", err)
+	}
+	got, err := s.GetArchiveRule(ctx, "rule-1")
+	if err != nil {
+		t.Fatalf("GetArchiveRule: %v", err)
+	}
+	if got.Days != 14 || got.Enabled || len(got.Priorities) != 1 {
+		t.Errorf("rule = %+v, want 14 days, disabled", got)
+	}
+
+	if err := s.DeleteArchiveRule(ctx, "rule-1"); err != nil {
+		t.Fatalf("DeleteArchiveRule: %v", err)
+	}
+	if err := s.DeleteArchiveRule(ctx, "rule-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second delete error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestApplyArchiveRule(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	createAgedItem(t, s, "old-letgo", model.PriorityLetGo, 10)
+	createAgedItem(t, s, "new-letgo", model.PriorityLetGo, 2)
+	createAgedItem(t, s, "old-dofirst", model.PriorityDoFirst, 10)
+	createAgedItem(t, s, "restored", model.PriorityLetGo, 10)
+	s.UpdateItemStatus(ctx, "restored", model.StatusArchived, nil)
+	s.UpdateItemStatus(ctx, "restored", model.StatusReady, nil)
+
+	r := model.NewArchiveRule("rule-1", "Let go", []string{model.PriorityLetGo}, model.ArchiveBasisCreated, 7)
+	now := time.Now()
+
+	matched, err := s.MatchArchiveRule(ctx, r, now)
+	if err != nil {
+		t.Fatalf("MatchArchiveRule: %v", err)
+	}
+	if len(matched) != 1 || matched[0].ID != "old-letgo" {
+		t.Fatalf("dry run = %v, want only old-letgo", matched)
+	}
+	// A dry run changes nothing.
+	if got, _ := s.GetItem(ctx, "old-letgo"); got.Status != model.StatusReady {
+		t.Errorf("status after dry run = %s, want READY", got.Status)
+	}
+
+	ids, err := s.ApplyArchiveRule(ctx, r, now)
+	if err != nil {
+		t.Fatalf("ApplyArchiveRule: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "old-letgo" {
+		t.Errorf("archived = %v, want [old-letgo]", ids)
+	}
+	if got, _ := s.GetItem(ctx, "old-letgo"); got.Status != model.StatusArchived {
+		t.Errorf("status = %s, want ARCHIVED", got.Status)
+	}
+	if got, _ := s.GetItem(ctx, "restored"); got.Status != model.StatusReady || got.RestoredAt == nil {
+		t.Errorf("restored item = %s (restored_at %v), want untouched READY", got.Status, got.RestoredAt)
+	}
+
+	log, err := s.ListAutoArchives(ctx)
+	if err != nil {
+		t.Fatalf("ListAutoArchives: %v", err)
+	}
+	if len(log) != 1 || log[0].ItemID != "old-letgo" || log[0].Reason != r.Reason() {
+		t.Fatalf("log = %+v, want one entry for old-letgo", log)
+	}
+
+	// Undo restores the item and protects it from the rule.
+	undone, err := s.UndoAutoArchive(ctx, log[0].ID)
+	if err != nil {
+		t.Fatalf("UndoAutoArchive: %v", err)
+	}
+	if undone.UndoneAt == nil {
+		t.Error("undone_at should be set")
+	}
+	if got, _ := s.GetItem(ctx, "old-letgo"); got.Status != model.StatusReady || got.RestoredAt == nil {
+		t.Errorf("after undo = %s (restored_at %v), want READY and restored", got.Status, got.RestoredAt)
+	}
+	if ids, _ := s.ApplyArchiveRule(ctx, r, now); len(ids) != 0 {
+		t.Errorf("re-apply archived = %v, want none", ids)
+	}
+
+	if _, err := s.UndoAutoArchive(ctx, log[0].ID); !errors.Is(err, ErrUndoConflict) {
+		t.Errorf("second undo error = %v, want ErrUndoConflict", err)
+	}
+	if _, err := s.UndoAutoArchive(ctx, "missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("undo missing error = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,291 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// Artifacts
+// ---------------------------------------------------------------------------
+
+// artifactColumns is the column list matching scanArtifact, shared by the
+// artifacts, artifact_proposals and artifact_versions tables.
+const artifactColumns = `id, item_id, artifact_type, payload, created_by, created_at, model, prompt_version, version`
+
+func scanArtifact(row scanner) (*model.Artifact, error) {
+	var a model.Artifact
+	err := row.Scan(&a.ID, &a.ItemID, &a.ArtifactType, &a.Payload, &a.CreatedBy, &a.CreatedAt, &a.Model, &a.PromptVersion, &a.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// UpsertArtifact inserts or replaces an artifact (one per item per type),
+// and appends it to the artifact's history. A system-generated artifact
+// does not replace one the user has edited: it is kept as the artifact's
+// proposal instead, replacing any earlier proposal, for the user to accept
+// or reject. A todos artifact written by the user is also synced into the
+// todos table.
+func (s *Store) UpsertArtifact(ctx context.Context, a model.Artifact) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := appendVersionTx(ctx, tx, &a); err != nil {
+		return err
+	}
+
+	if a.CreatedBy != model.CreatedByUser {
+		var createdBy string
+		err := tx.QueryRowContext(ctx,
+			`SELECT created_by FROM artifacts WHERE item_id = ? AND artifact_type = ?`, a.ItemID, a.ArtifactType,
+		).Scan(&createdBy)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("read artifact: %w", err)
+		}
+		if createdBy == model.CreatedByUser {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO artifact_proposals (id, item_id, artifact_type, payload, created_at, model, prompt_version, version)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT(item_id, artifact_type) DO UPDATE SET
+					id = excluded.id,
+					payload = excluded.payload,
+					created_at = excluded.created_at,
+					model = excluded.model,
+					prompt_version = excluded.prompt_version,
+					version = excluded.version`,
+				a.ID, a.ItemID, a.ArtifactType, a.Payload, a.CreatedAt, a.Model, a.PromptVersion, a.Version,
+			); err != nil {
+				return fmt.Errorf("save artifact proposal: %w", err)
+			}
+			return tx.Commit()
+		}
+	}
+
+	if err := upsertArtifactTx(ctx, tx, a); err != nil {
+		return err
+	}
+	// Generated todos are synced by the pipeline, which owns their IDs.
+	if a.ArtifactType == model.ArtifactTodos && a.CreatedBy == model.CreatedByUser {
+		if err := syncArtifactTodosTx(ctx, tx, a); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func upsertArtifactTx(ctx context.Context, tx *sql.Tx, a model.Artifact) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO artifacts (`+artifactColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(item_id, artifact_type) DO UPDATE SET
+			id = excluded.id,
+			payload = excluded.payload,
+			created_by = excluded.created_by,
+			created_at = excluded.created_at,
+			model = excluded.model,
+			prompt_version = excluded.prompt_version,
+			version = excluded.version`,
+		a.ID, a.ItemID, a.ArtifactType, a.Payload, a.CreatedBy, a.CreatedAt, a.Model, a.PromptVersion, a.Version,
+	)
+	return err
+}
+
+// appendVersionTx records a as the next version in its history and sets
+// a.Version, if artifacts of its type have a history.
+func appendVersionTx(ctx context.Context, tx *sql.Tx, a *model.Artifact) error {
+	if !model.HasHistory(a.ArtifactType) {
+		return nil
+	}
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(version), 0) + 1 FROM artifact_versions WHERE item_id = ? AND artifact_type = ?`,
+		a.ItemID, a.ArtifactType,
+	).Scan(&a.Version); err != nil {
+		return fmt.Errorf("next artifact version: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO artifact_versions (`+artifactColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.ItemID, a.ArtifactType, a.Payload, a.CreatedBy, a.CreatedAt, a.Model, a.PromptVersion, a.Version,
+	); err != nil {
+		return fmt.Errorf("append artifact version: %w", err)
+	}
+	return nil
+}
+
+// GetArtifact returns an item's current artifact of the given type.
+func (s *Store) GetArtifact(ctx context.Context, itemID, artifactType string) (*model.Artifact, error) {
+	return scanArtifact(s.db.QueryRowContext(ctx,
+		`SELECT `+artifactColumns+` FROM artifacts WHERE item_id = ? AND artifact_type = ?`, itemID, artifactType))
+}
+
+func (s *Store) listArtifacts(ctx context.Context, itemID string) ([]model.Artifact, error) {
+	return s.queryArtifacts(ctx, `SELECT `+artifactColumns+` FROM artifacts WHERE item_id = ? ORDER BY created_at ASC`, itemID)
+}
+
+func (s *Store) queryArtifacts(ctx context.Context, query string, args ...any) ([]model.Artifact, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var artifacts []model.Artifact
+	for rows.Next() {
+		a, err := scanArtifact(rows)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, *a)
+	}
+	return artifacts, rows.Err()
+}
+
+// ---------------------------------------------------------------------------
+// Artifact history
+// ---------------------------------------------------------------------------
+
+// ListArtifactVersions returns every version of an item's artifact, newest
+// first.
+func (s *Store) ListArtifactVersions(ctx context.Context, itemID, artifactType string) ([]model.Artifact, error) {
+	return s.queryArtifacts(ctx,
+		`SELECT `+artifactColumns+` FROM artifact_versions WHERE item_id = ? AND artifact_type = ? ORDER BY version DESC`,
+		itemID, artifactType)
+}
+
+// GetArtifactVersion returns one version of an item's artifact. It returns
+// sql.ErrNoRows if there is no such version.
+func (s *Store) GetArtifactVersion(ctx context.Context, itemID, artifactType string, version int) (*model.Artifact, error) {
+	return scanArtifact(s.db.QueryRowContext(ctx,
+		`SELECT `+artifactColumns+` FROM artifact_versions WHERE item_id = ? AND artifact_type = ? AND version = ?`,
+		itemID, artifactType, version))
+}
+
+// RevertArtifact makes an earlier version of an artifact current again. The
+// restored content is appended to the history as a new version by the
+// user, so later generations are proposed rather than applied, and the
+// history itself is never rewritten. It returns sql.ErrNoRows if there is
+// no such version.
+func (s *Store) RevertArtifact(ctx context.Context, itemID, artifactType string, version int) (*model.Artifact, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	old, err := scanArtifact(tx.QueryRowContext(ctx,
+		`SELECT `+artifactColumns+` FROM artifact_versions WHERE item_id = ? AND artifact_type = ? AND version = ?`,
+		itemID, artifactType, version))
+	if err != nil {
+		return nil, err
+	}
+	a := *old
+	a.ID = uuid.New().String()
+	a.CreatedBy = model.CreatedByUser
+	a.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := appendVersionTx(ctx, tx, &a); err != nil {
+		return nil, err
+	}
+	if err := upsertArtifactTx(ctx, tx, a); err != nil {
+		return nil, fmt.Errorf("save artifact: %w", err)
+	}
+	if a.ArtifactType == model.ArtifactTodos {
+		if err := syncArtifactTodosTx(ctx, tx, a); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ---------------------------------------------------------------------------
+// Artifact proposals
+// ---------------------------------------------------------------------------
+
+const proposalColumns = `id, item_id, artifact_type, payload, created_at, model, prompt_version, version`
+
+func scanProposal(row scanner) (*model.Artifact, error) {
+	a := model.Artifact{CreatedBy: model.CreatedBySystem}
+	if err := row.Scan(&a.ID, &a.ItemID, &a.ArtifactType, &a.Payload, &a.CreatedAt, &a.Model, &a.PromptVersion, &a.Version); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetArtifactProposal returns the pending system-generated version of an
+// item's user-edited artifact. It returns sql.ErrNoRows if there is none.
+func (s *Store) GetArtifactProposal(ctx context.Context, itemID, artifactType string) (*model.Artifact, error) {
+	return scanProposal(s.db.QueryRowContext(ctx,
+		`SELECT `+proposalColumns+` FROM artifact_proposals WHERE item_id = ? AND artifact_type = ?`, itemID, artifactType))
+}
+
+func (s *Store) listArtifactProposals(ctx context.Context, itemID string) ([]model.Artifact, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+proposalColumns+` FROM artifact_proposals WHERE item_id = ? ORDER BY artifact_type`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var proposals []model.Artifact
+	for rows.Next() {
+		a, err := scanProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, *a)
+	}
+	return proposals, rows.Err()
+}
+
+// AcceptArtifactProposal replaces the user's version of an artifact with its
+// pending system-generated version and returns the new artifact. It returns
+// sql.ErrNoRows if there is no proposal.
+func (s *Store) AcceptArtifactProposal(ctx context.Context, itemID, artifactType string) (*model.Artifact, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	a, err := scanProposal(tx.QueryRowContext(ctx,
+		`SELECT `+proposalColumns+` FROM artifact_proposals WHERE item_id = ? AND artifact_type = ?`, itemID, artifactType))
+	if err != nil {
+		return nil, err
+	}
+	if err := upsertArtifactTx(ctx, tx, *a); err != nil {
+		return nil, fmt.Errorf("save artifact: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM artifact_proposals WHERE id = ?`, a.ID); err != nil {
+		return nil, fmt.Errorf("delete proposal: %w", err)
+	}
+	if a.ArtifactType == model.ArtifactTodos {
+		if err := syncArtifactTodosTx(ctx, tx, *a); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// RejectArtifactProposal discards the pending system-generated version of an
+// artifact, keeping the user's. It returns sql.ErrNoRows if there is none.
+func (s *Store) RejectArtifactProposal(ctx context.Context, itemID, artifactType string) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM artifact_proposals WHERE item_id = ? AND artifact_type = ?`, itemID, artifactType)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestArtifactProposals(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://example.com/1"))
+
+	s.UpsertArtifact(ctx, model.NewArtifact("gen-1", "item-1", model.ArtifactSynthesis, `{"insight":"generated"}`))
+	edit := model.NewArtifact("edit-1", "item-1", model.ArtifactSynthesis, `{"insight":"mine"}`)
+	edit.CreatedBy = model.CreatedByUser
+	if err := s.UpsertArtifact(ctx, edit); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reprocessing keeps the user's version and proposes the new one.
+	for _, id := range []string{"gen-2", "gen-3"} {
+		if err := s.UpsertArtifact(ctx, model.NewArtifact(id, "item-1", model.ArtifactSynthesis, `{"insight":"`+id+`"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _ := s.GetItem(ctx, "item-1")
+	if len(got.Artifacts) != 1 || got.Artifacts[0].ID != "edit-1" {
+		t.Fatalf("artifacts = %+v, want the user's edit", got.Artifacts)
+	}
+	if len(got.Proposals) != 1 || got.Proposals[0].ID != "gen-3" || got.Proposals[0].CreatedBy != model.CreatedBySystem {
+		t.Fatalf("proposals = %+v, want the latest generation", got.Proposals)
+	}
+
+	if err := s.RejectArtifactProposal(ctx, "item-1", model.ArtifactSynthesis); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetArtifactProposal(ctx, "item-1", model.ArtifactSynthesis); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("proposal after reject: %v", err)
+	}
+	if a, _ := s.GetArtifact(ctx, "item-1", model.ArtifactSynthesis); a.ID != "edit-1" {
+		t.Errorf("artifact after reject = %s, want edit-1", a.ID)
+	}
+
+	s.UpsertArtifact(ctx, model.NewArtifact("gen-4", "item-1", model.ArtifactSynthesis, `{"insight":"gen-4"}`))
+	a, err := s.AcceptArtifactProposal(ctx, "item-1", model.ArtifactSynthesis)
+	if err != nil || a.ID != "gen-4" {
+		t.Fatalf("AcceptArtifactProposal = %+v, %v", a, err)
+	}
+	if a, _ := s.GetArtifact(ctx, "item-1", model.ArtifactSynthesis); a.ID != "gen-4" || a.CreatedBy != model.CreatedBySystem {
+		t.Errorf("artifact after accept = %+v", a)
+	}
+	// Once accepted, the next generation replaces the artifact directly.
+	s.UpsertArtifact(ctx, model.NewArtifact("gen-5", "item-1", model.ArtifactSynthesis, `{"insight":"gen-5"}`))
+	if a, _ := s.GetArtifact(ctx, "item-1", model.ArtifactSynthesis); a.ID != "gen-5" {
+		t.Errorf("artifact = %s, want gen-5", a.ID)
+	}
+	if _, err := s.AcceptArtifactProposal(ctx, "item-1", model.ArtifactSynthesis); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("accept without proposal = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestArtifactHistory(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://example.com/1"))
+
+	gen := model.NewArtifact("gen-1", "item-1", model.ArtifactSynthesis, `{"insight":"generated"}`)
+	gen.Model, gen.PromptVersion = "gpt-4o-mini", "1"
+	s.UpsertArtifact(ctx, gen)
+	edit := model.NewArtifact("edit-1", "item-1", model.ArtifactSynthesis, `{"insight":"mine"}`)
+	edit.CreatedBy = model.CreatedByUser
+	s.UpsertArtifact(ctx, edit)
+	// A generation over the edit is proposed, but still recorded.
+	s.UpsertArtifact(ctx, model.NewArtifact("gen-2", "item-1", model.ArtifactSynthesis, `{"insight":"regenerated"}`))
+	// Extractions have no history.
+	s.UpsertArtifact(ctx, model.NewArtifact("ext-1", "item-1", model.ArtifactExtraction, `{}`))
+
+	versions, err := s.ListArtifactVersions(ctx, "item-1", model.ArtifactSynthesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, v := range versions {
+		ids = append(ids, v.ID)
+	}
+	if len(versions) != 3 || versions[0].Version != 3 || ids[0] != "gen-2" || ids[1] != "edit-1" || ids[2] != "gen-1" {
+		t.Fatalf("versions = %v, want newest first", ids)
+	}
+	if v := versions[2]; v.Model != "gpt-4o-mini" || v.PromptVersion != "1" || v.CreatedBy != model.CreatedBySystem {
+		t.Errorf("version 1 = %+v, want model and prompt version recorded", v)
+	}
+	if a, _ := s.GetArtifact(ctx, "item-1", model.ArtifactSynthesis); a.Version != 2 {
+		t.Errorf("current version = %d, want 2", a.Version)
+	}
+	if p, _ := s.GetArtifactProposal(ctx, "item-1", model.ArtifactSynthesis); p.Version != 3 {
+		t.Errorf("proposed version = %d, want 3", p.Version)
+	}
+	if v, _ := s.ListArtifactVersions(ctx, "item-1", model.ArtifactExtraction); len(v) != 0 {
+		t.Errorf("extraction versions = %d, want none", len(v))
+	}
+
+	a, err := s.RevertArtifact(ctx, "item-1", model.ArtifactSynthesis, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Version != 4 || a.Payload != gen.Payload || a.CreatedBy != model.CreatedByUser || a.Model != "gpt-4o-mini" {
+		t.Errorf("reverted = %+v", a)
+	}
+	if cur, _ := s.GetArtifact(ctx, "item-1", model.ArtifactSynthesis); cur.ID != a.ID || cur.Version != 4 {
+		t.Errorf("current after revert = %+v", cur)
+	}
+	if v, err := s.GetArtifactVersion(ctx, "item-1", model.ArtifactSynthesis, 4); err != nil || v.Payload != gen.Payload {
+		t.Errorf("GetArtifactVersion(4) = %+v, %v", v, err)
+	}
+	if _, err := s.RevertArtifact(ctx, "item-1", model.ArtifactSynthesis, 9); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("revert to missing version = %v, want sql.ErrNoRows", err)
+	}
+
+	if err := s.DeleteItem(ctx, "item-1"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.ListArtifactVersions(ctx, "item-1", model.ArtifactSynthesis); len(v) != 0 {
+		t.Errorf("versions after delete = %d, want none", len(v))
+	}
+}
+
+func TestArtifactTodos_SyncTable(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://example.com/1"))
+	s.UpsertArtifact(ctx, model.NewArtifact("gen-1", "item-1", model.ArtifactTodos, `{"todos":[{"title":"Read"},{"title":"Write"}]}`))
+	s.SyncGeneratedTodos(ctx, "item-1", []model.Todo{
+		model.NewTodo("t-1", "item-1", "Read", "10m", model.TodoTypeRead, 0),
+		model.NewTodo("t-2", "item-1", "Write", "20m", model.TodoTypeWrite, 1),
+	})
+	// The user renames the READ todo directly.
+	title := "Read carefully"
+	todo, _ := s.GetTodo(ctx, "t-1")
+	todo.Apply(model.TodoPatch{Title: &title})
+	s.UpdateTodo(ctx, *todo)
+
+	titles := func() []string {
+		todos, _ := s.ListTodos(ctx, model.TodoFilter{ItemID: "item-1"})
+		var out []string
+		for _, td := range todos {
+			out = append(out, td.Title)
+		}
+		return out
+	}
+
+	edit := model.NewArtifact("edit-1", "item-1", model.ArtifactTodos, `{"todos":[{"title":"Share","done":true}]}`)
+	edit.CreatedBy = model.CreatedByUser
+	if err := s.UpsertArtifact(ctx, edit); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(); len(got) != 2 || got[0] != "Read carefully" || got[1] != "Share" {
+		t.Errorf("todos after edit = %v, want the edited todo kept and the artifact's", got)
+	}
+	if open, _ := s.ListTodos(ctx, model.TodoFilter{ItemID: "item-1", Done: new(bool)}); len(open) != 1 {
+		t.Errorf("open todos = %d, want 1", len(open))
+	}
+	// Done in the artifact is carried over, but is not a direct edit.
+	todos, _ := s.ListTodos(ctx, model.TodoFilter{ItemID: "item-1"})
+	if share := todos[1]; !share.Done || share.CompletedAt == nil || share.Edited {
+		t.Errorf("Share todo = %+v, want done and not edited", share)
+	}
+
+	// Reverting replaces the artifact's done todo rather than keeping it.
+	if _, err := s.RevertArtifact(ctx, "item-1", model.ArtifactTodos, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(); len(got) != 3 || got[1] != "Read" || got[2] != "Write" {
+		t.Errorf("todos after revert = %v, want the edited todo and version 1's", got)
+	}
+
+	// A generation over the user's version changes nothing until accepted.
+	s.UpsertArtifact(ctx, model.NewArtifact("gen-2", "item-1", model.ArtifactTodos, `{"todos":[{"title":"Plan"}]}`))
+	if got := titles(); len(got) != 3 {
+		t.Errorf("todos with pending proposal = %v", got)
+	}
+	if _, err := s.AcceptArtifactProposal(ctx, "item-1", model.ArtifactTodos); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(); len(got) != 2 || got[0] != "Read carefully" || got[1] != "Plan" {
+		t.Errorf("todos after accept = %v", got)
+	}
+}
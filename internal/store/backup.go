@@ -0,0 +1,441 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// Portable archive format identifiers, written in the archive header.
+const (
+	ArchiveFormat  = "readdo-jsonl"
+	ArchiveVersion = 2 // v2 adds artifact histories and proposals
+)
+
+// historyArchiveVersion is the first archive version that carries artifact
+// histories.
+const historyArchiveVersion = 2
+
+// Archive record types.
+const (
+	recordHeader   = "header"
+	recordItem     = "item"
+	recordIntent   = "intent"
+	recordArtifact = "artifact"
+	recordVersion  = "artifact_version"
+	recordProposal = "artifact_proposal"
+	recordTodo     = "todo"
+	recordTag      = "tag"
+)
+
+var (
+	// ErrRestoreNotEmpty is returned when restoring into a database that
+	// already has items.
+	ErrRestoreNotEmpty = errors.New("restore target already has items")
+	// ErrArchiveIncompatible is returned for archives that are not readdo
+	// archives or were written by a newer schema.
+	ErrArchiveIncompatible = errors.New("incompatible archive")
+)
+
+// ArchiveHeader is the first line of a portable archive.
+type ArchiveHeader struct {
+	Format        string `json:"format"`
+	Version       int    `json:"version"`
+	SchemaVersion int    `json:"schema_version"`
+	ExportedAt    string `json:"exported_at"`
+}
+
+// ArchiveSummary counts the records written to or restored from an archive.
+type ArchiveSummary struct {
+	Items            int `json:"items"`
+	Intents          int `json:"intents"`
+	Artifacts        int `json:"artifacts"`
+	ArtifactVersions int `json:"artifact_versions"`
+	Proposals        int `json:"artifact_proposals"`
+	Todos            int `json:"todos"`
+	Tags             int `json:"tags"`
+}
+
+// archiveRecord is one line of an archive: a type tag and its data.
+type archiveRecord struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// archiveTag is an item tag as stored in item_tags.
+type archiveTag struct {
+	ItemID    string `json:"item_id"`
+	Tag       string `json:"tag"`
+	Source    string `json:"source"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Backup writes a consistent snapshot of the whole database to path with
+// VACUUM INTO. It is safe while the database is in use; path must not exist.
+func (s *Store) Backup(ctx context.Context, path string) error {
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("vacuum into: %w", err)
+	}
+	return nil
+}
+
+// ExportArchive writes items with their intents, artifacts (with their
+// histories and pending proposals), todos and tags to w as JSON lines: a
+// header, then each item followed by its records. All rows are read in one
+// transaction, so the archive is consistent.
+func (s *Store) ExportArchive(ctx context.Context, w io.Writer) (ArchiveSummary, error) {
+	var sum ArchiveSummary
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return sum, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	enc := json.NewEncoder(w)
+	write := func(typ string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(archiveRecord{Type: typ, Data: data})
+	}
+
+	if err := write(recordHeader, ArchiveHeader{
+		Format:        ArchiveFormat,
+		Version:       ArchiveVersion,
+		SchemaVersion: currentSchemaVersion,
+		ExportedAt:    time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		return sum, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+itemColumns+` FROM items ORDER BY created_at ASC, id ASC`)
+	if err != nil {
+		return sum, fmt.Errorf("read items: %w", err)
+	}
+	var items []model.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			rows.Close()
+			return sum, err
+		}
+		items = append(items, *item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return sum, err
+	}
+
+	for _, item := range items {
+		if err := write(recordItem, item); err != nil {
+			return sum, err
+		}
+		sum.Items++
+
+		intents, err := exportRows(ctx, tx, `SELECT id, item_id, text, created_at FROM intents WHERE item_id = ? ORDER BY created_at ASC, id ASC`, item.ID,
+			func(r *sql.Rows) (any, error) {
+				var in model.Intent
+				err := r.Scan(&in.ID, &in.ItemID, &in.Text, &in.CreatedAt)
+				return in, err
+			})
+		if err != nil {
+			return sum, fmt.Errorf("read intents: %w", err)
+		}
+		artifacts, err := exportRows(ctx, tx, `SELECT `+artifactColumns+` FROM artifacts WHERE item_id = ? ORDER BY artifact_type ASC`, item.ID,
+			func(r *sql.Rows) (any, error) {
+				a, err := scanArtifact(r)
+				if err != nil {
+					return nil, err
+				}
+				return *a, nil
+			})
+		if err != nil {
+			return sum, fmt.Errorf("read artifacts: %w", err)
+		}
+		versions, err := exportRows(ctx, tx, `SELECT `+artifactColumns+` FROM artifact_versions WHERE item_id = ? ORDER BY artifact_type ASC, version ASC`, item.ID,
+			func(r *sql.Rows) (any, error) {
+				a, err := scanArtifact(r)
+				if err != nil {
+					return nil, err
+				}
+				return *a, nil
+			})
+		if err != nil {
+			return sum, fmt.Errorf("read artifact versions: %w", err)
+		}
+		proposals, err := exportRows(ctx, tx, `SELECT `+proposalColumns+` FROM artifact_proposals WHERE item_id = ? ORDER BY artifact_type ASC`, item.ID,
+			func(r *sql.Rows) (any, error) {
+				a, err := scanProposal(r)
+				if err != nil {
+					return nil, err
+				}
+				return *a, nil
+			})
+		if err != nil {
+			return sum, fmt.Errorf("read artifact proposals: %w", err)
+		}
+		todos, err := exportRows(ctx, tx, `SELECT `+todoColumns+` FROM todos WHERE item_id = ? ORDER BY position ASC, created_at ASC`, item.ID,
+			func(r *sql.Rows) (any, error) {
+				t, err := scanTodo(r)
+				if err != nil {
+					return nil, err
+				}
+				return *t, nil
+			})
+		if err != nil {
+			return sum, fmt.Errorf("read todos: %w", err)
+		}
+		tags, err := exportRows(ctx, tx, `SELECT item_id, tag, source, created_at FROM item_tags WHERE item_id = ? ORDER BY tag ASC`, item.ID,
+			func(r *sql.Rows) (any, error) {
+				var t archiveTag
+				err := r.Scan(&t.ItemID, &t.Tag, &t.Source, &t.CreatedAt)
+				return t, err
+			})
+		if err != nil {
+			return sum, fmt.Errorf("read tags: %w", err)
+		}
+
+		for _, group := range []struct {
+			typ     string
+			records []any
+			count   *int
+		}{
+			{recordIntent, intents, &sum.Intents},
+			{recordArtifact, artifacts, &sum.Artifacts},
+			{recordVersion, versions, &sum.ArtifactVersions},
+			{recordProposal, proposals, &sum.Proposals},
+			{recordTodo, todos, &sum.Todos},
+			{recordTag, tags, &sum.Tags},
+		} {
+			for _, rec := range group.records {
+				if err := write(group.typ, rec); err != nil {
+					return sum, err
+				}
+				*group.count++
+			}
+		}
+	}
+	return sum, nil
+}
+
+// exportRows runs a per-item query and scans every row with scan.
+func exportRows(ctx context.Context, tx *sql.Tx, query, itemID string, scan func(*sql.Rows) (any, error)) ([]any, error) {
+	rows, err := tx.QueryContext(ctx, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []any
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// RestoreArchive loads an archive written by ExportArchive into an empty
+// database, keeping every ID and artifact version number. The archive must
+// come from the same or an older schema version. An archive from before
+// artifact histories were exported starts each artifact's history at its
+// current version. Nothing is written unless the whole archive loads.
+func (s *Store) RestoreArchive(ctx context.Context, r io.Reader) (ArchiveSummary, error) {
+	var sum ArchiveSummary
+	dec := json.NewDecoder(r)
+
+	var rec archiveRecord
+	if err := dec.Decode(&rec); err != nil || rec.Type != recordHeader {
+		return sum, fmt.Errorf("%w: missing header", ErrArchiveIncompatible)
+	}
+	var header ArchiveHeader
+	if err := json.Unmarshal(rec.Data, &header); err != nil {
+		return sum, fmt.Errorf("%w: invalid header: %v", ErrArchiveIncompatible, err)
+	}
+	if header.Format != ArchiveFormat || header.Version < 1 || header.Version > ArchiveVersion {
+		return sum, fmt.Errorf("%w: format %q version %d, want %q version 1 to %d",
+			ErrArchiveIncompatible, header.Format, header.Version, ArchiveFormat, ArchiveVersion)
+	}
+	if header.SchemaVersion > currentSchemaVersion {
+		return sum, fmt.Errorf("%w: written by schema v%d, this database is v%d",
+			ErrArchiveIncompatible, header.SchemaVersion, currentSchemaVersion)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sum, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM items`).Scan(&existing); err != nil {
+		return sum, err
+	}
+	if existing > 0 {
+		return sum, ErrRestoreNotEmpty
+	}
+
+	items := map[string]bool{}
+	requireItem := func(n int, itemID string) error {
+		if !items[itemID] {
+			return fmt.Errorf("record %d: unknown item %q", n, itemID)
+		}
+		return nil
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	for n := 2; ; n++ {
+		rec = archiveRecord{}
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return sum, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		switch rec.Type {
+		case recordItem:
+			var item model.Item
+			if err := json.Unmarshal(rec.Data, &item); err != nil {
+				return sum, fmt.Errorf("record %d: %w", n, err)
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO items (`+itemColumns+`)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				item.ID, item.URL, item.Title, item.Domain, item.SourceType, item.IntentText,
+				item.Status, item.Priority, item.MatchScore, item.ErrorInfo, item.SaveCount,
+				item.CreatedAt, item.UpdatedAt, item.CompletedAt, item.SnoozeUntil, item.RestoredAt, item.ProcessAfter,
+				s.canon.Canonicalize(item.URL), item.ResolvedURL, item.DuplicateOf, item.RevivedAt,
+			); err != nil {
+				return sum, fmt.Errorf("record %d: insert item: %w", n, err)
+			}
+			items[item.ID] = true
+			sum.Items++
+
+		case recordIntent:
+			var in model.Intent
+			if err := json.Unmarshal(rec.Data, &in); err != nil {
+				return sum, fmt.Errorf("record %d: %w", n, err)
+			}
+			if err := requireItem(n, in.ItemID); err != nil {
+				return sum, err
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO intents (id, item_id, text, created_at) VALUES (?, ?, ?, ?)`,
+				in.ID, in.ItemID, in.Text, in.CreatedAt,
+			); err != nil {
+				return sum, fmt.Errorf("record %d: insert intent: %w", n, err)
+			}
+			sum.Intents++
+
+		case recordArtifact:
+			var a model.Artifact
+			if err := json.Unmarshal(rec.Data, &a); err != nil {
+				return sum, fmt.Errorf("record %d: %w", n, err)
+			}
+			if err := requireItem(n, a.ItemID); err != nil {
+				return sum, err
+			}
+			if header.Version < historyArchiveVersion {
+				if err := appendVersionTx(ctx, tx, &a); err != nil {
+					return sum, fmt.Errorf("record %d: %w", n, err)
+				}
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO artifacts (`+artifactColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				a.ID, a.ItemID, a.ArtifactType, a.Payload, a.CreatedBy, a.CreatedAt, a.Model, a.PromptVersion, a.Version,
+			); err != nil {
+				return sum, fmt.Errorf("record %d: insert artifact: %w", n, err)
+			}
+			if a.ArtifactType == model.ArtifactExtraction {
+				if err := setFingerprint(ctx, tx, a.ItemID, extractionFingerprint(a.Payload)); err != nil {
+					return sum, fmt.Errorf("record %d: insert fingerprint: %w", n, err)
+				}
+			}
+			sum.Artifacts++
+
+		case recordVersion:
+			var a model.Artifact
+			if err := json.Unmarshal(rec.Data, &a); err != nil {
+				return sum, fmt.Errorf("record %d: %w", n, err)
+			}
+			if err := requireItem(n, a.ItemID); err != nil {
+				return sum, err
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO artifact_versions (`+artifactColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				a.ID, a.ItemID, a.ArtifactType, a.Payload, a.CreatedBy, a.CreatedAt, a.Model, a.PromptVersion, a.Version,
+			); err != nil {
+				return sum, fmt.Errorf("record %d: insert artifact version: %w", n, err)
+			}
+			sum.ArtifactVersions++
+
+		case recordProposal:
+			var a model.Artifact
+			if err := json.Unmarshal(rec.Data, &a); err != nil {
+				return sum, fmt.Errorf("record %d: %w", n, err)
+			}
+			if err := requireItem(n, a.ItemID); err != nil {
+				return sum, err
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO artifact_proposals (`+proposalColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				a.ID, a.ItemID, a.ArtifactType, a.Payload, a.CreatedAt, a.Model, a.PromptVersion, a.Version,
+			); err != nil {
+				return sum, fmt.Errorf("record %d: insert artifact proposal: %w", n, err)
+			}
+			sum.Proposals++
+
+		case recordTodo:
+			var t model.Todo
+			if err := json.Unmarshal(rec.Data, &t); err != nil {
+				return sum, fmt.Errorf("record %d: %w", n, err)
+			}
+			if err := requireItem(n, t.ItemID); err != nil {
+				return sum, err
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO todos (`+todoColumns+`)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				t.ID, t.ItemID, t.Title, t.ETA, t.Type, t.Position, t.Done, t.CompletedAt, t.DueDate, t.Edited, t.CreatedBy, t.CreatedAt, t.UpdatedAt,
+			); err != nil {
+				return sum, fmt.Errorf("record %d: insert todo: %w", n, err)
+			}
+			sum.Todos++
+
+		case recordTag:
+			var t archiveTag
+			if err := json.Unmarshal(rec.Data, &t); err != nil {
+				return sum, fmt.Errorf("record %d: %w", n, err)
+			}
+			if err := requireItem(n, t.ItemID); err != nil {
+				return sum, err
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO tags (name, created_at) VALUES (?, ?)`, t.Tag, now); err != nil {
+				return sum, fmt.Errorf("record %d: insert tag: %w", n, err)
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO item_tags (item_id, tag, created_at, source) VALUES (?, ?, ?, ?)`,
+				t.ItemID, t.Tag, t.CreatedAt, t.Source,
+			); err != nil {
+				return sum, fmt.Errorf("record %d: insert item tag: %w", n, err)
+			}
+			sum.Tags++
+
+		default:
+			return sum, fmt.Errorf("record %d: unknown record type %q", n, rec.Type)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return sum, fmt.Errorf("commit: %w", err)
+	}
+	return sum, nil
+}
@@ -0,0 +1,219 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// seedArchiveData creates two items with intents, artifacts, todos and tags.
+// item-1's synthesis was edited by the user and regenerated, so it has three
+// versions and a pending proposal.
+func seedArchiveData(t *testing.T, s *Store) {
+	t.Helper()
+	ctx := context.Background()
+	for _, id := range []string{"item-1", "item-2"} {
+		if err := s.CreateItem(ctx, makeItem(id, "https://example.com/"+id)); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.CreateIntent(ctx, model.NewIntent("intent-"+id, id, "learn "+id)); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.UpsertArtifact(ctx, model.NewArtifact("art-"+id, id, model.ArtifactSynthesis, `{"insight":"x"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SyncGeneratedTodos(ctx, id, []model.Todo{model.NewTodo("todo-"+id, id, "Read", "10m", model.TodoTypeRead, 0)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	edited := model.NewArtifact("art-edit", "item-1", model.ArtifactSynthesis, `{"insight":"mine"}`)
+	edited.CreatedBy = model.CreatedByUser
+	if err := s.UpsertArtifact(ctx, edited); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpsertArtifact(ctx, model.NewArtifact("art-regen", "item-1", model.ArtifactSynthesis, `{"insight":"y"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetItemTags(ctx, "item-1", []string{"go", "db"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateItemStatus(ctx, "item-2", model.StatusReady, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	src := newTestStore(t)
+	ctx := context.Background()
+	seedArchiveData(t, src)
+
+	var buf bytes.Buffer
+	sum, err := src.ExportArchive(ctx, &buf)
+	if err != nil {
+		t.Fatalf("ExportArchive: %v", err)
+	}
+	want := ArchiveSummary{Items: 2, Intents: 2, Artifacts: 2, ArtifactVersions: 4, Proposals: 1, Todos: 2, Tags: 2}
+	if sum != want {
+		t.Errorf("export summary = %+v, want %+v", sum, want)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 16 {
+		t.Errorf("archive has %d lines, want header + 15 records", lines)
+	}
+
+	dst := newTestStore(t)
+	sum, err = dst.RestoreArchive(ctx, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("RestoreArchive: %v", err)
+	}
+	if sum != want {
+		t.Errorf("restore summary = %+v, want %+v", sum, want)
+	}
+
+	for _, id := range []string{"item-1", "item-2"} {
+		before, _ := src.GetItem(ctx, id)
+		after, err := dst.GetItem(ctx, id)
+		if err != nil {
+			t.Fatalf("GetItem(%s) after restore: %v", id, err)
+		}
+		if !reflect.DeepEqual(before, after) {
+			t.Errorf("item %s differs after restore:\n got %+v\nwant %+v", id, after, before)
+		}
+		// The history is restored as it was, not restarted from the
+		// current artifact.
+		vBefore, _ := src.ListArtifactVersions(ctx, id, model.ArtifactSynthesis)
+		vAfter, err := dst.ListArtifactVersions(ctx, id, model.ArtifactSynthesis)
+		if err != nil {
+			t.Fatalf("ListArtifactVersions(%s) after restore: %v", id, err)
+		}
+		if !reflect.DeepEqual(vBefore, vAfter) {
+			t.Errorf("item %s history differs after restore:\n got %+v\nwant %+v", id, vAfter, vBefore)
+		}
+	}
+	if p, err := dst.GetArtifactProposal(ctx, "item-1", model.ArtifactSynthesis); err != nil || p.ID != "art-regen" || p.Version != 3 {
+		t.Errorf("restored proposal = %+v, %v, want art-regen as version 3", p, err)
+	}
+	// New versions continue the restored history.
+	if _, err := dst.RevertArtifact(ctx, "item-1", model.ArtifactSynthesis, 1); err != nil {
+		t.Fatalf("RevertArtifact after restore: %v", err)
+	}
+	if a, _ := dst.GetArtifact(ctx, "item-1", model.ArtifactSynthesis); a.Version != 4 {
+		t.Errorf("version after revert = %d, want 4", a.Version)
+	}
+
+	// Restoring twice is refused rather than duplicating anything.
+	if _, err := dst.RestoreArchive(ctx, bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrRestoreNotEmpty) {
+		t.Errorf("second restore error = %v, want ErrRestoreNotEmpty", err)
+	}
+}
+
+func TestRestoreArchive_Version1(t *testing.T) {
+	// Version 1 archives carry only the current artifacts.
+	archive := strings.Join([]string{
+		`{"type":"header","data":{"format":"readdo-jsonl","version":1,"schema_version":19,"exported_at":"2026-01-02T00:00:00Z"}}`,
+		`{"type":"item","data":{"id":"i1","url":"https://example.com/a","source_type":"web","status":"READY","save_count":1,"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-01T00:00:00Z"}}`,
+		`{"type":"artifact","data":{"id":"a-ext","item_id":"i1","artifact_type":"extraction","payload":"{\"normalized_text\":\"text\"}","created_by":"system","created_at":"2026-01-01T00:00:00Z"}}`,
+		`{"type":"artifact","data":{"id":"a-syn","item_id":"i1","artifact_type":"synthesis","payload":"{\"insight\":\"mine\"}","created_by":"user","created_at":"2026-01-01T00:00:00Z"}}`,
+	}, "\n") + "\n"
+
+	s := newTestStore(t)
+	ctx := context.Background()
+	sum, err := s.RestoreArchive(ctx, strings.NewReader(archive))
+	if err != nil {
+		t.Fatalf("RestoreArchive: %v", err)
+	}
+	if want := (ArchiveSummary{Items: 1, Artifacts: 2}); sum != want {
+		t.Errorf("summary = %+v, want %+v", sum, want)
+	}
+
+	// Each artifact with a history starts it at version 1.
+	versions, err := s.ListArtifactVersions(ctx, "i1", model.ArtifactSynthesis)
+	if err != nil || len(versions) != 1 || versions[0].ID != "a-syn" || versions[0].Version != 1 {
+		t.Errorf("synthesis history = %+v, %v, want a-syn as version 1", versions, err)
+	}
+	if a, _ := s.GetArtifact(ctx, "i1", model.ArtifactSynthesis); a == nil || a.Version != 1 {
+		t.Errorf("current synthesis = %+v, want version 1", a)
+	}
+	if versions, _ := s.ListArtifactVersions(ctx, "i1", model.ArtifactExtraction); len(versions) != 0 {
+		t.Errorf("extraction history = %+v, want none", versions)
+	}
+}
+
+func TestRestoreArchive_Rejects(t *testing.T) {
+	header := func(format string, version, schema int) string {
+		return `{"type":"header","data":{"format":"` + format + `","version":` + strconv.Itoa(version) + `,"schema_version":` + strconv.Itoa(schema) + `}}` + "\n"
+	}
+	item := `{"type":"item","data":{"id":"i1","url":"https://example.com","source_type":"web","status":"READY","save_count":1,"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-01T00:00:00Z"}}` + "\n"
+	tests := []struct {
+		name           string
+		archive        string
+		wantIncompat   bool
+		wantErrContain string
+	}{
+		{"empty", "", true, "missing header"},
+		{"no header", item, true, "missing header"},
+		{"wrong format", header("other", 1, 1), true, "format"},
+		{"newer archive", header(ArchiveFormat, ArchiveVersion+1, 1), true, "version"},
+		{"newer schema", header(ArchiveFormat, ArchiveVersion, currentSchemaVersion+1), true, "written by schema"},
+		{"orphan record", header(ArchiveFormat, ArchiveVersion, 1) + `{"type":"intent","data":{"id":"x","item_id":"nope","text":"t"}}` + "\n", false, "unknown item"},
+		{"unknown type", header(ArchiveFormat, ArchiveVersion, 1) + item + `{"type":"view","data":{}}` + "\n", false, "unknown record type"},
+		{"truncated", header(ArchiveFormat, ArchiveVersion, 1) + item[:40], false, "record 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			_, err := s.RestoreArchive(context.Background(), strings.NewReader(tt.archive))
+			if err == nil {
+				t.Fatal("RestoreArchive succeeded, want error")
+			}
+			if errors.Is(err, ErrArchiveIncompatible) != tt.wantIncompat {
+				t.Errorf("error = %v, want incompatible = %v", err, tt.wantIncompat)
+			}
+			if !strings.Contains(err.Error(), tt.wantErrContain) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErrContain)
+			}
+			// Nothing is written when the archive fails to load.
+			if n, _ := s.CountItems(context.Background(), model.ItemFilter{}); n != 0 {
+				t.Errorf("%d items restored from a rejected archive", n)
+			}
+		})
+	}
+}
+
+func TestBackup(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	seedArchiveData(t, s)
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := s.Backup(ctx, path); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("open snapshot: %v", err)
+	}
+	defer db.Close()
+	snap, err := New(db)
+	if err != nil {
+		t.Fatalf("snapshot store: %v", err)
+	}
+	item, err := snap.GetItem(ctx, "item-1")
+	if err != nil {
+		t.Fatalf("GetItem from snapshot: %v", err)
+	}
+	if len(item.Tags) != 2 || len(item.Todos) != 1 {
+		t.Errorf("snapshot item = %+v, want tags and todos", item)
+	}
+
+	// VACUUM INTO never overwrites an existing file.
+	if err := s.Backup(ctx, path); err == nil {
+		t.Error("Backup over an existing file succeeded")
+	}
+}
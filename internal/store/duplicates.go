@@ -0,0 +1,413 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// refreshCanonicalURLs recomputes canonical_url for items whose stored value
+// no longer matches the canonicalizer: every item right after migration v17,
+// and the affected items after the tracking-parameter rules change. When any
+// item changed, the resulting duplicates are logged.
+func (s *Store) refreshCanonicalURLs(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, url, canonical_url FROM items`)
+	if err != nil {
+		return err
+	}
+	stale := map[string]string{}
+	for rows.Next() {
+		var id, url, canonical string
+		if err := rows.Scan(&id, &url, &canonical); err != nil {
+			rows.Close()
+			return err
+		}
+		if c := s.canon.Canonicalize(url); c != canonical {
+			stale[id] = c
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for id, c := range stale {
+		if _, err := tx.ExecContext(ctx, `UPDATE items SET canonical_url = ? WHERE id = ?`, c, id); err != nil {
+			return fmt.Errorf("update item %s: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	clusters, err := s.ListURLDuplicates(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("canonical urls updated", "items", len(stale), "duplicate_groups", len(clusters))
+	for _, c := range clusters {
+		ids := make([]string, len(c.Items))
+		for i, it := range c.Items {
+			ids[i] = it.ID
+		}
+		slog.Warn("items share a canonical url", "canonical_url", c.Key, "item_ids", ids)
+	}
+	return nil
+}
+
+// ListURLDuplicates returns the groups of items, in any status, that share a
+// canonical URL. Items in a group are ordered oldest first.
+func (s *Store) ListURLDuplicates(ctx context.Context) ([]model.DuplicateCluster, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+itemColumns+` FROM items
+		WHERE canonical_url IN (SELECT canonical_url FROM items GROUP BY canonical_url HAVING COUNT(*) > 1)
+		ORDER BY canonical_url, created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clusters []model.DuplicateCluster
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(clusters); n == 0 || clusters[n-1].Key != item.CanonicalURL {
+			clusters = append(clusters, model.DuplicateCluster{Reason: model.DuplicateURL, Key: item.CanonicalURL})
+		}
+		last := &clusters[len(clusters)-1]
+		last.Items = append(last.Items, *item)
+	}
+	return clusters, rows.Err()
+}
+
+// ListSuspectedDuplicates returns one group per item flagged as a suspected
+// duplicate: the item it is suspected to duplicate, then the item itself.
+// The key is the flagged item's resolved URL.
+func (s *Store) ListSuspectedDuplicates(ctx context.Context) ([]model.DuplicateCluster, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+itemColumns+` FROM items WHERE duplicate_of != '' ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	var suspects []model.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		suspects = append(suspects, *item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var clusters []model.DuplicateCluster
+	for _, suspect := range suspects {
+		of, err := scanItem(s.db.QueryRowContext(ctx, `SELECT `+itemColumns+` FROM items WHERE id = ?`, *suspect.DuplicateOf))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // the other item was deleted; nothing left to confirm
+		}
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, model.DuplicateCluster{
+			Reason: model.DuplicateSuspected,
+			Key:    suspect.ResolvedURL,
+			Items:  []model.Item{*of, suspect},
+		})
+	}
+	return clusters, nil
+}
+
+// FindDuplicateOf returns the oldest active (non-ARCHIVED) item other than id
+// whose URL, or the URL it was resolved to, has the same canonical form as
+// url. It returns nil if there is none.
+func (s *Store) FindDuplicateOf(ctx context.Context, id, url string) (*model.Item, error) {
+	canonical := s.canon.Canonicalize(url)
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+itemColumns+` FROM items
+		 WHERE id != ? AND (canonical_url = ? OR resolved_url = ?) AND status != ?
+		 ORDER BY created_at ASC, id ASC LIMIT 1`,
+		id, canonical, canonical, model.StatusArchived,
+	)
+	item, err := scanItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return item, err
+}
+
+// SetResolvedURL records where an item's URL led, so that capturing that URL
+// finds the item. A URL with the item's own canonical form is not recorded.
+func (s *Store) SetResolvedURL(ctx context.Context, id, url string) error {
+	canonical := s.canon.Canonicalize(url)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE items SET resolved_url = CASE WHEN canonical_url = ? THEN '' ELSE ? END WHERE id = ?`,
+		canonical, canonical, id,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// FlagDuplicate marks an item as a suspected duplicate of item ofID, unless
+// the user already dismissed a suspicion for it. It reports whether the
+// item was flagged.
+func (s *Store) FlagDuplicate(ctx context.Context, id, ofID string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE items SET duplicate_of = ? WHERE id = ? AND duplicate_of IS NULL`, ofID, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DismissDuplicate clears an item's suspected duplicate. The dismissal is
+// remembered (as an empty duplicate_of), so reprocessing does not flag the
+// item again. It returns sql.ErrNoRows if the item is not flagged.
+func (s *Store) DismissDuplicate(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE items SET duplicate_of = '' WHERE id = ? AND duplicate_of != ''`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// MergeItems folds the items fromIDs into item intoID and deletes them:
+// their intents and tags move over, as do the todos the user has ticked,
+// edited or added, their intent texts and save counts are added in order, and items suspected to duplicate them now point at
+// intoID. The surviving item is queued for processing again, as on a
+// repeated capture, and returned. It returns sql.ErrNoRows if any item
+// does not exist.
+func (s *Store) MergeItems(ctx context.Context, intoID string, fromIDs []string) (*model.Item, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	into, err := scanItem(tx.QueryRowContext(ctx, `SELECT `+itemColumns+` FROM items WHERE id = ?`, intoID))
+	if err != nil {
+		return nil, fmt.Errorf("get item %s: %w", intoID, err)
+	}
+	for _, fromID := range fromIDs {
+		if fromID == intoID {
+			return nil, fmt.Errorf("cannot merge item %s into itself", fromID)
+		}
+		from, err := scanItem(tx.QueryRowContext(ctx, `SELECT `+itemColumns+` FROM items WHERE id = ?`, fromID))
+		if err != nil {
+			return nil, fmt.Errorf("get item %s: %w", fromID, err)
+		}
+		into.Absorb(*from)
+
+		if _, err := tx.ExecContext(ctx, `UPDATE intents SET item_id = ? WHERE item_id = ?`, intoID, fromID); err != nil {
+			return nil, fmt.Errorf("move intents: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO item_tags (item_id, tag, created_at, source)
+			 SELECT ?, tag, created_at, source FROM item_tags WHERE item_id = ?`,
+			intoID, fromID,
+		); err != nil {
+			return nil, fmt.Errorf("move tags: %w", err)
+		}
+		// Generated todos are regenerated for the merged item; the user's
+		// work on the others is kept, after the surviving item's todos.
+		var next int
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COALESCE(MAX(position) + 1, 0) FROM todos WHERE item_id = ?`, intoID,
+		).Scan(&next); err != nil {
+			return nil, fmt.Errorf("read todo positions: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE todos SET item_id = ?, position = position + ? WHERE item_id = ? AND (edited = 1 OR done = 1 OR created_by = ?)`,
+			intoID, next, fromID, model.CreatedByUser,
+		); err != nil {
+			return nil, fmt.Errorf("move todos: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE items SET duplicate_of = ? WHERE duplicate_of = ?`, intoID, fromID); err != nil {
+			return nil, fmt.Errorf("repoint duplicates: %w", err)
+		}
+		if err := deleteItemTx(ctx, tx, fromID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE items SET intent_text = ?, save_count = ?, status = ?, error_info = NULL, completed_at = NULL,
+			snooze_until = NULL, process_after = NULL, duplicate_of = NULLIF(duplicate_of, ?), updated_at = ? WHERE id = ?`,
+		into.IntentText, into.SaveCount, model.StatusCaptured, intoID, now, intoID,
+	); err != nil {
+		return nil, fmt.Errorf("update item: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+itemColumns+` FROM items WHERE id = ?`, intoID)
+	return scanItem(row)
+}
+
+// ---------------------------------------------------------------------------
+// Content fingerprints
+// ---------------------------------------------------------------------------
+
+// execer is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// SetFingerprint stores the fingerprint of an item's extracted text, or
+// removes it when fp is nil.
+func (s *Store) SetFingerprint(ctx context.Context, itemID string, fp *model.Fingerprint) error {
+	return setFingerprint(ctx, s.db, itemID, fp)
+}
+
+func setFingerprint(ctx context.Context, db execer, itemID string, fp *model.Fingerprint) error {
+	if fp == nil {
+		_, err := db.ExecContext(ctx, `DELETE FROM fingerprints WHERE item_id = ?`, itemID)
+		return err
+	}
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO fingerprints (item_id, simhash, band0, band1, band2, band3) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(item_id) DO UPDATE SET simhash = excluded.simhash,
+			band0 = excluded.band0, band1 = excluded.band1, band2 = excluded.band2, band3 = excluded.band3`,
+		itemID, int64(*fp), fp.Band(0), fp.Band(1), fp.Band(2), fp.Band(3),
+	)
+	return err
+}
+
+// extractionFingerprint fingerprints the text of an extraction artifact
+// payload. It returns nil if the payload is malformed or the text too short.
+func extractionFingerprint(payload string) *model.Fingerprint {
+	var ext struct {
+		NormalizedText string `json:"normalized_text"`
+	}
+	if err := json.Unmarshal([]byte(payload), &ext); err != nil {
+		return nil
+	}
+	fp, ok := model.SimHash(ext.NormalizedText)
+	if !ok {
+		return nil
+	}
+	return &fp
+}
+
+// ListContentDuplicates returns the groups of items, in any status, whose
+// extracted texts are near-duplicates: their fingerprints are within
+// model.NearDuplicateDistance bits, directly or through other items of the
+// group. Groups whose items all share a canonical URL are left to
+// ListURLDuplicates. Items in a group are ordered oldest first, and the key
+// is the oldest item's fingerprint.
+func (s *Store) ListContentDuplicates(ctx context.Context) ([]model.DuplicateCluster, error) {
+	// Candidate pairs agree on at least one band; each band is indexed.
+	var joins []string
+	for i := 0; i < model.FingerprintBands; i++ {
+		joins = append(joins, fmt.Sprintf(
+			`SELECT a.item_id, a.simhash, b.item_id, b.simhash FROM fingerprints a
+			 JOIN fingerprints b ON b.band%d = a.band%d AND b.item_id > a.item_id`, i, i))
+	}
+	rows, err := s.db.QueryContext(ctx, strings.Join(joins, " UNION "))
+	if err != nil {
+		return nil, err
+	}
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(id string) string {
+		if p, ok := parent[id]; ok && p != id {
+			parent[id] = find(p)
+			return parent[id]
+		}
+		parent[id] = id
+		return id
+	}
+	fingerprints := map[string]model.Fingerprint{}
+	for rows.Next() {
+		var a, b string
+		var fa, fb int64
+		if err := rows.Scan(&a, &fa, &b, &fb); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if model.Fingerprint(fa).Distance(model.Fingerprint(fb)) > model.NearDuplicateDistance {
+			continue
+		}
+		fingerprints[a], fingerprints[b] = model.Fingerprint(fa), model.Fingerprint(fb)
+		parent[find(a)] = find(b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(fingerprints) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]any, 0, len(fingerprints))
+	for id := range fingerprints {
+		ids = append(ids, id)
+	}
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT `+itemColumns+` FROM items WHERE id IN (`+placeholders(len(ids))+`) ORDER BY created_at, id`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	groups := map[string]*model.DuplicateCluster{}
+	var order []string
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		root := find(item.ID)
+		g, ok := groups[root]
+		if !ok {
+			g = &model.DuplicateCluster{Reason: model.DuplicateContent, Key: fingerprints[item.ID].String()}
+			groups[root] = g
+			order = append(order, root)
+		}
+		g.Items = append(g.Items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var clusters []model.DuplicateCluster
+	for _, root := range order {
+		g := groups[root]
+		if len(g.Items) < 2 || sameCanonicalURL(g.Items) {
+			continue
+		}
+		clusters = append(clusters, *g)
+	}
+	return clusters, nil
+}
+
+func sameCanonicalURL(items []model.Item) bool {
+	for _, it := range items[1:] {
+		if it.CanonicalURL != items[0].CanonicalURL {
+			return false
+		}
+	}
+	return true
+}
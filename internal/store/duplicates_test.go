@@ -0,0 +1,227 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestFindItemByURL_Canonical(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://www.example.com/post/?utm_source=x"))
+
+	for _, url := range []string{"http://example.com/post", "https://m.example.com/post#top", "https://example.com/post?fbclid=1"} {
+		got, err := s.FindItemByURL(ctx, url)
+		if err != nil || got.ID != "item-1" {
+			t.Errorf("FindItemByURL(%q) = %v, %v", url, got, err)
+		}
+	}
+	if got, err := s.FindItemByURL(ctx, "https://example.com/post?page=2"); err == nil {
+		t.Errorf("different query matched %s", got.ID)
+	}
+
+	got, _ := s.GetItem(ctx, "item-1")
+	if got.URL != "https://www.example.com/post/?utm_source=x" || got.CanonicalURL != "https://example.com/post" {
+		t.Errorf("url = %q, canonical_url = %q", got.URL, got.CanonicalURL)
+	}
+}
+
+func TestRefreshCanonicalURLs(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("a", "https://example.com/a?src=feed"))
+	s.CreateItem(ctx, makeItem("b", "https://example.com/a"))
+	s.CreateItem(ctx, makeItem("c", "https://example.com/c/"))
+	s.CreateItem(ctx, makeItem("d", "http://www.example.com/c"))
+	// Simulate items created before migration v17.
+	if _, err := s.db.Exec(`UPDATE items SET canonical_url = ''`); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(s.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters, err := s.ListURLDuplicates(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Key != "https://example.com/c" || clusters[0].Reason != model.DuplicateURL || len(clusters[0].Items) != 2 {
+		t.Fatalf("clusters = %+v", clusters)
+	}
+
+	// A new rule applies to existing items when the store is reopened.
+	s, err = New(s.db, WithURLCanonicalizer(model.NewURLCanonicalizer([]string{"src"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters, _ = s.ListURLDuplicates(ctx)
+	if len(clusters) != 2 || clusters[0].Key != "https://example.com/a" {
+		t.Fatalf("clusters after rule change = %+v", clusters)
+	}
+	if got, err := s.FindItemByURL(ctx, "https://example.com/a?src=mail"); err != nil || got == nil {
+		t.Errorf("FindItemByURL with new rule: %v", err)
+	}
+}
+
+func TestResolvedURLAndDuplicates(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("article", "https://example.com/post"))
+	s.CreateItem(ctx, makeItem("short", "https://t.co/abc"))
+
+	if dup, err := s.FindDuplicateOf(ctx, "article", "https://example.com/post"); err != nil || dup != nil {
+		t.Errorf("FindDuplicateOf found the item itself: %v, %v", dup, err)
+	}
+	dup, err := s.FindDuplicateOf(ctx, "short", "http://www.example.com/post?utm_source=tw")
+	if err != nil || dup == nil || dup.ID != "article" {
+		t.Fatalf("FindDuplicateOf = %v, %v; want article", dup, err)
+	}
+
+	// A resolved URL makes the item findable by where it led.
+	s.CreateItem(ctx, makeItem("amp", "https://example.com/other.amp"))
+	if err := s.SetResolvedURL(ctx, "amp", "https://example.com/other"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.FindItemByURL(ctx, "https://example.com/other/"); err != nil || got.ID != "amp" {
+		t.Errorf("FindItemByURL by resolved url = %v, %v", got, err)
+	}
+	s.SetResolvedURL(ctx, "article", "https://example.com/post")
+	if got, _ := s.GetItem(ctx, "article"); got.ResolvedURL != "" {
+		t.Errorf("resolved_url = %q, want empty for the item's own url", got.ResolvedURL)
+	}
+
+	// Flagging, listing and dismissing a suspected duplicate.
+	if ok, err := s.FlagDuplicate(ctx, "amp", "article"); err != nil || !ok {
+		t.Fatalf("FlagDuplicate = %v, %v", ok, err)
+	}
+	clusters, err := s.ListSuspectedDuplicates(ctx)
+	if err != nil || len(clusters) != 1 || clusters[0].Items[0].ID != "article" || clusters[0].Items[1].ID != "amp" {
+		t.Fatalf("ListSuspectedDuplicates = %+v, %v", clusters, err)
+	}
+	if err := s.DismissDuplicate(ctx, "amp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DismissDuplicate(ctx, "amp"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second dismiss = %v, want sql.ErrNoRows", err)
+	}
+	if got, _ := s.GetItem(ctx, "amp"); got.DuplicateOf != nil {
+		t.Errorf("duplicate_of = %q after dismissal", *got.DuplicateOf)
+	}
+	// A dismissed suspicion is not raised again.
+	if ok, _ := s.FlagDuplicate(ctx, "amp", "article"); ok {
+		t.Error("dismissed item was flagged again")
+	}
+}
+
+func TestMergeItems(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	into := makeItem("into", "https://example.com/post")
+	into.IntentText = "first"
+	s.CreateItem(ctx, into)
+	s.UpdateItemStatus(ctx, "into", model.StatusReady, nil)
+	from := makeItem("from", "https://t.co/abc")
+	from.IntentText = "second"
+	from.SaveCount = 2
+	s.CreateItem(ctx, from)
+	s.CreateIntent(ctx, model.NewIntent("in-1", "from", "second"))
+	s.AddItemTags(ctx, "from", []string{"go"})
+	s.SyncGeneratedTodos(ctx, "into", []model.Todo{model.NewTodo("t-into", "into", "Read", "10m", model.TodoTypeRead, 0)})
+	s.SyncGeneratedTodos(ctx, "from", []model.Todo{
+		model.NewTodo("t-done", "from", "Read the intro", "10m", model.TodoTypeRead, 0),
+		model.NewTodo("t-open", "from", "Write notes", "20m", model.TodoTypeWrite, 1),
+	})
+	done := true
+	ticked, _ := s.GetTodo(ctx, "t-done")
+	ticked.Apply(model.TodoPatch{Done: &done})
+	s.UpdateTodo(ctx, *ticked)
+	s.CreateItem(ctx, makeItem("third", "https://example.com/post.amp"))
+	s.FlagDuplicate(ctx, "third", "from")
+
+	merged, err := s.MergeItems(ctx, "into", []string{"from"})
+	if err != nil {
+		t.Fatalf("MergeItems: %v", err)
+	}
+	if merged.IntentText != "first\n---\nsecond" || merged.SaveCount != 3 || merged.Status != model.StatusCaptured {
+		t.Errorf("merged = %+v", merged)
+	}
+	if _, err := s.GetItem(ctx, "from"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("merged item still exists: %v", err)
+	}
+	got, _ := s.GetItem(ctx, "into")
+	if len(got.Intents) != 1 || got.Intents[0].ID != "in-1" || len(got.Tags) != 1 || got.Tags[0] != "go" {
+		t.Errorf("intents = %+v, tags = %v", got.Intents, got.Tags)
+	}
+	// The ticked todo moves over; the untouched generated one does not.
+	if len(got.Todos) != 2 || got.Todos[0].ID != "t-into" || got.Todos[1].ID != "t-done" || !got.Todos[1].Done || got.Todos[1].Position != 1 {
+		t.Errorf("todos = %+v, want the surviving item's and the ticked one", got.Todos)
+	}
+	if _, err := s.GetTodo(ctx, "t-open"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("untouched generated todo of the merged item: %v, want deleted", err)
+	}
+	if third, _ := s.GetItem(ctx, "third"); third.DuplicateOf == nil || *third.DuplicateOf != "into" {
+		t.Errorf("suspected duplicate of the merged item = %v, want into", third.DuplicateOf)
+	}
+
+	if _, err := s.MergeItems(ctx, "into", []string{"into"}); err == nil {
+		t.Error("merging an item into itself succeeded")
+	}
+	if _, err := s.MergeItems(ctx, "into", []string{"missing"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("MergeItems(missing) = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestListContentDuplicates(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	fingerprints := map[string]model.Fingerprint{
+		"a": 0,
+		"b": 0b111,    // 3 bits from a
+		"c": 0b111111, // 3 bits from b, 6 from a
+		"d": 0xffff_ffff_0000_0000,
+		"e": 0,
+		"f": 0b1,
+	}
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		s.CreateItem(ctx, makeItem(id, "https://example.com/"+id))
+		fp := fingerprints[id]
+		if err := s.SetFingerprint(ctx, id, &fp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Neither a cleared fingerprint nor a deleted item is matched.
+	s.SetFingerprint(ctx, "e", nil)
+	s.DeleteItem(ctx, "f")
+
+	clusters, err := s.ListContentDuplicates(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Reason != model.DuplicateContent || clusters[0].Key != "0000000000000000" {
+		t.Fatalf("clusters = %+v", clusters)
+	}
+	var ids []string
+	for _, it := range clusters[0].Items {
+		ids = append(ids, it.ID)
+	}
+	if len(ids) != 3 {
+		t.Errorf("cluster items = %v, want a, b and c", ids)
+	}
+
+	var n int
+	s.db.QueryRow(`SELECT COUNT(*) FROM fingerprints WHERE item_id IN ('e', 'f')`).Scan(&n)
+	if n != 0 {
+		t.Errorf("%d fingerprints left for cleared or deleted items", n)
+	}
+
+	// Items with the same canonical URL are not reported twice.
+	s.db.Exec(`UPDATE items SET canonical_url = 'https://example.com/a'`)
+	if clusters, _ := s.ListContentDuplicates(ctx); len(clusters) != 0 {
+		t.Errorf("clusters with one canonical url = %+v", clusters)
+	}
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+const feedColumns = `id, name, view_id, priority, tags, token_hash, created_at, updated_at`
+
+// CreateFeed inserts a new feed.
+func (s *Store) CreateFeed(ctx context.Context, f model.Feed) error {
+	priority, tags, err := marshalFeedFilters(f)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO feeds (`+feedColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		f.ID, f.Name, f.ViewID, priority, tags, f.TokenHash, f.CreatedAt, f.UpdatedAt,
+	)
+	return err
+}
+
+// GetFeed returns a feed by ID. It returns sql.ErrNoRows if not found.
+func (s *Store) GetFeed(ctx context.Context, id string) (*model.Feed, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+feedColumns+` FROM feeds WHERE id = ?`, id)
+	return scanFeed(row)
+}
+
+// GetFeedByTokenHash returns the feed read with the token of the given
+// hash (see model.HashFeedToken). It returns sql.ErrNoRows if not found.
+func (s *Store) GetFeedByTokenHash(ctx context.Context, hash string) (*model.Feed, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+feedColumns+` FROM feeds WHERE token_hash = ?`, hash)
+	return scanFeed(row)
+}
+
+// ListFeeds returns all feeds ordered by name.
+func (s *Store) ListFeeds(ctx context.Context) ([]model.Feed, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+feedColumns+` FROM feeds ORDER BY name ASC, created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feeds []model.Feed
+	for rows.Next() {
+		f, err := scanFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, *f)
+	}
+	return feeds, rows.Err()
+}
+
+// UpdateFeed replaces a feed's name and filters. The token is only replaced
+// when f.TokenHash is non-empty.
+// It returns sql.ErrNoRows if the feed does not exist.
+func (s *Store) UpdateFeed(ctx context.Context, f model.Feed) error {
+	priority, tags, err := marshalFeedFilters(f)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE feeds SET name = ?, view_id = ?, priority = ?, tags = ?, token_hash = COALESCE(NULLIF(?, ''), token_hash), updated_at = ? WHERE id = ?`,
+		f.Name, f.ViewID, priority, tags, f.TokenHash, now, f.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// DeleteFeed removes a feed. It returns sql.ErrNoRows if the feed does not exist.
+func (s *Store) DeleteFeed(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM feeds WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+func marshalFeedFilters(f model.Feed) (priority, tags string, err error) {
+	p, err := json.Marshal(nonNil(f.Priority))
+	if err != nil {
+		return "", "", fmt.Errorf("marshal feed priority: %w", err)
+	}
+	t, err := json.Marshal(nonNil(f.Tags))
+	if err != nil {
+		return "", "", fmt.Errorf("marshal feed tags: %w", err)
+	}
+	return string(p), string(t), nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func scanFeed(row scanner) (*model.Feed, error) {
+	var f model.Feed
+	var priority, tags string
+	if err := row.Scan(&f.ID, &f.Name, &f.ViewID, &priority, &tags, &f.TokenHash, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(priority), &f.Priority); err != nil {
+		return nil, fmt.Errorf("unmarshal feed priority: %w", err)
+	}
+	if err := json.Unmarshal([]byte(tags), &f.Tags); err != nil {
+		return nil, fmt.Errorf("unmarshal feed tags: %w", err)
+	}
+	return &f, nil
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestFeedCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	viewID := "view-1"
+	f := model.NewFeed("feed-1", "Go reading", &viewID, []string{model.PriorityDoFirst}, []string{"go"}, "token-1")
+	if err := s.CreateFeed(ctx, f); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if err := s.CreateFeed(ctx, model.NewFeed("feed-2", "All", nil, nil, nil, "token-2")); err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+
+	got, err := s.GetFeedByTokenHash(ctx, model.HashFeedToken("token-1"))
+	if err != nil {
+		t.Fatalf("GetFeedByTokenHash: %v", err)
+	}
+	if !reflect.DeepEqual(*got, f) {
+		t.Errorf("feed = %+v, want %+v", *got, f)
+	}
+	if _, err := s.GetFeedByTokenHash(ctx, model.HashFeedToken("nope")); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("unknown token: err = %v, want sql.ErrNoRows", err)
+	}
+
+	// Updating without a token hash keeps the old token.
+	got.Name = "Go"
+	got.ViewID = nil
+	got.TokenHash = ""
+	if err := s.UpdateFeed(ctx, *got); err != nil {
+		t.Fatalf("UpdateFeed: %v", err)
+	}
+	if u, err := s.GetFeedByTokenHash(ctx, model.HashFeedToken("token-1")); err != nil || u.Name != "Go" || u.ViewID != nil {
+		t.Errorf("after update = %+v, %v", u, err)
+	}
+
+	// Rotating the token invalidates the old one.
+	got.TokenHash = model.HashFeedToken("token-3")
+	if err := s.UpdateFeed(ctx, *got); err != nil {
+		t.Fatalf("UpdateFeed: %v", err)
+	}
+	if _, err := s.GetFeedByTokenHash(ctx, model.HashFeedToken("token-1")); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("old token still valid: %v", err)
+	}
+
+	feeds, err := s.ListFeeds(ctx)
+	if err != nil || len(feeds) != 2 || feeds[0].Name != "All" {
+		t.Fatalf("ListFeeds = %+v, %v", feeds, err)
+	}
+	if feeds[0].Priority == nil || feeds[0].Tags == nil {
+		t.Errorf("empty filters loaded as nil: %+v", feeds[0])
+	}
+
+	if err := s.DeleteFeed(ctx, "feed-1"); err != nil {
+		t.Fatalf("DeleteFeed: %v", err)
+	}
+	if err := s.DeleteFeed(ctx, "feed-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second DeleteFeed: err = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.UpdateFeed(ctx, model.Feed{ID: "missing", Name: "x"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateFeed(missing): err = %v, want sql.ErrNoRows", err)
+	}
+}
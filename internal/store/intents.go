@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ---------------------------------------------------------------------------
+// Intents
+// ---------------------------------------------------------------------------
+
+// CreateIntent adds an intent to an item and updates its intent_text.
+func (s *Store) CreateIntent(ctx context.Context, intent model.Intent) error {
+	return s.editIntents(ctx, intent.ItemID, func(tx *sql.Tx) error {
+		return insertIntents(ctx, tx, []model.Intent{intent})
+	})
+}
+
+// ListIntents returns all intents for an item, ordered by creation time.
+func (s *Store) ListIntents(ctx context.Context, itemID string) ([]model.Intent, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, item_id, text, created_at FROM intents WHERE item_id = ? ORDER BY created_at ASC, id ASC`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var intents []model.Intent
+	for rows.Next() {
+		var i model.Intent
+		if err := rows.Scan(&i.ID, &i.ItemID, &i.Text, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		intents = append(intents, i)
+	}
+	return intents, rows.Err()
+}
+
+// UpdateIntent replaces the text of an item's intent and updates the item's
+// intent_text. It returns sql.ErrNoRows if the item has no such intent.
+func (s *Store) UpdateIntent(ctx context.Context, itemID, id, text string) (*model.Intent, error) {
+	err := s.editIntents(ctx, itemID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE intents SET text = ? WHERE id = ? AND item_id = ?`, text, id, itemID)
+		if err != nil {
+			return fmt.Errorf("update intent: %w", err)
+		}
+		return requireAffected(res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var in model.Intent
+	err = s.db.QueryRowContext(ctx, `SELECT id, item_id, text, created_at FROM intents WHERE id = ?`, id).
+		Scan(&in.ID, &in.ItemID, &in.Text, &in.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &in, nil
+}
+
+// DeleteIntent removes an item's intent and updates the item's intent_text.
+// It returns sql.ErrNoRows if the item has no such intent.
+func (s *Store) DeleteIntent(ctx context.Context, itemID, id string) error {
+	return s.editIntents(ctx, itemID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM intents WHERE id = ? AND item_id = ?`, id, itemID)
+		if err != nil {
+			return fmt.Errorf("delete intent: %w", err)
+		}
+		return requireAffected(res)
+	})
+}
+
+// editIntents runs edit and then rederives the item's intent_text from its
+// intents, oldest first, in one transaction.
+func (s *Store) editIntents(ctx context.Context, itemID string, edit func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := edit(tx); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT text FROM intents WHERE item_id = ? ORDER BY created_at ASC, id ASC`, itemID)
+	if err != nil {
+		return fmt.Errorf("read intents: %w", err)
+	}
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			rows.Close()
+			return err
+		}
+		texts = append(texts, text)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := tx.ExecContext(ctx, `UPDATE items SET intent_text = ?, updated_at = ? WHERE id = ?`,
+		strings.Join(texts, "\n---\n"), now, itemID)
+	if err != nil {
+		return fmt.Errorf("update intent_text: %w", err)
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertIntents(ctx context.Context, tx *sql.Tx, intents []model.Intent) error {
+	for _, in := range intents {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO intents (id, item_id, text, created_at) VALUES (?, ?, ?, ?)`,
+			in.ID, in.ItemID, in.Text, in.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("insert intent: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestIntents(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	first := model.NewIntent("int-1", "item-1", "learn Go")
+	first.CreatedAt = "2026-09-01T08:00:00Z"
+	item := makeItem("item-1", "https://example.com/1")
+	item.IntentText = "learn Go"
+	if err := s.CreateItem(ctx, item, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateIntent(ctx, model.NewIntent("int-2", "item-1", "write a CLI")); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.GetItem(ctx, "item-1")
+	if len(got.Intents) != 2 || got.IntentText != "learn Go\n---\nwrite a CLI" {
+		t.Fatalf("intents = %+v, intent_text = %q", got.Intents, got.IntentText)
+	}
+
+	in, err := s.UpdateIntent(ctx, "item-1", "int-1", "learn Go generics")
+	if err != nil || in.Text != "learn Go generics" || in.CreatedAt != first.CreatedAt {
+		t.Fatalf("UpdateIntent = %+v, %v", in, err)
+	}
+	if err := s.DeleteIntent(ctx, "item-1", "int-2"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.GetItem(ctx, "item-1")
+	if got.IntentText != "learn Go generics" {
+		t.Errorf("intent_text = %q after edit and delete", got.IntentText)
+	}
+
+	s.CreateItem(ctx, makeItem("item-2", "https://example.com/2"))
+	if _, err := s.UpdateIntent(ctx, "item-2", "int-1", "x"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateIntent of another item's intent = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.DeleteIntent(ctx, "item-1", "int-2"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second DeleteIntent = %v, want sql.ErrNoRows", err)
+	}
+
+	// A failed capture leaves no intent behind.
+	dup := makeItem("item-1", "https://example.com/dup")
+	if err := s.CreateItem(ctx, dup, model.NewIntent("int-3", "item-1", "again")); err == nil {
+		t.Fatal("CreateItem with a duplicate id succeeded")
+	}
+	if intents, _ := s.ListIntents(ctx, "item-1"); len(intents) != 1 {
+		t.Errorf("intents after failed capture = %+v", intents)
+	}
+	if err := s.UpdateItemForReprocess(ctx, "missing", "x", 2, model.NewIntent("int-4", "missing", "x")); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateItemForReprocess(missing) = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/yangwenmai/readdo/internal/model"
 )
@@ -10,6 +12,8 @@ import (
 type StatusCounts struct {
 	Inbox   int `json:"inbox"`
 	Archive int `json:"archive"`
+	Done    int `json:"done"`
+	Snoozed int `json:"snoozed"`
 }
 
 // ItemReader provides read access to items.
@@ -17,34 +21,142 @@ type ItemReader interface {
 	GetItem(ctx context.Context, id string) (*model.ItemWithArtifacts, error)
 	ListItems(ctx context.Context, f model.ItemFilter) ([]model.Item, error)
 	FindItemByURL(ctx context.Context, url string) (*model.Item, error)
+	FindArchivedItemByURL(ctx context.Context, url string) (*model.Item, error)
 	CountByStatus(ctx context.Context) (StatusCounts, error)
+	CountItems(ctx context.Context, f model.ItemFilter) (int, error)
+	LatestProcessAfter(ctx context.Context) (string, error)
 }
 
 // ItemWriter provides write access to items.
 type ItemWriter interface {
-	CreateItem(ctx context.Context, item model.Item) error
+	CreateItem(ctx context.Context, item model.Item, intents ...model.Intent) error
 	UpdateItemStatus(ctx context.Context, id, newStatus string, errorInfo *string) error
 	UpdateItemScoreAndPriority(ctx context.Context, id string, score float64, priority string) error
-	UpdateItemForReprocess(ctx context.Context, id, intentText string, saveCount int) error
+	UpdateItemForReprocess(ctx context.Context, id, intentText string, saveCount int, intents ...model.Intent) error
+	ReviveItem(ctx context.Context, id, intentText string, saveCount int, intents ...model.Intent) error
+	DeferProcessing(ctx context.Context, id, at string) error
 	DeleteItem(ctx context.Context, id string) error
-	BatchUpdateStatus(ctx context.Context, ids []string, status string) (int64, error)
-	BatchDeleteItems(ctx context.Context, ids []string) (int64, error)
+	BatchUpdateStatus(ctx context.Context, ids []string, status string) ([]string, error)
+	BatchDeleteItems(ctx context.Context, ids []string) ([]string, error)
+	SnoozeItems(ctx context.Context, ids []string, until string) ([]string, error)
 }
 
 // ItemClaimer provides atomic claim operations for background processing.
 type ItemClaimer interface {
 	ClaimNextCaptured(ctx context.Context) (*model.Item, error)
 	ResetStaleProcessing(ctx context.Context) (int64, error)
+	WakeSnoozedItems(ctx context.Context, now time.Time) ([]string, error)
 }
 
 // ArtifactStore provides access to artifact persistence.
 type ArtifactStore interface {
 	UpsertArtifact(ctx context.Context, a model.Artifact) error
+	GetArtifact(ctx context.Context, itemID, artifactType string) (*model.Artifact, error)
+	GetArtifactProposal(ctx context.Context, itemID, artifactType string) (*model.Artifact, error)
+	AcceptArtifactProposal(ctx context.Context, itemID, artifactType string) (*model.Artifact, error)
+	RejectArtifactProposal(ctx context.Context, itemID, artifactType string) error
+	ListArtifactVersions(ctx context.Context, itemID, artifactType string) ([]model.Artifact, error)
+	GetArtifactVersion(ctx context.Context, itemID, artifactType string, version int) (*model.Artifact, error)
+	RevertArtifact(ctx context.Context, itemID, artifactType string, version int) (*model.Artifact, error)
 }
 
 // IntentStore provides access to intent persistence.
 type IntentStore interface {
 	CreateIntent(ctx context.Context, intent model.Intent) error
+	ListIntents(ctx context.Context, itemID string) ([]model.Intent, error)
+	UpdateIntent(ctx context.Context, itemID, id, text string) (*model.Intent, error)
+	DeleteIntent(ctx context.Context, itemID, id string) error
+}
+
+// ViewStore provides access to saved view persistence.
+type ViewStore interface {
+	CreateView(ctx context.Context, v model.View) error
+	GetView(ctx context.Context, id string) (*model.View, error)
+	ListViews(ctx context.Context) ([]model.View, error)
+	UpdateView(ctx context.Context, v model.View) error
+	DeleteView(ctx context.Context, id string) error
+	CountViews(ctx context.Context) ([]ViewCount, error)
+}
+
+// TagStore provides access to item tags.
+type TagStore interface {
+	ListItemTags(ctx context.Context, itemID string) ([]string, error)
+	SetItemTags(ctx context.Context, itemID string, tags []string) error
+	AddItemTags(ctx context.Context, itemID string, tags []string) error
+	BatchUpdateTags(ctx context.Context, ids, add, remove []string) ([]string, error)
+	CountTags(ctx context.Context) ([]TagCount, error)
+}
+
+// TodoStore provides access to tracked todos.
+type TodoStore interface {
+	GetTodo(ctx context.Context, id string) (*model.Todo, error)
+	ListTodos(ctx context.Context, f model.TodoFilter) ([]model.Todo, error)
+	UpdateTodo(ctx context.Context, t model.Todo) error
+	SyncGeneratedTodos(ctx context.Context, itemID string, generated []model.Todo) error
+	CountItemTodos(ctx context.Context, itemID string) (total, done int, err error)
+	ListTodoSyncs(ctx context.Context, itemID string) ([]model.TodoSync, error)
+}
+
+// ArchiveRuleStore provides access to auto-archive rules and their log.
+type ArchiveRuleStore interface {
+	CreateArchiveRule(ctx context.Context, r model.ArchiveRule) error
+	GetArchiveRule(ctx context.Context, id string) (*model.ArchiveRule, error)
+	ListArchiveRules(ctx context.Context) ([]model.ArchiveRule, error)
+	UpdateArchiveRule(ctx context.Context, r model.ArchiveRule) error
+	DeleteArchiveRule(ctx context.Context, id string) error
+	MatchArchiveRule(ctx context.Context, r model.ArchiveRule, now time.Time) ([]model.Item, error)
+	ApplyArchiveRule(ctx context.Context, r model.ArchiveRule, now time.Time) ([]string, error)
+	ListAutoArchives(ctx context.Context) ([]model.AutoArchive, error)
+	UndoAutoArchive(ctx context.Context, id string) (*model.AutoArchive, error)
+}
+
+// JobStore provides access to persisted scheduler job state.
+type JobStore interface {
+	RegisterJob(ctx context.Context, name, schedule, nextRunAt string) error
+	GetJob(ctx context.Context, name string) (*model.Job, error)
+	ListJobs(ctx context.Context) ([]model.Job, error)
+	ClaimJobRun(ctx context.Context, name, dueAt, nextRunAt string) (bool, error)
+	FinishJobRun(ctx context.Context, name, startedAt string, durationMS int64, errMsg *string) error
+}
+
+// WebhookStore provides access to webhook subscriptions and their deliveries.
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, w model.Webhook) error
+	GetWebhook(ctx context.Context, id string) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	UpdateWebhook(ctx context.Context, w model.Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+	ListWebhookDeliveries(ctx context.Context, webhookID, status string) ([]model.WebhookDelivery, error)
+	RedeliverWebhookDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error)
+}
+
+// FeedStore provides access to Atom feed definitions.
+type FeedStore interface {
+	CreateFeed(ctx context.Context, f model.Feed) error
+	GetFeed(ctx context.Context, id string) (*model.Feed, error)
+	GetFeedByTokenHash(ctx context.Context, hash string) (*model.Feed, error)
+	ListFeeds(ctx context.Context) ([]model.Feed, error)
+	UpdateFeed(ctx context.Context, f model.Feed) error
+	DeleteFeed(ctx context.Context, id string) error
+}
+
+// DuplicateStore provides duplicate detection and merging of items.
+type DuplicateStore interface {
+	ListURLDuplicates(ctx context.Context) ([]model.DuplicateCluster, error)
+	ListSuspectedDuplicates(ctx context.Context) ([]model.DuplicateCluster, error)
+	ListContentDuplicates(ctx context.Context) ([]model.DuplicateCluster, error)
+	FindDuplicateOf(ctx context.Context, id, url string) (*model.Item, error)
+	SetResolvedURL(ctx context.Context, id, url string) error
+	FlagDuplicate(ctx context.Context, id, ofID string) (bool, error)
+	DismissDuplicate(ctx context.Context, id string) error
+	MergeItems(ctx context.Context, intoID string, fromIDs []string) (*model.Item, error)
+	SetFingerprint(ctx context.Context, itemID string, fp *model.Fingerprint) error
+}
+
+// BackupStore provides database snapshots and portable archives.
+type BackupStore interface {
+	Backup(ctx context.Context, path string) error
+	ExportArchive(ctx context.Context, w io.Writer) (ArchiveSummary, error)
 }
 
 // ItemRepository combines all item-related operations for the API layer.
@@ -53,4 +165,12 @@ type ItemRepository interface {
 	ItemWriter
 	ArtifactStore
 	IntentStore
+	ViewStore
+	TagStore
+	TodoStore
+	ArchiveRuleStore
+	WebhookStore
+	FeedStore
+	DuplicateStore
+	BackupStore
 }
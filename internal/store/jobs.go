@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+const jobColumns = `name, schedule, next_run_at, last_run_at, last_status, last_error, last_duration_ms, run_count, updated_at`
+
+// RegisterJob records a scheduled job. A new job, or one whose schedule
+// changed, gets nextRunAt; otherwise the persisted next run is kept so that a
+// restart neither re-fires nor skips it.
+func (s *Store) RegisterJob(ctx context.Context, name, schedule, nextRunAt string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO jobs (name, schedule, next_run_at, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			schedule = excluded.schedule,
+			next_run_at = excluded.next_run_at,
+			updated_at = excluded.updated_at
+		WHERE jobs.schedule != excluded.schedule`,
+		name, schedule, nextRunAt, now,
+	)
+	return err
+}
+
+// GetJob returns a job's state. It returns sql.ErrNoRows if not found.
+func (s *Store) GetJob(ctx context.Context, name string) (*model.Job, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE name = ?`, name)
+	return scanJob(row)
+}
+
+// ListJobs returns the state of every registered job ordered by name.
+func (s *Store) ListJobs(ctx context.Context) ([]model.Job, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+jobColumns+` FROM jobs ORDER BY name ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []model.Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimJobRun advances a due job's next_run_at from dueAt to nextRunAt.
+// The update only applies while next_run_at still equals dueAt, so exactly
+// one caller wins a given run. It reports whether the claim succeeded.
+func (s *Store) ClaimJobRun(ctx context.Context, name, dueAt, nextRunAt string) (bool, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE jobs SET next_run_at = ?, updated_at = ? WHERE name = ? AND next_run_at = ?`,
+		nextRunAt, now, name, dueAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// FinishJobRun records the outcome of a job run. errMsg is nil on success.
+func (s *Store) FinishJobRun(ctx context.Context, name, startedAt string, durationMS int64, errMsg *string) error {
+	status := model.JobStatusOK
+	if errMsg != nil {
+		status = model.JobStatusError
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE jobs SET last_run_at = ?, last_status = ?, last_error = ?, last_duration_ms = ?,
+			run_count = run_count + 1, updated_at = ?
+		WHERE name = ?`,
+		startedAt, status, errMsg, durationMS, now, name,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// Optimize runs SQLite's PRAGMA optimize to refresh query planner statistics.
+func (s *Store) Optimize(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `PRAGMA optimize`)
+	return err
+}
+
+func scanJob(row scanner) (*model.Job, error) {
+	var j model.Job
+	err := row.Scan(&j.Name, &j.Schedule, &j.NextRunAt, &j.LastRunAt, &j.LastStatus, &j.LastError, &j.LastDurationMS, &j.RunCount, &j.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestRegisterJob(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	if err := s.RegisterJob(ctx, "wake", "* * * * *", "2026-03-04T10:01:00Z"); err != nil {
+		t.Fatalf("RegisterJob: %v", err)
+	}
+
+	// Re-registering with the same schedule keeps the persisted next run.
+	s.RegisterJob(ctx, "wake", "* * * * *", "2026-03-04T12:00:00Z")
+	j, err := s.GetJob(ctx, "wake")
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if j.NextRunAt != "2026-03-04T10:01:00Z" {
+		t.Errorf("next_run_at = %s, want unchanged", j.NextRunAt)
+	}
+
+	// A new schedule resets it.
+	s.RegisterJob(ctx, "wake", "*/5 * * * *", "2026-03-04T10:05:00Z")
+	j, _ = s.GetJob(ctx, "wake")
+	if j.Schedule != "*/5 * * * *" || j.NextRunAt != "2026-03-04T10:05:00Z" {
+		t.Errorf("job = %+v, want new schedule and next run", j)
+	}
+
+	if _, err := s.GetJob(ctx, "missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetJob(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestClaimAndFinishJobRun(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.RegisterJob(ctx, "wake", "* * * * *", "2026-03-04T10:01:00Z")
+
+	ok, err := s.ClaimJobRun(ctx, "wake", "2026-03-04T10:01:00Z", "2026-03-04T10:02:00Z")
+	if err != nil || !ok {
+		t.Fatalf("first claim = %v, %v; want true", ok, err)
+	}
+	// A second claim on the same due time loses.
+	ok, _ = s.ClaimJobRun(ctx, "wake", "2026-03-04T10:01:00Z", "2026-03-04T10:02:00Z")
+	if ok {
+		t.Error("second claim should fail")
+	}
+
+	msg := "boom"
+	if err := s.FinishJobRun(ctx, "wake", "2026-03-04T10:01:00Z", 12, &msg); err != nil {
+		t.Fatalf("FinishJobRun: %v", err)
+	}
+	s.FinishJobRun(ctx, "wake", "2026-03-04T10:02:00Z", 3, nil)
+
+	jobs, err := s.ListJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("jobs = %d, want 1", len(jobs))
+	}
+	j := jobs[0]
+	if j.RunCount != 2 || j.LastStatus != "ok" || j.LastError != nil || j.LastDurationMS != 3 {
+		t.Errorf("job = %+v, want 2 runs, last ok", j)
+	}
+	if j.LastRunAt == nil || *j.LastRunAt != "2026-03-04T10:02:00Z" {
+		t.Errorf("last_run_at = %v", j.LastRunAt)
+	}
+
+	if err := s.FinishJobRun(ctx, "missing", "2026-03-04T10:02:00Z", 1, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FinishJobRun(missing) error = %v, want sql.ErrNoRows", err)
+	}
+}
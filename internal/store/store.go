@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -18,25 +19,46 @@ var (
 	_ ItemClaimer   = (*Store)(nil)
 	_ ArtifactStore = (*Store)(nil)
 	_ IntentStore   = (*Store)(nil)
+	_ ViewStore     = (*Store)(nil)
+	_ TagStore      = (*Store)(nil)
+	_ TodoStore     = (*Store)(nil)
 )
 
 // Store provides data access to the SQLite database.
 type Store struct {
-	db *sql.DB
+	db    *sql.DB
+	canon *model.URLCanonicalizer
 }
 
-// New creates a new Store and initialises the schema.
-func New(db *sql.DB) (*Store, error) {
-	s := &Store{db: db}
+// Option configures a Store.
+type Option func(*Store)
+
+// WithURLCanonicalizer sets how item URLs are canonicalized for duplicate
+// detection. The default drops model.DefaultTrackingParams.
+func WithURLCanonicalizer(c *model.URLCanonicalizer) Option {
+	return func(s *Store) { s.canon = c }
+}
+
+// New creates a new Store and initialises the schema. It also brings the
+// items' canonical URLs up to date with the canonicalizer, so that changed
+// tracking-parameter rules apply to existing items.
+func New(db *sql.DB, opts ...Option) (*Store, error) {
+	s := &Store{db: db, canon: model.NewURLCanonicalizer(nil)}
+	for _, o := range opts {
+		o(s)
+	}
 	if err := s.migrate(); err != nil {
 		return nil, fmt.Errorf("migrate: %w", err)
 	}
+	if err := s.refreshCanonicalURLs(context.Background()); err != nil {
+		return nil, fmt.Errorf("canonicalize urls: %w", err)
+	}
 	return s, nil
 }
 
 // currentSchemaVersion is bumped whenever the schema changes.
 // Add a new migration function in the migrations slice below.
-const currentSchemaVersion = 4
+const currentSchemaVersion = 22
 
 func (s *Store) migrate() error {
 	// Ensure the schema_version table exists.
@@ -59,10 +81,28 @@ func (s *Store) migrate() error {
 	// migrations is an ordered list of migration functions.
 	// Index 0 = migration from v0 to v1, etc.
 	migrations := []func() error{
-		s.migrateV1, // v0 → v1: initial schema
-		s.migrateV2, // v1 → v2: add save_count column
-		s.migrateV3, // v2 → v3: add intents table, migrate existing intent_text
-		s.migrateV4, // v3 → v4: rename priority values (READ_NEXT→DO_FIRST, etc.)
+		s.migrateV1,  // v0 → v1: initial schema
+		s.migrateV2,  // v1 → v2: add save_count column
+		s.migrateV3,  // v2 → v3: add intents table, migrate existing intent_text
+		s.migrateV4,  // v3 → v4: rename priority values (READ_NEXT→DO_FIRST, etc.)
+		s.migrateV5,  // v4 → v5: add views table (saved filters)
+		s.migrateV6,  // v5 → v6: add tags and item_tags tables
+		s.migrateV7,  // v6 → v7: record who applied each item tag
+		s.migrateV8,  // v7 → v8: add todos table, backfill from todos artifacts
+		s.migrateV9,  // v8 → v9: add items.completed_at for the DONE status
+		s.migrateV10, // v9 → v10: add items.snooze_until for the SNOOZED status
+		s.migrateV11, // v10 → v11: add jobs table for the maintenance scheduler
+		s.migrateV12, // v11 → v12: add auto-archive rules and log, items.restored_at
+		s.migrateV13, // v12 → v13: add webhooks and the webhook delivery outbox
+		s.migrateV14, // v13 → v14: add the vault todo sync log
+		s.migrateV15, // v14 → v15: add items.process_after for throttled imports
+		s.migrateV16, // v15 → v16: add Atom feeds
+		s.migrateV17, // v16 → v17: add items.canonical_url for duplicate detection
+		s.migrateV18, // v17 → v18: add items.resolved_url and items.duplicate_of
+		s.migrateV19, // v18 → v19: add content fingerprints, backfill from extraction artifacts
+		s.migrateV20, // v19 → v20: add proposals for artifacts the user has edited
+		s.migrateV21, // v20 → v21: add artifact version history, backfill from current artifacts
+		s.migrateV22, // v21 → v22: add items.revived_at for archived items captured again
 	}
 
 	for i := version; i < len(migrations); i++ {
@@ -190,25 +230,423 @@ func (s *Store) migrateV4() error {
 	return nil
 }
 
+// migrateV5 adds the views table for saved filters (v4 → v5).
+func (s *Store) migrateV5() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS views (
+			id         TEXT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			filter     TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);
+	`)
+	return err
+}
+
+// migrateV6 adds the tags vocabulary and the item_tags many-to-many table (v5 → v6).
+func (s *Store) migrateV6() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			name       TEXT PRIMARY KEY,
+			created_at TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS item_tags (
+			item_id    TEXT NOT NULL REFERENCES items(id),
+			tag        TEXT NOT NULL REFERENCES tags(name),
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (item_id, tag)
+		);
+		CREATE INDEX IF NOT EXISTS idx_item_tags_tag ON item_tags(tag);
+	`)
+	return err
+}
+
+// migrateV7 records whether an item tag was applied by the user or by the AI (v6 → v7).
+func (s *Store) migrateV7() error {
+	_, err := s.db.Exec(`ALTER TABLE item_tags ADD COLUMN source TEXT NOT NULL DEFAULT 'user'`)
+	return err
+}
+
+// migrateV8 adds the todos table and backfills it from existing todos artifacts (v7 → v8).
+func (s *Store) migrateV8() error {
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS todos (
+			id           TEXT PRIMARY KEY,
+			item_id      TEXT NOT NULL REFERENCES items(id),
+			title        TEXT NOT NULL,
+			eta          TEXT NOT NULL DEFAULT '',
+			type         TEXT NOT NULL DEFAULT '',
+			position     INTEGER NOT NULL DEFAULT 0,
+			done         INTEGER NOT NULL DEFAULT 0,
+			completed_at TEXT,
+			due_date     TEXT,
+			edited       INTEGER NOT NULL DEFAULT 0,
+			created_by   TEXT NOT NULL,
+			created_at   TEXT NOT NULL,
+			updated_at   TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_todos_item ON todos(item_id, position);
+		CREATE INDEX IF NOT EXISTS idx_todos_type_done ON todos(type, done);
+	`); err != nil {
+		return fmt.Errorf("create todos table: %w", err)
+	}
+
+	rows, err := s.db.Query(`SELECT item_id, payload, created_by, created_at FROM artifacts WHERE artifact_type = 'todos'`)
+	if err != nil {
+		return fmt.Errorf("read todos artifacts: %w", err)
+	}
+	defer rows.Close()
+
+	stmt, err := s.db.Prepare(`
+		INSERT INTO todos (id, item_id, title, eta, type, position, done, completed_at, edited, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare todo insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for rows.Next() {
+		var itemID, payload, createdBy, createdAt string
+		if err := rows.Scan(&itemID, &payload, &createdBy, &createdAt); err != nil {
+			return fmt.Errorf("scan todos artifact: %w", err)
+		}
+		var parsed struct {
+			Todos []struct {
+				Title string `json:"title"`
+				ETA   string `json:"eta"`
+				Type  string `json:"type"`
+				Done  bool   `json:"done"`
+			} `json:"todos"`
+		}
+		if err := json.Unmarshal([]byte(payload), &parsed); err != nil {
+			continue // malformed legacy payloads are left in the artifact only
+		}
+		edited := createdBy == model.CreatedByUser
+		for i, t := range parsed.Todos {
+			var completedAt *string
+			if t.Done {
+				completedAt = &createdAt
+			}
+			id := fmt.Sprintf("migrated-%s-%d", itemID, i)
+			if _, err := stmt.Exec(id, itemID, t.Title, t.ETA, t.Type, i, t.Done, completedAt, edited || t.Done, createdBy, createdAt, createdAt); err != nil {
+				return fmt.Errorf("insert migrated todo: %w", err)
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// migrateV9 adds the completed_at column used by the DONE status (v8 → v9).
+func (s *Store) migrateV9() error {
+	_, err := s.db.Exec(`ALTER TABLE items ADD COLUMN completed_at TEXT`)
+	return err
+}
+
+// migrateV10 adds the snooze_until column used by the SNOOZED status (v9 → v10).
+func (s *Store) migrateV10() error {
+	_, err := s.db.Exec(`
+		ALTER TABLE items ADD COLUMN snooze_until TEXT;
+		CREATE INDEX IF NOT EXISTS idx_items_snooze ON items(status, snooze_until);
+	`)
+	return err
+}
+
+// migrateV11 adds the jobs table holding scheduler run state (v10 → v11).
+func (s *Store) migrateV11() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS jobs (
+			name             TEXT PRIMARY KEY,
+			schedule         TEXT NOT NULL,
+			next_run_at      TEXT NOT NULL,
+			last_run_at      TEXT,
+			last_status      TEXT NOT NULL DEFAULT '',
+			last_error       TEXT,
+			last_duration_ms INTEGER NOT NULL DEFAULT 0,
+			run_count        INTEGER NOT NULL DEFAULT 0,
+			updated_at       TEXT NOT NULL
+		);
+	`)
+	return err
+}
+
+// migrateV12 adds auto-archive rules, the auto-archive log and the
+// restored_at column that protects user-restored items (v11 → v12).
+func (s *Store) migrateV12() error {
+	_, err := s.db.Exec(`
+		ALTER TABLE items ADD COLUMN restored_at TEXT;
+
+		CREATE TABLE IF NOT EXISTS archive_rules (
+			id         TEXT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			priorities TEXT NOT NULL,
+			basis      TEXT NOT NULL,
+			days       INTEGER NOT NULL,
+			enabled    INTEGER NOT NULL DEFAULT 1,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS auto_archives (
+			id              TEXT PRIMARY KEY,
+			item_id         TEXT NOT NULL REFERENCES items(id),
+			rule_id         TEXT NOT NULL,
+			reason          TEXT NOT NULL,
+			previous_status TEXT NOT NULL,
+			archived_at     TEXT NOT NULL,
+			undone_at       TEXT
+		);
+		CREATE INDEX IF NOT EXISTS idx_auto_archives_item ON auto_archives(item_id);
+	`)
+	return err
+}
+
+// migrateV13 adds webhook subscriptions and their delivery outbox, which
+// doubles as the delivery log (v12 → v13).
+func (s *Store) migrateV13() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id         TEXT PRIMARY KEY,
+			url        TEXT NOT NULL,
+			events     TEXT NOT NULL,
+			secret     TEXT NOT NULL,
+			enabled    INTEGER NOT NULL DEFAULT 1,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id               TEXT PRIMARY KEY,
+			webhook_id       TEXT NOT NULL REFERENCES webhooks(id),
+			event_type       TEXT NOT NULL,
+			item_id          TEXT NOT NULL,
+			payload          TEXT NOT NULL,
+			status           TEXT NOT NULL,
+			attempts         INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at  TEXT NOT NULL,
+			last_status_code INTEGER,
+			last_error       TEXT,
+			created_at       TEXT NOT NULL,
+			updated_at       TEXT NOT NULL,
+			delivered_at     TEXT
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
+	`)
+	return err
+}
+
+// migrateV14 adds the log of todo done states reconciled between vault
+// notes and the database (v13 → v14).
+func (s *Store) migrateV14() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS todo_syncs (
+			id               TEXT PRIMARY KEY,
+			todo_id          TEXT NOT NULL,
+			item_id          TEXT NOT NULL,
+			file             TEXT NOT NULL,
+			file_done        INTEGER NOT NULL,
+			api_done         INTEGER NOT NULL,
+			file_modified_at TEXT NOT NULL,
+			todo_updated_at  TEXT NOT NULL,
+			winner           TEXT NOT NULL,
+			created_at       TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_todo_syncs_item ON todo_syncs(item_id, created_at);
+	`)
+	return err
+}
+
+// migrateV15 adds process_after, which holds back imported items so that
+// the worker picks them up at a throttled rate (v14 → v15).
+func (s *Store) migrateV15() error {
+	_, err := s.db.Exec(`ALTER TABLE items ADD COLUMN process_after TEXT`)
+	return err
+}
+
+// migrateV16 adds the feeds table; tokens are stored hashed (v15 → v16).
+func (s *Store) migrateV16() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS feeds (
+			id         TEXT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			view_id    TEXT,
+			priority   TEXT NOT NULL,
+			tags       TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		);
+	`)
+	return err
+}
+
+// migrateV17 adds canonical_url, which capture matches duplicates on
+// (v16 → v17). New fills it in for existing items.
+func (s *Store) migrateV17() error {
+	_, err := s.db.Exec(`
+		ALTER TABLE items ADD COLUMN canonical_url TEXT NOT NULL DEFAULT '';
+		CREATE INDEX IF NOT EXISTS idx_items_canonical_url ON items(canonical_url);
+	`)
+	return err
+}
+
+// migrateV18 adds resolved_url, where extraction found an item's URL to
+// lead, and duplicate_of, the item it is suspected to duplicate or empty once
+// the user dismissed the suspicion (v17 → v18).
+func (s *Store) migrateV18() error {
+	_, err := s.db.Exec(`
+		ALTER TABLE items ADD COLUMN resolved_url TEXT NOT NULL DEFAULT '';
+		ALTER TABLE items ADD COLUMN duplicate_of TEXT;
+		CREATE INDEX IF NOT EXISTS idx_items_resolved_url ON items(resolved_url);
+	`)
+	return err
+}
+
+// migrateV19 adds the fingerprints table, the SimHash of each item's
+// extracted text split into indexed bands for near-duplicate lookup, and
+// fingerprints the existing extraction artifacts (v18 → v19).
+func (s *Store) migrateV19() error {
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS fingerprints (
+			item_id TEXT PRIMARY KEY REFERENCES items(id),
+			simhash INTEGER NOT NULL,
+			band0   INTEGER NOT NULL,
+			band1   INTEGER NOT NULL,
+			band2   INTEGER NOT NULL,
+			band3   INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_fingerprints_band0 ON fingerprints(band0);
+		CREATE INDEX IF NOT EXISTS idx_fingerprints_band1 ON fingerprints(band1);
+		CREATE INDEX IF NOT EXISTS idx_fingerprints_band2 ON fingerprints(band2);
+		CREATE INDEX IF NOT EXISTS idx_fingerprints_band3 ON fingerprints(band3);
+	`); err != nil {
+		return fmt.Errorf("create fingerprints table: %w", err)
+	}
+
+	rows, err := s.db.Query(`SELECT item_id, payload FROM artifacts WHERE artifact_type = 'extraction'`)
+	if err != nil {
+		return fmt.Errorf("read extraction artifacts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var itemID, payload string
+		if err := rows.Scan(&itemID, &payload); err != nil {
+			return fmt.Errorf("scan extraction artifact: %w", err)
+		}
+		if err := setFingerprint(context.Background(), s.db, itemID, extractionFingerprint(payload)); err != nil {
+			return fmt.Errorf("insert fingerprint: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// migrateV20 adds the artifact_proposals table, which holds a newly
+// generated version of an artifact the user has edited until the user
+// accepts or rejects it (v19 → v20).
+func (s *Store) migrateV20() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS artifact_proposals (
+			id            TEXT PRIMARY KEY,
+			item_id       TEXT NOT NULL REFERENCES items(id),
+			artifact_type TEXT NOT NULL,
+			payload       TEXT NOT NULL,
+			created_at    TEXT NOT NULL
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_artifact_proposals_unique ON artifact_proposals(item_id, artifact_type);
+	`)
+	return err
+}
+
+// migrateV21 adds the append-only artifact_versions table, recording every
+// generation and edit of an artifact with the model and prompt version that
+// produced it, and numbers the current artifacts and proposals as the first
+// versions of their histories (v20 → v21).
+func (s *Store) migrateV21() error {
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS artifact_versions (
+			item_id        TEXT NOT NULL REFERENCES items(id),
+			artifact_type  TEXT NOT NULL,
+			version        INTEGER NOT NULL,
+			id             TEXT NOT NULL,
+			payload        TEXT NOT NULL,
+			created_by     TEXT NOT NULL,
+			model          TEXT NOT NULL DEFAULT '',
+			prompt_version TEXT NOT NULL DEFAULT '',
+			created_at     TEXT NOT NULL,
+			PRIMARY KEY (item_id, artifact_type, version)
+		);
+		ALTER TABLE artifacts ADD COLUMN model TEXT NOT NULL DEFAULT '';
+		ALTER TABLE artifacts ADD COLUMN prompt_version TEXT NOT NULL DEFAULT '';
+		ALTER TABLE artifacts ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE artifact_proposals ADD COLUMN model TEXT NOT NULL DEFAULT '';
+		ALTER TABLE artifact_proposals ADD COLUMN prompt_version TEXT NOT NULL DEFAULT '';
+		ALTER TABLE artifact_proposals ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+	`); err != nil {
+		return fmt.Errorf("create artifact_versions table: %w", err)
+	}
+
+	// A proposal was generated after the user's edit it would replace.
+	backfill := []struct {
+		query string
+		args  []any
+	}{
+		{`INSERT INTO artifact_versions (item_id, artifact_type, version, id, payload, created_by, created_at)
+		  SELECT item_id, artifact_type, 1, id, payload, created_by, created_at FROM artifacts WHERE artifact_type != ?`,
+			[]any{model.ArtifactExtraction}},
+		{`UPDATE artifacts SET version = 1 WHERE artifact_type != ?`, []any{model.ArtifactExtraction}},
+		{`INSERT INTO artifact_versions (item_id, artifact_type, version, id, payload, created_by, created_at)
+		  SELECT item_id, artifact_type, 2, id, payload, ?, created_at FROM artifact_proposals`,
+			[]any{model.CreatedBySystem}},
+		{`UPDATE artifact_proposals SET version = 2`, nil},
+	}
+	for _, b := range backfill {
+		if _, err := s.db.Exec(b.query, b.args...); err != nil {
+			return fmt.Errorf("backfill artifact versions: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateV22 adds revived_at, which records when a repeated capture brought
+// an archived item back (v21 → v22).
+func (s *Store) migrateV22() error {
+	_, err := s.db.Exec(`ALTER TABLE items ADD COLUMN revived_at TEXT`)
+	return err
+}
+
 // ---------------------------------------------------------------------------
 // Items
 // ---------------------------------------------------------------------------
 
-// CreateItem inserts a new item.
-func (s *Store) CreateItem(ctx context.Context, item model.Item) error {
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO items (id, url, title, domain, source_type, intent_text, status, priority, match_score, error_info, save_count, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+// CreateItem inserts a new item, and in the same transaction the intents it
+// was captured with. Its canonical URL is derived from its URL.
+func (s *Store) CreateItem(ctx context.Context, item model.Item, intents ...model.Intent) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO items (`+itemColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		item.ID, item.URL, item.Title, item.Domain, item.SourceType, item.IntentText,
 		item.Status, item.Priority, item.MatchScore, item.ErrorInfo, item.SaveCount,
-		item.CreatedAt, item.UpdatedAt,
-	)
-	return err
+		item.CreatedAt, item.UpdatedAt, item.CompletedAt, item.SnoozeUntil, item.RestoredAt, item.ProcessAfter,
+		s.canon.Canonicalize(item.URL), item.ResolvedURL, item.DuplicateOf, item.RevivedAt,
+	); err != nil {
+		return err
+	}
+	if err := insertIntents(ctx, tx, intents); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetItem returns an item together with its artifacts and intents.
 func (s *Store) GetItem(ctx context.Context, id string) (*model.ItemWithArtifacts, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT id, url, title, domain, source_type, intent_text, status, priority, match_score, error_info, save_count, created_at, updated_at FROM items WHERE id = ?`, id)
+	row := s.db.QueryRowContext(ctx, `SELECT `+itemColumns+` FROM items WHERE id = ?`, id)
 	item, err := scanItem(row)
 	if err != nil {
 		return nil, err
@@ -220,65 +658,135 @@ func (s *Store) GetItem(ctx context.Context, id string) (*model.ItemWithArtifact
 	}
 
 	// Intents may not exist yet if migration v3 hasn't run; treat as empty.
-	intents, _ := s.listIntents(ctx, id)
+	intents, _ := s.ListIntents(ctx, id)
 
-	return &model.ItemWithArtifacts{Item: *item, Artifacts: artifacts, Intents: intents}, nil
+	tags, err := s.ListItemTags(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	todos, err := s.ListTodos(ctx, model.TodoFilter{ItemID: id})
+	if err != nil {
+		return nil, fmt.Errorf("list todos: %w", err)
+	}
+
+	proposals, err := s.listArtifactProposals(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list artifact proposals: %w", err)
+	}
+
+	return &model.ItemWithArtifacts{Item: *item, Artifacts: artifacts, Proposals: proposals, Intents: intents, Tags: tags, Todos: todos}, nil
 }
 
 // ListItems returns items matching the given filter, ordered by priority/score.
 func (s *Store) ListItems(ctx context.Context, f model.ItemFilter) ([]model.Item, error) {
-	query := `SELECT id, url, title, domain, source_type, intent_text, status, priority, match_score, error_info, save_count, created_at, updated_at FROM items`
+	query := `SELECT ` + itemColumns + ` FROM items`
+	where, args, err := buildItemWhere(f)
+	if err != nil {
+		return nil, err
+	}
+	query += where
+	query += " ORDER BY CASE status WHEN 'PROCESSING' THEN 0 WHEN 'CAPTURED' THEN 1 WHEN 'FAILED' THEN 2 WHEN 'READY' THEN 3 ELSE 4 END, COALESCE(match_score, 0) DESC, updated_at DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// CountItems returns the number of items matching the given filter.
+func (s *Store) CountItems(ctx context.Context, f model.ItemFilter) (int, error) {
+	where, args, err := buildItemWhere(f)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM items`+where, args...).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// buildItemWhere translates an ItemFilter into a WHERE clause (with leading space)
+// and its positional arguments. It returns an empty clause for an empty filter.
+func buildItemWhere(f model.ItemFilter) (string, []interface{}, error) {
 	var conditions []string
 	var args []interface{}
 
 	if len(f.Status) > 0 {
-		placeholders := make([]string, len(f.Status))
-		for i, st := range f.Status {
-			placeholders[i] = "?"
+		conditions = append(conditions, "status IN ("+placeholders(len(f.Status))+")")
+		for _, st := range f.Status {
 			args = append(args, st)
 		}
-		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ",")+") ")
+	} else {
+		// Snoozed items stay out of sight unless explicitly requested.
+		conditions = append(conditions, "status != ?")
+		args = append(args, model.StatusSnoozed)
 	}
 	if len(f.Priority) > 0 {
-		placeholders := make([]string, len(f.Priority))
-		for i, p := range f.Priority {
-			placeholders[i] = "?"
+		conditions = append(conditions, "priority IN ("+placeholders(len(f.Priority))+")")
+		for _, p := range f.Priority {
 			args = append(args, p)
 		}
-		conditions = append(conditions, "priority IN ("+strings.Join(placeholders, ",")+") ")
 	}
 	if f.Query != "" {
 		like := "%" + f.Query + "%"
 		conditions = append(conditions, "(title LIKE ? OR domain LIKE ? OR intent_text LIKE ?)")
 		args = append(args, like, like, like)
 	}
-
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	for _, tags := range [][]string{f.Tags, f.AlsoTags} {
+		if len(tags) == 0 {
+			continue
+		}
+		conditions = append(conditions, "id IN (SELECT item_id FROM item_tags WHERE tag IN ("+placeholders(len(tags))+"))")
+		for _, t := range tags {
+			args = append(args, t)
+		}
 	}
-	query += " ORDER BY CASE status WHEN 'PROCESSING' THEN 0 WHEN 'CAPTURED' THEN 1 WHEN 'FAILED' THEN 2 WHEN 'READY' THEN 3 ELSE 4 END, COALESCE(match_score, 0) DESC, updated_at DESC"
-
-	rows, err := s.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
+	if f.Within != "" {
+		d, err := model.ParseWithin(f.Within)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, "updated_at >= ?")
+		args = append(args, time.Now().UTC().Add(-d).Format(time.RFC3339))
 	}
-	defer rows.Close()
 
-	var items []model.Item
-	for rows.Next() {
-		var item model.Item
-		if err := rows.Scan(&item.ID, &item.URL, &item.Title, &item.Domain, &item.SourceType, &item.IntentText, &item.Status, &item.Priority, &item.MatchScore, &item.ErrorInfo, &item.SaveCount, &item.CreatedAt, &item.UpdatedAt); err != nil {
-			return nil, err
-		}
-		items = append(items, item)
+	if len(conditions) == 0 {
+		return "", args, nil
 	}
-	return items, rows.Err()
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
 }
 
+// completedAtExpr computes items.completed_at for a status change: it is stamped
+// on entering DONE, kept when archiving, and cleared for any other status.
+// It expects the target status twice followed by the timestamp.
+const completedAtExpr = `CASE WHEN ? = 'DONE' THEN COALESCE(completed_at, ?) WHEN ? = 'ARCHIVED' THEN completed_at ELSE NULL END`
+
+// restoredAtExpr computes items.restored_at for a status change: it is stamped
+// when an ARCHIVED item moves back to READY, which exempts it from
+// auto-archive rules. It expects the target status followed by the timestamp.
+const restoredAtExpr = `CASE WHEN status = 'ARCHIVED' AND ? = 'READY' THEN ? ELSE restored_at END`
+
 // UpdateItemStatus changes the status of an item.
 func (s *Store) UpdateItemStatus(ctx context.Context, id, newStatus string, errorInfo *string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := s.db.ExecContext(ctx, `UPDATE items SET status = ?, error_info = ?, updated_at = ? WHERE id = ?`, newStatus, errorInfo, now, id)
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE items SET status = ?, error_info = ?, updated_at = ?, snooze_until = NULL,
+			completed_at = `+completedAtExpr+`, restored_at = `+restoredAtExpr+` WHERE id = ?`,
+		newStatus, errorInfo, now, newStatus, now, newStatus, newStatus, now, id,
+	)
 	return err
 }
 
@@ -295,9 +803,13 @@ func (s *Store) ClaimNextCaptured(ctx context.Context) (*model.Item, error) {
 	now := time.Now().UTC().Format(time.RFC3339)
 	row := s.db.QueryRowContext(ctx, `
 		UPDATE items SET status = ?, updated_at = ?
-		WHERE id = (SELECT id FROM items WHERE status = ? ORDER BY created_at ASC LIMIT 1)
-		RETURNING id, url, title, domain, source_type, intent_text, status, priority, match_score, error_info, save_count, created_at, updated_at`,
-		model.StatusProcessing, now, model.StatusCaptured,
+		WHERE id = (
+			SELECT id FROM items
+			WHERE status = ? AND (process_after IS NULL OR process_after <= ?)
+			ORDER BY COALESCE(process_after, created_at) ASC LIMIT 1
+		)
+		RETURNING `+itemColumns,
+		model.StatusProcessing, now, model.StatusCaptured, now,
 	)
 	item, err := scanItem(row)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -306,12 +818,14 @@ func (s *Store) ClaimNextCaptured(ctx context.Context) (*model.Item, error) {
 	return item, err
 }
 
-// FindItemByURL returns an active (non-ARCHIVED) item with the given URL, or nil if not found.
+// FindItemByURL returns an active (non-ARCHIVED) item whose URL, or the URL
+// it was resolved to, has the same canonical form as url.
 func (s *Store) FindItemByURL(ctx context.Context, url string) (*model.Item, error) {
+	canonical := s.canon.Canonicalize(url)
 	row := s.db.QueryRowContext(ctx,
-		`SELECT id, url, title, domain, source_type, intent_text, status, priority, match_score, error_info, save_count, created_at, updated_at
-		 FROM items WHERE url = ? AND status != ? ORDER BY created_at DESC LIMIT 1`,
-		url, model.StatusArchived,
+		`SELECT `+itemColumns+`
+		 FROM items WHERE (canonical_url = ? OR resolved_url = ?) AND status != ? ORDER BY created_at DESC LIMIT 1`,
+		canonical, canonical, model.StatusArchived,
 	)
 	item, err := scanItem(row)
 	if err != nil {
@@ -320,17 +834,88 @@ func (s *Store) FindItemByURL(ctx context.Context, url string) (*model.Item, err
 	return item, nil
 }
 
+// FindArchivedItemByURL returns the most recently archived item whose URL,
+// or the URL it was resolved to, has the same canonical form as url.
+func (s *Store) FindArchivedItemByURL(ctx context.Context, url string) (*model.Item, error) {
+	canonical := s.canon.Canonicalize(url)
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+itemColumns+`
+		 FROM items WHERE (canonical_url = ? OR resolved_url = ?) AND status = ? ORDER BY updated_at DESC LIMIT 1`,
+		canonical, canonical, model.StatusArchived,
+	)
+	return scanItem(row)
+}
+
+// ReviveItem brings an ARCHIVED item back for a repeated capture: like
+// UpdateItemForReprocess, and it stamps revived_at to record the revival and
+// restored_at so that auto-archive rules leave the item alone. It returns
+// sql.ErrNoRows if the item is not ARCHIVED.
+func (s *Store) ReviveItem(ctx context.Context, id, intentText string, saveCount int, intents ...model.Intent) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return s.recapture(ctx, intents,
+		`UPDATE items SET intent_text = ?, save_count = ?, status = ?, error_info = NULL, completed_at = NULL, snooze_until = NULL,
+			process_after = NULL, restored_at = ?, revived_at = ?, updated_at = ? WHERE id = ? AND status = ?`,
+		intentText, saveCount, model.StatusCaptured, now, now, now, id, model.StatusArchived,
+	)
+}
+
 // UpdateItemForReprocess merges the new intent, increments save_count, and resets the
-// item to CAPTURED status so it will be re-processed by the pipeline.
-func (s *Store) UpdateItemForReprocess(ctx context.Context, id, intentText string, saveCount int) error {
+// item to CAPTURED status so it will be re-processed by the pipeline. The
+// intents of the new capture are inserted in the same transaction. It
+// returns sql.ErrNoRows if the item does not exist.
+func (s *Store) UpdateItemForReprocess(ctx context.Context, id, intentText string, saveCount int, intents ...model.Intent) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := s.db.ExecContext(ctx,
-		`UPDATE items SET intent_text = ?, save_count = ?, status = ?, error_info = NULL, updated_at = ? WHERE id = ?`,
+	return s.recapture(ctx, intents,
+		`UPDATE items SET intent_text = ?, save_count = ?, status = ?, error_info = NULL, completed_at = NULL, snooze_until = NULL, process_after = NULL, updated_at = ? WHERE id = ?`,
 		intentText, saveCount, model.StatusCaptured, now, id,
 	)
+}
+
+// recapture runs the item update query and inserts intents in one
+// transaction.
+func (s *Store) recapture(ctx context.Context, intents []model.Intent, query string, args ...any) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	if err := insertIntents(ctx, tx, intents); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeferProcessing keeps a CAPTURED item from being claimed before at
+// (RFC 3339). It has no effect on items in any other status.
+func (s *Store) DeferProcessing(ctx context.Context, id, at string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE items SET process_after = ? WHERE id = ? AND status = ?`,
+		at, id, model.StatusCaptured,
+	)
 	return err
 }
 
+// LatestProcessAfter returns the latest process_after of the CAPTURED items,
+// or "" if none is deferred, so that a new import queues behind earlier ones.
+func (s *Store) LatestProcessAfter(ctx context.Context) (string, error) {
+	var latest sql.NullString
+	err := s.db.QueryRowContext(ctx,
+		`SELECT MAX(process_after) FROM items WHERE status = ?`, model.StatusCaptured,
+	).Scan(&latest)
+	if err != nil {
+		return "", err
+	}
+	return latest.String, nil
+}
+
 // ResetStaleProcessing resets any PROCESSING items back to CAPTURED (for server restart).
 func (s *Store) ResetStaleProcessing(ctx context.Context) (int64, error) {
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -341,7 +926,7 @@ func (s *Store) ResetStaleProcessing(ctx context.Context) (int64, error) {
 	return res.RowsAffected()
 }
 
-// DeleteItem removes an item and its associated artifacts and intents.
+// DeleteItem removes an item and its associated artifacts, intents and tag links.
 func (s *Store) DeleteItem(ctx context.Context, id string) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -349,48 +934,71 @@ func (s *Store) DeleteItem(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback()
 
+	if err := deleteItemTx(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteItemTx deletes an item and everything attached to it within tx.
+func deleteItemTx(ctx context.Context, tx *sql.Tx, id string) error {
 	if _, err := tx.ExecContext(ctx, `DELETE FROM intents WHERE item_id = ?`, id); err != nil {
 		return fmt.Errorf("delete intents: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM item_tags WHERE item_id = ?`, id); err != nil {
+		return fmt.Errorf("delete item tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM todos WHERE item_id = ?`, id); err != nil {
+		return fmt.Errorf("delete todos: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM auto_archives WHERE item_id = ?`, id); err != nil {
+		return fmt.Errorf("delete auto-archives: %w", err)
+	}
 	if _, err := tx.ExecContext(ctx, `DELETE FROM artifacts WHERE item_id = ?`, id); err != nil {
 		return fmt.Errorf("delete artifacts: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM artifact_proposals WHERE item_id = ?`, id); err != nil {
+		return fmt.Errorf("delete artifact proposals: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM artifact_versions WHERE item_id = ?`, id); err != nil {
+		return fmt.Errorf("delete artifact versions: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM fingerprints WHERE item_id = ?`, id); err != nil {
+		return fmt.Errorf("delete fingerprint: %w", err)
+	}
 	if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE id = ?`, id); err != nil {
 		return fmt.Errorf("delete item: %w", err)
 	}
-
-	return tx.Commit()
+	return nil
 }
 
-// BatchUpdateStatus changes the status of multiple items at once.
-func (s *Store) BatchUpdateStatus(ctx context.Context, ids []string, status string) (int64, error) {
+// BatchUpdateStatus changes the status of multiple items at once. Items
+// already in that status are left untouched; it returns the IDs of the
+// items it changed.
+func (s *Store) BatchUpdateStatus(ctx context.Context, ids []string, status string) ([]string, error) {
 	if len(ids) == 0 {
-		return 0, nil
+		return nil, nil
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	placeholders := make([]string, len(ids))
-	args := make([]interface{}, 0, len(ids)+2)
-	args = append(args, status, now)
-	for i, id := range ids {
-		placeholders[i] = "?"
+	args := make([]interface{}, 0, len(ids)+8)
+	args = append(args, status, now, status, now, status, status, now, status)
+	for _, id := range ids {
 		args = append(args, id)
 	}
-	query := fmt.Sprintf(`UPDATE items SET status = ?, updated_at = ? WHERE id IN (%s)`, strings.Join(placeholders, ","))
-	res, err := s.db.ExecContext(ctx, query, args...)
-	if err != nil {
-		return 0, err
-	}
-	return res.RowsAffected()
+	query := fmt.Sprintf(`UPDATE items SET status = ?, updated_at = ?, snooze_until = NULL,
+		completed_at = `+completedAtExpr+`, restored_at = `+restoredAtExpr+` WHERE status != ? AND id IN (%s) RETURNING id`, placeholders(len(ids)))
+	return queryIDs(ctx, s.db, query, args...)
 }
 
-// BatchDeleteItems removes multiple items and their associated data.
-func (s *Store) BatchDeleteItems(ctx context.Context, ids []string) (int64, error) {
+// BatchDeleteItems removes multiple items and their associated data. It
+// returns the IDs of the items it deleted.
+func (s *Store) BatchDeleteItems(ctx context.Context, ids []string) ([]string, error) {
 	if len(ids) == 0 {
-		return 0, nil
+		return nil, nil
 	}
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return 0, fmt.Errorf("begin tx: %w", err)
+		return nil, fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 
@@ -403,107 +1011,123 @@ func (s *Store) BatchDeleteItems(ctx context.Context, ids []string) (int64, erro
 	inClause := strings.Join(placeholders, ",")
 
 	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM intents WHERE item_id IN (%s)`, inClause), args...); err != nil {
-		return 0, fmt.Errorf("delete intents: %w", err)
+		return nil, fmt.Errorf("delete intents: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM item_tags WHERE item_id IN (%s)`, inClause), args...); err != nil {
+		return nil, fmt.Errorf("delete item tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM todos WHERE item_id IN (%s)`, inClause), args...); err != nil {
+		return nil, fmt.Errorf("delete todos: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM auto_archives WHERE item_id IN (%s)`, inClause), args...); err != nil {
+		return nil, fmt.Errorf("delete auto-archives: %w", err)
 	}
 	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM artifacts WHERE item_id IN (%s)`, inClause), args...); err != nil {
-		return 0, fmt.Errorf("delete artifacts: %w", err)
+		return nil, fmt.Errorf("delete artifacts: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM artifact_proposals WHERE item_id IN (%s)`, inClause), args...); err != nil {
+		return nil, fmt.Errorf("delete artifact proposals: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM artifact_versions WHERE item_id IN (%s)`, inClause), args...); err != nil {
+		return nil, fmt.Errorf("delete artifact versions: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM fingerprints WHERE item_id IN (%s)`, inClause), args...); err != nil {
+		return nil, fmt.Errorf("delete fingerprints: %w", err)
 	}
-	res, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM items WHERE id IN (%s)`, inClause), args...)
+	deleted, err := queryIDs(ctx, tx, fmt.Sprintf(`DELETE FROM items WHERE id IN (%s) RETURNING id`, inClause), args...)
 	if err != nil {
-		return 0, fmt.Errorf("delete items: %w", err)
+		return nil, fmt.Errorf("delete items: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return 0, err
+		return nil, err
 	}
-	return res.RowsAffected()
+	return deleted, nil
+}
+
+// SnoozeItems moves READY items to SNOOZED until the given RFC 3339 time.
+// Items in any other status are left untouched; it returns the IDs of the
+// items it snoozed.
+func (s *Store) SnoozeItems(ctx context.Context, ids []string, until string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	args := make([]interface{}, 0, len(ids)+4)
+	args = append(args, model.StatusSnoozed, until, now, model.StatusReady)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return queryIDs(ctx, s.db,
+		fmt.Sprintf(`UPDATE items SET status = ?, snooze_until = ?, updated_at = ? WHERE status = ? AND id IN (%s) RETURNING id`, placeholders(len(ids))),
+		args...,
+	)
+}
+
+// WakeSnoozedItems returns SNOOZED items whose snooze_until has passed to
+// READY. It returns the IDs of the items it woke.
+func (s *Store) WakeSnoozedItems(ctx context.Context, now time.Time) ([]string, error) {
+	ts := now.UTC().Format(time.RFC3339)
+	return queryIDs(ctx, s.db,
+		`UPDATE items SET status = ?, snooze_until = NULL, updated_at = ? WHERE status = ? AND snooze_until <= ? RETURNING id`,
+		model.StatusReady, ts, model.StatusSnoozed, ts,
+	)
 }
 
-// CountByStatus returns the number of inbox (non-ARCHIVED) and archived items.
+// CountByStatus returns the number of inbox (not ARCHIVED, DONE or SNOOZED),
+// archived, done and snoozed items.
 func (s *Store) CountByStatus(ctx context.Context) (StatusCounts, error) {
 	var counts StatusCounts
 	row := s.db.QueryRowContext(ctx, `
 		SELECT
-			COALESCE(SUM(CASE WHEN status != ? THEN 1 ELSE 0 END), 0),
-			COALESCE(SUM(CASE WHEN status  = ? THEN 1 ELSE 0 END), 0)
-		FROM items`, model.StatusArchived, model.StatusArchived)
-	if err := row.Scan(&counts.Inbox, &counts.Archive); err != nil {
+			COALESCE(SUM(CASE WHEN status NOT IN (?, ?, ?) THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0)
+		FROM items`,
+		model.StatusArchived, model.StatusDone, model.StatusSnoozed,
+		model.StatusArchived, model.StatusDone, model.StatusSnoozed)
+	if err := row.Scan(&counts.Inbox, &counts.Archive, &counts.Done, &counts.Snoozed); err != nil {
 		return counts, err
 	}
 	return counts, nil
 }
 
 // ---------------------------------------------------------------------------
-// Artifacts
+// helpers
 // ---------------------------------------------------------------------------
 
-// UpsertArtifact inserts or replaces an artifact (one per item per type).
-func (s *Store) UpsertArtifact(ctx context.Context, a model.Artifact) error {
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO artifacts (id, item_id, artifact_type, payload, created_by, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-		ON CONFLICT(item_id, artifact_type) DO UPDATE SET
-			id = excluded.id,
-			payload = excluded.payload,
-			created_by = excluded.created_by,
-			created_at = excluded.created_at`,
-		a.ID, a.ItemID, a.ArtifactType, a.Payload, a.CreatedBy, a.CreatedAt,
-	)
-	return err
+// placeholders returns n comma-separated "?" placeholders for an IN clause.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
 }
 
-func (s *Store) listArtifacts(ctx context.Context, itemID string) ([]model.Artifact, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, item_id, artifact_type, payload, created_by, created_at FROM artifacts WHERE item_id = ? ORDER BY created_at ASC`, itemID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var artifacts []model.Artifact
-	for rows.Next() {
-		var a model.Artifact
-		if err := rows.Scan(&a.ID, &a.ItemID, &a.ArtifactType, &a.Payload, &a.CreatedBy, &a.CreatedAt); err != nil {
-			return nil, err
-		}
-		artifacts = append(artifacts, a)
-	}
-	return artifacts, rows.Err()
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 }
 
-// ---------------------------------------------------------------------------
-// Intents
-// ---------------------------------------------------------------------------
-
-// CreateIntent inserts a new intent record.
-func (s *Store) CreateIntent(ctx context.Context, intent model.Intent) error {
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO intents (id, item_id, text, created_at) VALUES (?, ?, ?, ?)`,
-		intent.ID, intent.ItemID, intent.Text, intent.CreatedAt,
-	)
-	return err
-}
-
-// listIntents returns all intents for an item, ordered by creation time.
-func (s *Store) listIntents(ctx context.Context, itemID string) ([]model.Intent, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, item_id, text, created_at FROM intents WHERE item_id = ? ORDER BY created_at ASC`, itemID)
+// queryIDs runs a query returning a single ID column, such as a statement
+// with RETURNING id, and collects the IDs.
+func queryIDs(ctx context.Context, q queryer, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var intents []model.Intent
+
+	var ids []string
 	for rows.Next() {
-		var i model.Intent
-		if err := rows.Scan(&i.ID, &i.ItemID, &i.Text, &i.CreatedAt); err != nil {
+		var id string
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		intents = append(intents, i)
+		ids = append(ids, id)
 	}
-	return intents, rows.Err()
+	return ids, rows.Err()
 }
 
-// ---------------------------------------------------------------------------
-// helpers
-// ---------------------------------------------------------------------------
+// itemColumns is the column list matching scanItem.
+const itemColumns = `id, url, title, domain, source_type, intent_text, status, priority, match_score, error_info, save_count, created_at, updated_at, completed_at, snooze_until, restored_at, process_after, canonical_url, resolved_url, duplicate_of, revived_at`
 
 type scanner interface {
 	Scan(dest ...interface{}) error
@@ -511,9 +1135,12 @@ type scanner interface {
 
 func scanItem(row scanner) (*model.Item, error) {
 	var item model.Item
-	err := row.Scan(&item.ID, &item.URL, &item.Title, &item.Domain, &item.SourceType, &item.IntentText, &item.Status, &item.Priority, &item.MatchScore, &item.ErrorInfo, &item.SaveCount, &item.CreatedAt, &item.UpdatedAt)
+	err := row.Scan(&item.ID, &item.URL, &item.Title, &item.Domain, &item.SourceType, &item.IntentText, &item.Status, &item.Priority, &item.MatchScore, &item.ErrorInfo, &item.SaveCount, &item.CreatedAt, &item.UpdatedAt, &item.CompletedAt, &item.SnoozeUntil, &item.RestoredAt, &item.ProcessAfter, &item.CanonicalURL, &item.ResolvedURL, &item.DuplicateOf, &item.RevivedAt)
 	if err != nil {
 		return nil, err
 	}
+	if item.DuplicateOf != nil && *item.DuplicateOf == "" {
+		item.DuplicateOf = nil // a dismissed suspicion
+	}
 	return &item, nil
 }
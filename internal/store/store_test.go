@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -179,6 +180,57 @@ func TestClaimNextCaptured(t *testing.T) {
 	}
 }
 
+func TestClaimNextCaptured_Deferred(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	// An imported item saved years ago still waits for its turn, and a
+	// deferred item that is due is claimed in process_after order.
+	old := makeItem("old", "https://example.com/old")
+	old.CreatedAt = "2019-01-01T00:00:00Z"
+	old.ProcessAfter = &future
+	due := makeItem("due", "https://example.com/due")
+	due.ProcessAfter = &past
+	for _, item := range []model.Item{old, due} {
+		if err := s.CreateItem(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := s.LatestProcessAfter(ctx)
+	if err != nil || latest != future {
+		t.Errorf("LatestProcessAfter = %q, %v; want %q", latest, err, future)
+	}
+
+	claimed, err := s.ClaimNextCaptured(ctx)
+	if err != nil || claimed == nil || claimed.ID != "due" {
+		t.Fatalf("ClaimNextCaptured = %v, %v; want due", claimed, err)
+	}
+	if claimed, _ := s.ClaimNextCaptured(ctx); claimed != nil {
+		t.Errorf("claimed %s before its process_after", claimed.ID)
+	}
+
+	// Re-capturing clears the deferral; DeferProcessing sets it again.
+	if err := s.UpdateItemForReprocess(ctx, "old", "", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeferProcessing(ctx, "old", future); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, _ := s.ClaimNextCaptured(ctx); claimed != nil {
+		t.Errorf("claimed %s after DeferProcessing", claimed.ID)
+	}
+	if err := s.UpdateItemForReprocess(ctx, "old", "", 3); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, _ := s.ClaimNextCaptured(ctx); claimed == nil || claimed.ID != "old" {
+		t.Errorf("ClaimNextCaptured = %v, want old once no longer deferred", claimed)
+	}
+}
+
 func TestFindItemByURL(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
@@ -377,18 +429,22 @@ func TestBatchUpdateStatus(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
 
-	for _, id := range []string{"a", "b", "c"} {
+	for _, id := range []string{"a", "b", "c", "d"} {
 		item := makeItem(id, "https://example.com/"+id)
 		item.Status = model.StatusReady
+		if id == "d" {
+			item.Status = model.StatusArchived
+		}
 		s.CreateItem(ctx, item)
 	}
 
-	n, err := s.BatchUpdateStatus(ctx, []string{"a", "b"}, model.StatusArchived)
+	// Only items that change are reported: d is already archived.
+	ids, err := s.BatchUpdateStatus(ctx, []string{"a", "b", "d", "missing"}, model.StatusArchived)
 	if err != nil {
 		t.Fatalf("BatchUpdateStatus: %v", err)
 	}
-	if n != 2 {
-		t.Errorf("updated = %d, want 2", n)
+	if slices.Sort(ids); !slices.Equal(ids, []string{"a", "b"}) {
+		t.Errorf("updated = %v, want [a b]", ids)
 	}
 
 	got, _ := s.GetItem(ctx, "a")
@@ -410,12 +466,12 @@ func TestBatchDeleteItems(t *testing.T) {
 		s.CreateItem(ctx, item)
 	}
 
-	n, err := s.BatchDeleteItems(ctx, []string{"a", "b"})
+	ids, err := s.BatchDeleteItems(ctx, []string{"a", "b", "missing"})
 	if err != nil {
 		t.Fatalf("BatchDeleteItems: %v", err)
 	}
-	if n != 2 {
-		t.Errorf("deleted = %d, want 2", n)
+	if slices.Sort(ids); !slices.Equal(ids, []string{"a", "b"}) {
+		t.Errorf("deleted = %v, want [a b]", ids)
 	}
 
 	all, _ := s.ListItems(ctx, model.ItemFilter{})
@@ -438,7 +494,7 @@ func TestCountByStatus(t *testing.T) {
 	}
 
 	// Add items in various statuses
-	for i, status := range []string{model.StatusCaptured, model.StatusReady, model.StatusFailed, model.StatusArchived, model.StatusArchived} {
+	for i, status := range []string{model.StatusCaptured, model.StatusReady, model.StatusFailed, model.StatusArchived, model.StatusArchived, model.StatusDone, model.StatusSnoozed} {
 		item := makeItem(
 			"item-"+string(rune('a'+i)),
 			"https://example.com/"+string(rune('a'+i)),
@@ -459,6 +515,92 @@ func TestCountByStatus(t *testing.T) {
 	if counts.Archive != 2 {
 		t.Errorf("archive = %d, want 2", counts.Archive)
 	}
+	if counts.Done != 1 {
+		t.Errorf("done = %d, want 1", counts.Done)
+	}
+	if counts.Snoozed != 1 {
+		t.Errorf("snoozed = %d, want 1", counts.Snoozed)
+	}
+}
+
+func TestSnoozeAndWake(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	ready := makeItem("item-1", "https://example.com/1")
+	ready.Status = model.StatusReady
+	captured := makeItem("item-2", "https://example.com/2")
+	s.CreateItem(ctx, ready)
+	s.CreateItem(ctx, captured)
+
+	until := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	ids, err := s.SnoozeItems(ctx, []string{"item-1", "item-2"}, until)
+	if err != nil {
+		t.Fatalf("SnoozeItems: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "item-1" {
+		t.Errorf("snoozed = %v, want [item-1] (only READY items)", ids)
+	}
+
+	got, _ := s.GetItem(ctx, "item-1")
+	if got.Status != model.StatusSnoozed || got.SnoozeUntil == nil || *got.SnoozeUntil != until {
+		t.Fatalf("item-1 = %s until %v, want SNOOZED until %s", got.Status, got.SnoozeUntil, until)
+	}
+
+	// Snoozed items are hidden from the default list but reachable by status.
+	all, _ := s.ListItems(ctx, model.ItemFilter{})
+	if len(all) != 1 || all[0].ID != "item-2" {
+		t.Errorf("default list = %v, want only item-2", all)
+	}
+	snoozed, _ := s.ListItems(ctx, model.ItemFilter{Status: []string{model.StatusSnoozed}})
+	if len(snoozed) != 1 {
+		t.Errorf("snoozed list = %d, want 1", len(snoozed))
+	}
+
+	// Not due yet.
+	if ids, _ := s.WakeSnoozedItems(ctx, time.Now()); len(ids) != 0 {
+		t.Errorf("woke = %v before due, want none", ids)
+	}
+
+	ids, err = s.WakeSnoozedItems(ctx, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("WakeSnoozedItems: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "item-1" {
+		t.Errorf("woke = %v, want [item-1]", ids)
+	}
+	got, _ = s.GetItem(ctx, "item-1")
+	if got.Status != model.StatusReady || got.SnoozeUntil != nil {
+		t.Errorf("after wake = %s until %v, want READY with no snooze_until", got.Status, got.SnoozeUntil)
+	}
+}
+
+func TestUpdateItemStatus_CompletedAt(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	item := makeItem("item-1", "https://example.com/1")
+	item.Status = model.StatusReady
+	s.CreateItem(ctx, item)
+
+	s.UpdateItemStatus(ctx, "item-1", model.StatusDone, nil)
+	got, _ := s.GetItem(ctx, "item-1")
+	if got.CompletedAt == nil {
+		t.Fatal("CompletedAt should be set when DONE")
+	}
+
+	// Archiving a DONE item keeps the completion time.
+	s.UpdateItemStatus(ctx, "item-1", model.StatusArchived, nil)
+	got, _ = s.GetItem(ctx, "item-1")
+	if got.CompletedAt == nil {
+		t.Error("CompletedAt should be kept when archiving")
+	}
+
+	// Reopening clears it.
+	s.UpdateItemStatus(ctx, "item-1", model.StatusReady, nil)
+	got, _ = s.GetItem(ctx, "item-1")
+	if got.CompletedAt != nil {
+		t.Errorf("CompletedAt = %v, want nil after reopening", *got.CompletedAt)
+	}
 }
 
 func TestMigration(t *testing.T) {
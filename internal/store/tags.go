@@ -0,0 +1,210 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// TagCount is the number of items carrying a tag.
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ListItemTags returns the tags of an item in alphabetical order.
+func (s *Store) ListItemTags(ctx context.Context, itemID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT tag FROM item_tags WHERE item_id = ? ORDER BY tag ASC`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// SetItemTags replaces the full tag set of an item.
+// Tag names are expected to be normalized by the caller (see model.NormalizeTags).
+func (s *Store) SetItemTags(ctx context.Context, itemID string, tags []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM item_tags WHERE item_id = ?`, itemID); err != nil {
+		return fmt.Errorf("clear item tags: %w", err)
+	}
+	if err := addTagsTx(ctx, tx, []string{itemID}, tags); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddItemTags links additional tags to an item, keeping the existing ones.
+func (s *Store) AddItemTags(ctx context.Context, itemID string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := addTagsTx(ctx, tx, []string{itemID}, tags); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// BatchUpdateTags adds and removes tags on multiple items at once.
+// It returns the IDs of the existing items that were targeted.
+func (s *Store) BatchUpdateTags(ctx context.Context, ids, add, remove []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := existingItemIDs(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if err := addTagsTx(ctx, tx, existing, add); err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 && len(remove) > 0 {
+		args := make([]interface{}, 0, len(existing)+len(remove))
+		for _, id := range existing {
+			args = append(args, id)
+		}
+		for _, t := range remove {
+			args = append(args, t)
+		}
+		query := fmt.Sprintf(`DELETE FROM item_tags WHERE item_id IN (%s) AND tag IN (%s)`, placeholders(len(existing)), placeholders(len(remove)))
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return nil, fmt.Errorf("remove tags: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// CountTags returns every tag in use with its item count, most used first.
+func (s *Store) CountTags(ctx context.Context) ([]TagCount, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT tag, COUNT(*) FROM item_tags GROUP BY tag ORDER BY COUNT(*) DESC, tag ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []TagCount{}
+	for rows.Next() {
+		var c TagCount
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// ListTagVocabulary returns up to limit known tag names, most used first.
+func (s *Store) ListTagVocabulary(ctx context.Context, limit int) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.name FROM tags t
+		LEFT JOIN item_tags it ON it.tag = t.name
+		GROUP BY t.name
+		ORDER BY COUNT(it.item_id) DESC, t.name ASC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			return nil, err
+		}
+		names = append(names, n)
+	}
+	return names, rows.Err()
+}
+
+// ReplaceAutoTags replaces the AI-applied tags of an item with the given set.
+// Tags applied by the user are never removed or downgraded.
+func (s *Store) ReplaceAutoTags(ctx context.Context, itemID string, tags []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM item_tags WHERE item_id = ? AND source = ?`, itemID, model.CreatedBySystem); err != nil {
+		return fmt.Errorf("clear auto tags: %w", err)
+	}
+	if err := linkTagsTx(ctx, tx, []string{itemID}, tags, model.CreatedBySystem); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addTagsTx links user-applied tags to every given item.
+func addTagsTx(ctx context.Context, tx *sql.Tx, itemIDs, tags []string) error {
+	return linkTagsTx(ctx, tx, itemIDs, tags, model.CreatedByUser)
+}
+
+// linkTagsTx registers tags in the vocabulary and links them to every given item.
+// A user link always wins: it upgrades an existing AI link, while an AI link
+// never overwrites an existing one.
+func linkTagsTx(ctx context.Context, tx *sql.Tx, itemIDs, tags []string, source string) error {
+	if len(itemIDs) == 0 || len(tags) == 0 {
+		return nil
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, t := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO tags (name, created_at) VALUES (?, ?)`, t, now); err != nil {
+			return fmt.Errorf("insert tag %q: %w", t, err)
+		}
+		for _, id := range itemIDs {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO item_tags (item_id, tag, created_at, source) VALUES (?, ?, ?, ?)
+				ON CONFLICT(item_id, tag) DO UPDATE SET source = excluded.source
+				WHERE excluded.source = ?`,
+				id, t, now, source, model.CreatedByUser,
+			); err != nil {
+				return fmt.Errorf("link tag %q: %w", t, err)
+			}
+		}
+	}
+	return nil
+}
+
+// existingItemIDs filters ids down to those that exist in the items table.
+func existingItemIDs(ctx context.Context, tx *sql.Tx, ids []string) ([]string, error) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return queryIDs(ctx, tx, fmt.Sprintf(`SELECT id FROM items WHERE id IN (%s)`, placeholders(len(ids))), args...)
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestSetAndAddItemTags(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://example.com/1"))
+
+	if err := s.SetItemTags(ctx, "item-1", []string{"go", "db"}); err != nil {
+		t.Fatalf("SetItemTags: %v", err)
+	}
+	if err := s.AddItemTags(ctx, "item-1", []string{"go", "perf"}); err != nil {
+		t.Fatalf("AddItemTags: %v", err)
+	}
+
+	got, err := s.GetItem(ctx, "item-1")
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	if want := []string{"db", "go", "perf"}; !reflect.DeepEqual(got.Tags, want) {
+		t.Errorf("Tags = %v, want %v", got.Tags, want)
+	}
+
+	// Set replaces the whole set.
+	s.SetItemTags(ctx, "item-1", []string{"perf"})
+	tags, _ := s.ListItemTags(ctx, "item-1")
+	if want := []string{"perf"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Tags after set = %v, want %v", tags, want)
+	}
+}
+
+func TestListItems_TagFilter(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	for _, id := range []string{"a", "b", "c"} {
+		s.CreateItem(ctx, makeItem(id, "https://example.com/"+id))
+	}
+	s.SetItemTags(ctx, "a", []string{"go"})
+	s.SetItemTags(ctx, "b", []string{"rust"})
+
+	got, err := s.ListItems(ctx, model.ItemFilter{Tags: []string{"go", "rust"}})
+	if err != nil {
+		t.Fatalf("ListItems: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("ListItems tag filter = %d, want 2", len(got))
+	}
+}
+
+func TestBatchUpdateTagsAndCount(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		s.CreateItem(ctx, makeItem(id, "https://example.com/"+id))
+	}
+	s.SetItemTags(ctx, "a", []string{"old"})
+
+	ids, err := s.BatchUpdateTags(ctx, []string{"a", "b", "missing"}, []string{"go"}, []string{"old"})
+	if err != nil {
+		t.Fatalf("BatchUpdateTags: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("updated = %v, want [a b]", ids)
+	}
+
+	counts, err := s.CountTags(ctx)
+	if err != nil {
+		t.Fatalf("CountTags: %v", err)
+	}
+	if want := []TagCount{{Name: "go", Count: 2}}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountTags = %v, want %v", counts, want)
+	}
+}
+
+func TestDeleteItem_RemovesTagLinks(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://example.com/1"))
+	s.SetItemTags(ctx, "item-1", []string{"go"})
+
+	if err := s.DeleteItem(ctx, "item-1"); err != nil {
+		t.Fatalf("DeleteItem: %v", err)
+	}
+	counts, _ := s.CountTags(ctx)
+	if len(counts) != 0 {
+		t.Errorf("CountTags after delete = %v, want none", counts)
+	}
+}
+
+func TestReplaceAutoTags_KeepsUserTags(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://example.com/1"))
+	s.SetItemTags(ctx, "item-1", []string{"mine"})
+
+	if err := s.ReplaceAutoTags(ctx, "item-1", []string{"ai-1", "mine"}); err != nil {
+		t.Fatalf("ReplaceAutoTags: %v", err)
+	}
+	// Reprocessing replaces the AI tags but must keep the user's tag.
+	if err := s.ReplaceAutoTags(ctx, "item-1", []string{"ai-2"}); err != nil {
+		t.Fatalf("ReplaceAutoTags (reprocess): %v", err)
+	}
+
+	tags, _ := s.ListItemTags(ctx, "item-1")
+	if want := []string{"ai-2", "mine"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Tags = %v, want %v", tags, want)
+	}
+
+	vocab, err := s.ListTagVocabulary(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListTagVocabulary: %v", err)
+	}
+	if len(vocab) != 3 {
+		t.Errorf("vocabulary = %v, want 3 names", vocab)
+	}
+}
@@ -0,0 +1,234 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+const todoColumns = `id, item_id, title, eta, type, position, done, completed_at, due_date, edited, created_by, created_at, updated_at`
+
+// GetTodo returns a todo by ID. It returns sql.ErrNoRows if not found.
+func (s *Store) GetTodo(ctx context.Context, id string) (*model.Todo, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+todoColumns+` FROM todos WHERE id = ?`, id)
+	return scanTodo(row)
+}
+
+// ListTodos returns todos matching the filter, ordered by item then position.
+func (s *Store) ListTodos(ctx context.Context, f model.TodoFilter) ([]model.Todo, error) {
+	query := `SELECT ` + todoColumns + ` FROM todos`
+	var conditions []string
+	var args []interface{}
+
+	if f.ItemID != "" {
+		conditions = append(conditions, "item_id = ?")
+		args = append(args, f.ItemID)
+	}
+	if len(f.Type) > 0 {
+		conditions = append(conditions, "type IN ("+placeholders(len(f.Type))+")")
+		for _, t := range f.Type {
+			args = append(args, t)
+		}
+	}
+	if f.Done != nil {
+		conditions = append(conditions, "done = ?")
+		args = append(args, *f.Done)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY item_id, position ASC, created_at ASC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := []model.Todo{}
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, *t)
+	}
+	return todos, rows.Err()
+}
+
+// UpdateTodo persists the user-editable fields of a todo.
+// It returns sql.ErrNoRows if the todo does not exist.
+func (s *Store) UpdateTodo(ctx context.Context, t model.Todo) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE todos SET title = ?, position = ?, done = ?, completed_at = ?, due_date = ?, edited = ?, updated_at = ?
+		WHERE id = ?`,
+		t.Title, t.Position, t.Done, t.CompletedAt, t.DueDate, t.Edited, t.UpdatedAt, t.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// SyncGeneratedTodos reconciles freshly generated todos with the stored ones.
+// Todos the user has edited (or created) are kept untouched; untouched system
+// todos are replaced. Generated todos whose title matches a kept todo are
+// skipped, and the rest are appended after the kept ones.
+func (s *Store) SyncGeneratedTodos(ctx context.Context, itemID string, generated []model.Todo) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := syncGeneratedTodosTx(ctx, tx, itemID, generated); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func syncGeneratedTodosTx(ctx context.Context, tx *sql.Tx, itemID string, generated []model.Todo) error {
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM todos WHERE item_id = ? AND edited = 0 AND created_by = ?`, itemID, model.CreatedBySystem,
+	); err != nil {
+		return fmt.Errorf("delete generated todos: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT title, position FROM todos WHERE item_id = ?`, itemID)
+	if err != nil {
+		return fmt.Errorf("read kept todos: %w", err)
+	}
+	kept := map[string]bool{}
+	next := 0
+	for rows.Next() {
+		var title string
+		var pos int
+		if err := rows.Scan(&title, &pos); err != nil {
+			rows.Close()
+			return err
+		}
+		kept[normalizeTodoTitle(title)] = true
+		next = max(next, pos+1)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, t := range generated {
+		if kept[normalizeTodoTitle(t.Title)] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO todos (`+todoColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.ID, itemID, t.Title, t.ETA, t.Type, next, t.Done, t.CompletedAt, t.DueDate, t.Edited, t.CreatedBy, t.CreatedAt, t.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("insert todo: %w", err)
+		}
+		next++
+	}
+	return nil
+}
+
+// syncArtifactTodosTx reconciles the todos table with a todos artifact that
+// became current other than by generation (an edit, a revert or an accepted
+// proposal), the same way as freshly generated todos: todos the user has
+// edited directly are kept, the rest follow the artifact. Todos marked done
+// in the artifact are done in the table too, but are not marked edited, so
+// the next artifact change replaces them like any other.
+func syncArtifactTodosTx(ctx context.Context, tx *sql.Tx, a model.Artifact) error {
+	var parsed struct {
+		Todos []struct {
+			Title string `json:"title"`
+			ETA   string `json:"eta"`
+			Type  string `json:"type"`
+			Done  bool   `json:"done"`
+		} `json:"todos"`
+	}
+	if err := json.Unmarshal([]byte(a.Payload), &parsed); err != nil {
+		return fmt.Errorf("parse todos artifact: %w", err)
+	}
+	todos := make([]model.Todo, len(parsed.Todos))
+	for i, t := range parsed.Todos {
+		todos[i] = model.NewTodo(uuid.New().String(), a.ItemID, t.Title, t.ETA, t.Type, i)
+		if t.Done {
+			todos[i].Done = true
+			todos[i].CompletedAt = &todos[i].UpdatedAt
+		}
+	}
+	if err := syncGeneratedTodosTx(ctx, tx, a.ItemID, todos); err != nil {
+		return fmt.Errorf("sync todos: %w", err)
+	}
+	return nil
+}
+
+// CountItemTodos returns the total and completed number of todos for an item.
+func (s *Store) CountItemTodos(ctx context.Context, itemID string) (total, done int, err error) {
+	err = s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COALESCE(SUM(done), 0) FROM todos WHERE item_id = ?`, itemID,
+	).Scan(&total, &done)
+	return total, done, err
+}
+
+// maxTodoSyncs caps how many todo sync log entries are listed.
+const maxTodoSyncs = 200
+
+const todoSyncColumns = `id, todo_id, item_id, file, file_done, api_done, file_modified_at, todo_updated_at, winner, created_at`
+
+// RecordTodoSync appends an entry to the todo sync log.
+func (s *Store) RecordTodoSync(ctx context.Context, r model.TodoSync) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO todo_syncs (`+todoSyncColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.TodoID, r.ItemID, r.File, r.FileDone, r.APIDone, r.FileModifiedAt, r.TodoUpdatedAt, r.Winner, r.CreatedAt,
+	)
+	return err
+}
+
+// ListTodoSyncs returns the most recent todo sync log entries, newest first,
+// optionally restricted to one item.
+func (s *Store) ListTodoSyncs(ctx context.Context, itemID string) ([]model.TodoSync, error) {
+	query := `SELECT ` + todoSyncColumns + ` FROM todo_syncs`
+	var args []any
+	if itemID != "" {
+		query += ` WHERE item_id = ?`
+		args = append(args, itemID)
+	}
+	query += ` ORDER BY created_at DESC, rowid DESC LIMIT ?`
+	args = append(args, maxTodoSyncs)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	syncs := []model.TodoSync{}
+	for rows.Next() {
+		var r model.TodoSync
+		if err := rows.Scan(&r.ID, &r.TodoID, &r.ItemID, &r.File, &r.FileDone, &r.APIDone,
+			&r.FileModifiedAt, &r.TodoUpdatedAt, &r.Winner, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		syncs = append(syncs, r)
+	}
+	return syncs, rows.Err()
+}
+
+func normalizeTodoTitle(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func scanTodo(row scanner) (*model.Todo, error) {
+	var t model.Todo
+	err := row.Scan(&t.ID, &t.ItemID, &t.Title, &t.ETA, &t.Type, &t.Position, &t.Done, &t.CompletedAt, &t.DueDate, &t.Edited, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
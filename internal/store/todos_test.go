@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestSyncGeneratedTodos_KeepsEdited(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://example.com/1"))
+
+	first := []model.Todo{
+		model.NewTodo("t-1", "item-1", "Read intro", "10m", model.TodoTypeRead, 0),
+		model.NewTodo("t-2", "item-1", "Write notes", "20m", model.TodoTypeWrite, 1),
+	}
+	if err := s.SyncGeneratedTodos(ctx, "item-1", first); err != nil {
+		t.Fatalf("SyncGeneratedTodos: %v", err)
+	}
+
+	// The user ticks off the WRITE todo.
+	done := true
+	todo, _ := s.GetTodo(ctx, "t-2")
+	todo.Apply(model.TodoPatch{Done: &done})
+	if err := s.UpdateTodo(ctx, *todo); err != nil {
+		t.Fatalf("UpdateTodo: %v", err)
+	}
+
+	// Reprocessing generates a new list that repeats the edited title.
+	second := []model.Todo{
+		model.NewTodo("t-3", "item-1", "Write  notes", "20m", model.TodoTypeWrite, 0),
+		model.NewTodo("t-4", "item-1", "Share with team", "30m", model.TodoTypeShare, 1),
+	}
+	if err := s.SyncGeneratedTodos(ctx, "item-1", second); err != nil {
+		t.Fatalf("SyncGeneratedTodos (reprocess): %v", err)
+	}
+
+	todos, err := s.ListTodos(ctx, model.TodoFilter{ItemID: "item-1"})
+	if err != nil {
+		t.Fatalf("ListTodos: %v", err)
+	}
+	if len(todos) != 2 {
+		t.Fatalf("todos = %+v, want 2", todos)
+	}
+	if todos[0].ID != "t-2" || !todos[0].Done {
+		t.Errorf("todos[0] = %+v, want kept done t-2", todos[0])
+	}
+	if todos[1].ID != "t-4" || todos[1].Position != 2 {
+		t.Errorf("todos[1] = %+v, want t-4 appended at position 2", todos[1])
+	}
+
+	open := false
+	openWrite, _ := s.ListTodos(ctx, model.TodoFilter{Type: []string{model.TodoTypeWrite}, Done: &open})
+	if len(openWrite) != 0 {
+		t.Errorf("open WRITE todos = %d, want 0", len(openWrite))
+	}
+}
+
+func TestMigrateV8_BackfillsTodos(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "backfill.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	s, err := New(db)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	s.CreateItem(ctx, makeItem("item-1", "https://example.com/1"))
+	a := model.NewArtifact("a-1", "item-1", model.ArtifactTodos, `{"todos":[{"title":"Read","eta":"10m","type":"READ","done":true},{"title":"Write","eta":"20m","type":"WRITE"}]}`)
+	s.UpsertArtifact(ctx, a)
+
+	// Re-run the v8 migration against the existing artifact.
+	if _, err := db.Exec(`DROP TABLE todos`); err != nil {
+		t.Fatalf("drop todos: %v", err)
+	}
+	if err := s.migrateV8(); err != nil {
+		t.Fatalf("migrateV8: %v", err)
+	}
+
+	todos, _ := s.ListTodos(ctx, model.TodoFilter{ItemID: "item-1"})
+	if len(todos) != 2 {
+		t.Fatalf("backfilled todos = %d, want 2", len(todos))
+	}
+	if !todos[0].Done || todos[0].CompletedAt == nil || !todos[0].Edited {
+		t.Errorf("todos[0] = %+v, want done and protected", todos[0])
+	}
+}
+
+func TestTodoSyncs_RecordAndList(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	for i, itemID := range []string{"item-1", "item-2", "item-1"} {
+		td := model.NewTodo(fmt.Sprintf("t-%d", i), itemID, "Read", "10m", model.TodoTypeRead, 0)
+		rec := model.NewTodoSync(fmt.Sprintf("s-%d", i), td, "note.md", true, now.Add(time.Hour))
+		if err := s.RecordTodoSync(ctx, rec); err != nil {
+			t.Fatalf("RecordTodoSync: %v", err)
+		}
+	}
+
+	all, err := s.ListTodoSyncs(ctx, "")
+	if err != nil {
+		t.Fatalf("ListTodoSyncs: %v", err)
+	}
+	if len(all) != 3 || all[0].ID != "s-2" {
+		t.Fatalf("all = %+v, want 3 newest first", all)
+	}
+	if !all[0].FileDone || all[0].APIDone || all[0].Winner != model.SyncSideVault {
+		t.Errorf("record = %+v, want file done, api open, vault winner", all[0])
+	}
+
+	one, err := s.ListTodoSyncs(ctx, "item-2")
+	if err != nil {
+		t.Fatalf("ListTodoSyncs(item-2): %v", err)
+	}
+	if len(one) != 1 || one[0].TodoID != "t-1" {
+		t.Errorf("item-2 syncs = %+v, want t-1", one)
+	}
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// ViewCount is the live item count of a saved view.
+type ViewCount struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// CreateView inserts a new saved view.
+func (s *Store) CreateView(ctx context.Context, v model.View) error {
+	filter, err := json.Marshal(v.Filter)
+	if err != nil {
+		return fmt.Errorf("marshal view filter: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO views (id, name, filter, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		v.ID, v.Name, string(filter), v.CreatedAt, v.UpdatedAt,
+	)
+	return err
+}
+
+// GetView returns a saved view by ID. It returns sql.ErrNoRows if not found.
+func (s *Store) GetView(ctx context.Context, id string) (*model.View, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, name, filter, created_at, updated_at FROM views WHERE id = ?`, id)
+	return scanView(row)
+}
+
+// ListViews returns all saved views ordered by name.
+func (s *Store) ListViews(ctx context.Context) ([]model.View, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, filter, created_at, updated_at FROM views ORDER BY name ASC, created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []model.View
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *v)
+	}
+	return views, rows.Err()
+}
+
+// UpdateView replaces the name and filter of a saved view.
+// It returns sql.ErrNoRows if the view does not exist.
+func (s *Store) UpdateView(ctx context.Context, v model.View) error {
+	filter, err := json.Marshal(v.Filter)
+	if err != nil {
+		return fmt.Errorf("marshal view filter: %w", err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE views SET name = ?, filter = ?, updated_at = ? WHERE id = ?`,
+		v.Name, string(filter), now, v.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// DeleteView removes a saved view. It returns sql.ErrNoRows if the view does not exist.
+func (s *Store) DeleteView(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM views WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// CountViews evaluates every saved view and returns its current item count.
+func (s *Store) CountViews(ctx context.Context) ([]ViewCount, error) {
+	views, err := s.ListViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]ViewCount, 0, len(views))
+	for _, v := range views {
+		n, err := s.CountItems(ctx, v.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("count view %s: %w", v.ID, err)
+		}
+		counts = append(counts, ViewCount{ID: v.ID, Name: v.Name, Count: n})
+	}
+	return counts, nil
+}
+
+func scanView(row scanner) (*model.View, error) {
+	var v model.View
+	var filter string
+	if err := row.Scan(&v.ID, &v.Name, &filter, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(filter), &v.Filter); err != nil {
+		return nil, fmt.Errorf("unmarshal view filter: %w", err)
+	}
+	return &v, nil
+}
+
+// requireAffected returns sql.ErrNoRows when an UPDATE/DELETE matched no rows.
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
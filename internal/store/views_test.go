@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestViewCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	v := model.NewView("v-1", "Failed last 24h", model.ItemFilter{Status: []string{model.StatusFailed}, Within: "24h"})
+	if err := s.CreateView(ctx, v); err != nil {
+		t.Fatalf("CreateView: %v", err)
+	}
+
+	got, err := s.GetView(ctx, "v-1")
+	if err != nil {
+		t.Fatalf("GetView: %v", err)
+	}
+	if got.Name != v.Name || got.Filter.Within != "24h" || len(got.Filter.Status) != 1 {
+		t.Errorf("GetView = %+v, want %+v", got, v)
+	}
+
+	got.Name = "Renamed"
+	if err := s.UpdateView(ctx, *got); err != nil {
+		t.Fatalf("UpdateView: %v", err)
+	}
+	views, err := s.ListViews(ctx)
+	if err != nil {
+		t.Fatalf("ListViews: %v", err)
+	}
+	if len(views) != 1 || views[0].Name != "Renamed" {
+		t.Errorf("ListViews = %+v, want one view named Renamed", views)
+	}
+
+	if err := s.DeleteView(ctx, "v-1"); err != nil {
+		t.Fatalf("DeleteView: %v", err)
+	}
+	if _, err := s.GetView(ctx, "v-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetView after delete error = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.DeleteView(ctx, "v-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteView missing error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestCountViews(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	old := makeItem("old", "https://example.com/old")
+	old.Status = model.StatusFailed
+	old.UpdatedAt = time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339)
+	fresh := makeItem("fresh", "https://example.com/fresh")
+	fresh.Status = model.StatusFailed
+	ready := makeItem("ready", "https://example.com/ready")
+	ready.Status = model.StatusReady
+	for _, item := range []model.Item{old, fresh, ready} {
+		if err := s.CreateItem(ctx, item); err != nil {
+			t.Fatalf("CreateItem: %v", err)
+		}
+	}
+
+	s.CreateView(ctx, model.NewView("v-1", "Failed last 24h", model.ItemFilter{Status: []string{model.StatusFailed}, Within: "24h"}))
+	s.CreateView(ctx, model.NewView("v-2", "All failed", model.ItemFilter{Status: []string{model.StatusFailed}}))
+
+	counts, err := s.CountViews(ctx)
+	if err != nil {
+		t.Fatalf("CountViews: %v", err)
+	}
+	want := map[string]int{"v-1": 1, "v-2": 2}
+	if len(counts) != len(want) {
+		t.Fatalf("CountViews len = %d, want %d", len(counts), len(want))
+	}
+	for _, c := range counts {
+		if c.Count != want[c.ID] {
+			t.Errorf("view %s count = %d, want %d", c.ID, c.Count, want[c.ID])
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// maxWebhookDeliveries caps how many delivery log entries are listed.
+const maxWebhookDeliveries = 200
+
+const webhookColumns = `id, url, events, secret, enabled, created_at, updated_at`
+
+const deliveryColumns = `id, webhook_id, event_type, item_id, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, created_at, updated_at, delivered_at`
+
+// CreateWebhook inserts a new webhook subscription.
+func (s *Store) CreateWebhook(ctx context.Context, w model.Webhook) error {
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return fmt.Errorf("marshal webhook events: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO webhooks (`+webhookColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		w.ID, w.URL, string(events), w.Secret, w.Enabled, w.CreatedAt, w.UpdatedAt,
+	)
+	return err
+}
+
+// GetWebhook returns a webhook by ID. It returns sql.ErrNoRows if not found.
+func (s *Store) GetWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id)
+	return scanWebhook(row)
+}
+
+// ListWebhooks returns all webhooks, oldest first.
+func (s *Store) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at ASC, id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []model.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, *w)
+	}
+	return hooks, rows.Err()
+}
+
+// UpdateWebhook replaces a webhook's URL, events and enabled flag. The secret
+// is only replaced when w.Secret is non-empty.
+// It returns sql.ErrNoRows if the webhook does not exist.
+func (s *Store) UpdateWebhook(ctx context.Context, w model.Webhook) error {
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return fmt.Errorf("marshal webhook events: %w", err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE webhooks SET url = ?, events = ?, secret = COALESCE(NULLIF(?, ''), secret), enabled = ?, updated_at = ? WHERE id = ?`,
+		w.URL, string(events), w.Secret, w.Enabled, now, w.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// DeleteWebhook removes a webhook together with its deliveries.
+// It returns sql.ErrNoRows if the webhook does not exist.
+func (s *Store) DeleteWebhook(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return fmt.Errorf("delete deliveries: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// EnqueueDelivery adds a delivery to the outbox.
+func (s *Store) EnqueueDelivery(ctx context.Context, d model.WebhookDelivery) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (`+deliveryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.WebhookID, d.EventType, d.ItemID, string(d.Payload), d.Status, d.Attempts, d.NextAttemptAt,
+		d.LastStatusCode, d.LastError, d.CreatedAt, d.UpdatedAt, d.DeliveredAt,
+	)
+	return err
+}
+
+// ListDueDeliveries returns up to limit pending deliveries of enabled
+// webhooks whose next attempt is due at now, oldest first.
+func (s *Store) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		  AND webhook_id IN (SELECT id FROM webhooks WHERE enabled = 1)
+		ORDER BY next_attempt_at ASC, created_at ASC
+		LIMIT ?`,
+		model.DeliveryPending, now.UTC().Format(time.RFC3339), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+// UpdateDelivery records the outcome of a delivery attempt.
+// It returns sql.ErrNoRows if the delivery does not exist.
+func (s *Store) UpdateDelivery(ctx context.Context, d model.WebhookDelivery) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?,
+		    updated_at = ?, delivered_at = ?
+		WHERE id = ?`,
+		d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.UpdatedAt, d.DeliveredAt, d.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// ListWebhookDeliveries returns the most recent deliveries of a webhook,
+// newest first, optionally restricted to one status.
+func (s *Store) ListWebhookDeliveries(ctx context.Context, webhookID, status string) ([]model.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = ?`
+	args := []interface{}{webhookID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC, id ASC LIMIT ?`
+	args = append(args, maxWebhookDeliveries)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+// RedeliverWebhookDelivery puts a delivery back into the outbox, due
+// immediately and with a fresh set of attempts.
+// It returns sql.ErrNoRows if the delivery does not exist.
+func (s *Store) RedeliverWebhookDelivery(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ?, updated_at = ? WHERE id = ?`,
+		model.DeliveryPending, now, now, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(res); err != nil {
+		return nil, err
+	}
+	row := s.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id)
+	return scanDelivery(row)
+}
+
+func scanWebhook(row scanner) (*model.Webhook, error) {
+	var w model.Webhook
+	var events string
+	if err := row.Scan(&w.ID, &w.URL, &events, &w.Secret, &w.Enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &w.Events); err != nil {
+		return nil, fmt.Errorf("unmarshal webhook events: %w", err)
+	}
+	return &w, nil
+}
+
+func scanDelivery(row scanner) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	var payload string
+	if err := row.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.ItemID, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt, &d.DeliveredAt); err != nil {
+		return nil, err
+	}
+	d.Payload = json.RawMessage(payload)
+	return &d, nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+func TestWebhooksCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	h := model.NewWebhook("hook-1", "https://example.com/hook", []string{model.EventItemReady}, "secret-1")
+	if err := s.CreateWebhook(ctx, h); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	// An empty secret on update keeps the existing one.
+	h.URL = "https://example.com/v2"
+	h.Events = []string{model.EventItemReady, model.EventItemFailed}
+	h.Secret = ""
+	h.Enabled = false
+	if err := s.UpdateWebhook(ctx, h); err != nil {
+		t.Fatalf("UpdateWebhook: %v", err)
+	}
+	got, err := s.GetWebhook(ctx, "hook-1")
+	if err != nil {
+		t.Fatalf("GetWebhook: %v", err)
+	}
+	if got.URL != "https://example.com/v2" || len(got.Events) != 2 || got.Enabled || got.Secret != "secret-1" {
+		t.Errorf("after update = %+v", got)
+	}
+
+	hooks, err := s.ListWebhooks(ctx)
+	if err != nil || len(hooks) != 1 {
+		t.Fatalf("ListWebhooks = %d, %v; want 1", len(hooks), err)
+	}
+
+	if err := s.UpdateWebhook(ctx, model.NewWebhook("missing", "https://x", nil, "")); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateWebhook(missing) = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.DeleteWebhook(ctx, "hook-1"); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if err := s.DeleteWebhook(ctx, "hook-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteWebhook twice = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestWebhookDeliveryOutbox(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	active := model.NewWebhook("active", "https://example.com/a", []string{model.WebhookAllEvents}, "s")
+	paused := model.NewWebhook("paused", "https://example.com/p", []string{model.WebhookAllEvents}, "s")
+	paused.Enabled = false
+	for _, h := range []model.Webhook{active, paused} {
+		if err := s.CreateWebhook(ctx, h); err != nil {
+			t.Fatalf("CreateWebhook: %v", err)
+		}
+	}
+
+	e := model.StatusEvent("item-1", model.StatusReady)
+	payload := json.RawMessage(`{"event":{"type":"item.ready"}}`)
+	for _, d := range []model.WebhookDelivery{
+		model.NewWebhookDelivery("d1", "active", e, payload),
+		model.NewWebhookDelivery("d2", "paused", e, payload),
+	} {
+		if err := s.EnqueueDelivery(ctx, d); err != nil {
+			t.Fatalf("EnqueueDelivery: %v", err)
+		}
+	}
+
+	now := time.Now()
+	due, err := s.ListDueDeliveries(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("ListDueDeliveries: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != "d1" {
+		t.Fatalf("due = %+v, want only d1 (paused webhooks are skipped)", due)
+	}
+	if string(due[0].Payload) != string(payload) {
+		t.Errorf("payload = %s, want %s", due[0].Payload, payload)
+	}
+
+	// A failed attempt is rescheduled and no longer due right now.
+	d := due[0]
+	d.RecordAttempt(500, "unexpected status 500", now)
+	if err := s.UpdateDelivery(ctx, d); err != nil {
+		t.Fatalf("UpdateDelivery: %v", err)
+	}
+	if due, _ := s.ListDueDeliveries(ctx, now, 10); len(due) != 0 {
+		t.Errorf("due after backoff = %d, want 0", len(due))
+	}
+	if due, _ := s.ListDueDeliveries(ctx, now.Add(time.Minute), 10); len(due) != 1 {
+		t.Errorf("due after backoff elapsed = %d, want 1", len(due))
+	}
+
+	log, err := s.ListWebhookDeliveries(ctx, "active", model.DeliveryPending)
+	if err != nil || len(log) != 1 || log[0].Attempts != 1 || *log[0].LastStatusCode != 500 {
+		t.Fatalf("ListWebhookDeliveries = %+v, %v", log, err)
+	}
+	if log, _ := s.ListWebhookDeliveries(ctx, "active", model.DeliveryDelivered); len(log) != 0 {
+		t.Errorf("delivered entries = %d, want 0", len(log))
+	}
+
+	redelivered, err := s.RedeliverWebhookDelivery(ctx, "d1")
+	if err != nil {
+		t.Fatalf("RedeliverWebhookDelivery: %v", err)
+	}
+	if redelivered.Attempts != 0 || redelivered.Status != model.DeliveryPending {
+		t.Errorf("redelivered = %+v, want pending with 0 attempts", redelivered)
+	}
+	if _, err := s.RedeliverWebhookDelivery(ctx, "missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("RedeliverWebhookDelivery(missing) = %v, want sql.ErrNoRows", err)
+	}
+
+	// Deleting a webhook removes its deliveries.
+	if err := s.DeleteWebhook(ctx, "active"); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if log, _ := s.ListWebhookDeliveries(ctx, "active", ""); len(log) != 0 {
+		t.Errorf("deliveries after delete = %d, want 0", len(log))
+	}
+}
@@ -0,0 +1,226 @@
+// Package webhook delivers item lifecycle events to subscribed HTTP
+// endpoints. Events are written to a persistent outbox first and delivered
+// from there with retries, so an unreachable endpoint or a restart loses
+// nothing once an event is in the outbox. Events reach the outbox from the
+// in-memory event bus: a graceful shutdown writes the events still queued,
+// but a crash can lose those not yet written.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yangwenmai/readdo/internal/events"
+	"github.com/yangwenmai/readdo/internal/model"
+)
+
+// Request headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Readdo-Event"
+	HeaderDelivery  = "X-Readdo-Delivery"
+	HeaderTimestamp = "X-Readdo-Timestamp"
+	HeaderSignature = "X-Readdo-Signature"
+)
+
+const (
+	// pollInterval is how often the outbox is checked for retries that
+	// have come due.
+	pollInterval = 5 * time.Second
+	// batchSize is the maximum number of deliveries fetched per outbox query.
+	batchSize = 20
+	// maxErrorBody is how much of a failed response body is kept in the log.
+	maxErrorBody = 512
+)
+
+// Store persists webhooks and the delivery outbox.
+type Store interface {
+	GetItem(ctx context.Context, id string) (*model.ItemWithArtifacts, error)
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (*model.Webhook, error)
+	EnqueueDelivery(ctx context.Context, d model.WebhookDelivery) error
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, d model.WebhookDelivery) error
+}
+
+// Dispatcher turns bus events into outbox deliveries and sends them.
+type Dispatcher struct {
+	store  Store
+	bus    *events.Bus
+	client *http.Client
+	now    func() time.Time
+	wake   chan struct{}
+}
+
+// New creates a Dispatcher. timeout bounds each delivery request.
+func New(store Store, bus *events.Bus, timeout time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		bus:    bus,
+		client: &http.Client{Timeout: timeout},
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Start consumes events and delivers the outbox. It blocks until ctx is
+// cancelled and the events still queued have been written to the outbox.
+func (d *Dispatcher) Start(ctx context.Context) {
+	consumed := make(chan struct{})
+	defer func() { <-consumed }()
+	go func() {
+		defer close(consumed)
+		d.bus.Consume(ctx, "webhooks", func(e model.Event) { d.enqueue(context.WithoutCancel(ctx), e) })
+	}()
+
+	slog.Info("webhook dispatcher started")
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		d.deliverDue(ctx)
+		select {
+		case <-ctx.Done():
+			slog.Info("webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// enqueue writes one delivery per matching, enabled webhook to the outbox.
+func (d *Dispatcher) enqueue(ctx context.Context, e model.Event) {
+	hooks, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		slog.Error("list webhooks failed", "error", err)
+		return
+	}
+	var targets []model.Webhook
+	for _, h := range hooks {
+		if h.Enabled && h.Matches(e.Type) {
+			targets = append(targets, h)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	item, err := d.store.GetItem(ctx, e.ItemID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("load webhook item failed", "item_id", e.ItemID, "error", err)
+		return
+	}
+	payload, err := json.Marshal(model.NewWebhookPayload(e, item))
+	if err != nil {
+		slog.Error("marshal webhook payload failed", "item_id", e.ItemID, "error", err)
+		return
+	}
+
+	for _, h := range targets {
+		delivery := model.NewWebhookDelivery(uuid.New().String(), h.ID, e, payload)
+		if err := d.store.EnqueueDelivery(ctx, delivery); err != nil {
+			slog.Error("enqueue webhook delivery failed", "webhook_id", h.ID, "error", err)
+		}
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverDue sends every outbox delivery that is due, one batch at a time.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := d.store.ListDueDeliveries(ctx, d.now(), batchSize)
+		if err != nil {
+			slog.Error("list due webhook deliveries failed", "error", err)
+			return
+		}
+		hooks := map[string]*model.Webhook{}
+		for _, delivery := range due {
+			h, ok := hooks[delivery.WebhookID]
+			if !ok {
+				if h, err = d.store.GetWebhook(ctx, delivery.WebhookID); err != nil {
+					slog.Error("load webhook failed", "webhook_id", delivery.WebhookID, "error", err)
+					return
+				}
+				hooks[delivery.WebhookID] = h
+			}
+			if err := d.attempt(ctx, h, delivery); err != nil {
+				slog.Error("record webhook delivery failed", "delivery_id", delivery.ID, "error", err)
+				return
+			}
+		}
+		if len(due) < batchSize {
+			return
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome in the outbox.
+func (d *Dispatcher) attempt(ctx context.Context, h *model.Webhook, delivery model.WebhookDelivery) error {
+	statusCode, err := d.send(ctx, h, delivery)
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+		slog.Warn("webhook delivery failed", "webhook_id", h.ID, "delivery_id", delivery.ID,
+			"attempt", delivery.Attempts+1, "error", err)
+	}
+	delivery.RecordAttempt(statusCode, errMsg, d.now())
+	return d.store.UpdateDelivery(context.WithoutCancel(ctx), delivery)
+}
+
+// send POSTs the delivery payload, signed with the webhook secret. It returns
+// the response status code (0 if there was none) and an error unless the
+// endpoint answered 2xx.
+func (d *Dispatcher) send(ctx context.Context, h *model.Webhook, delivery model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	ts := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "readdo-webhook/1")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, Sign(h.Secret, ts, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if len(body) > 0 {
+			return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+		}
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value for a delivery body sent at
+// timestamp (Unix seconds): "sha256=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with secret. Receivers should recompute it,
+// compare in constant time and reject stale timestamps.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
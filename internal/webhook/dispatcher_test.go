@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yangwenmai/readdo/internal/events"
+	"github.com/yangwenmai/readdo/internal/model"
+	"github.com/yangwenmai/readdo/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	db, err := store.OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := store.New(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	return s
+}
+
+// receiver is a webhook endpoint that records requests and answers status.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	w.WriteHeader(rc.status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+// setup creates a READY item with a synthesis artifact and a webhook for
+// item.ready pointing at a receiver answering status.
+func setup(t *testing.T, status int) (*store.Store, *receiver, *Dispatcher) {
+	t.Helper()
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	item := model.Item{ID: "item-1", URL: "https://example.com/a", Title: "A", Domain: "example.com",
+		SourceType: "web", Status: model.StatusReady, SaveCount: 1, CreatedAt: now, UpdatedAt: now}
+	if err := s.CreateItem(ctx, item); err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	if err := s.UpsertArtifact(ctx, model.NewArtifact("a1", "item-1", model.ArtifactSynthesis, `{"headline":"H"}`)); err != nil {
+		t.Fatalf("UpsertArtifact: %v", err)
+	}
+
+	rc := &receiver{status: status}
+	ts := httptest.NewServer(rc)
+	t.Cleanup(ts.Close)
+	hook := model.NewWebhook("hook-1", ts.URL, []string{model.EventItemReady}, "s3cret")
+	if err := s.CreateWebhook(ctx, hook); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	return s, rc, New(s, events.NewBus(10), 5*time.Second)
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	s, rc, d := setup(t, http.StatusNoContent)
+	ctx := context.Background()
+
+	d.enqueue(ctx, model.StatusEvent("item-1", model.StatusReady))
+	d.enqueue(ctx, model.StatusEvent("item-1", model.StatusFailed)) // not subscribed
+	d.deliverDue(ctx)
+
+	if rc.count() != 1 {
+		t.Fatalf("requests = %d, want 1", rc.count())
+	}
+	req, body := rc.requests[0], rc.bodies[0]
+	if got := req.Header.Get(HeaderEvent); got != model.EventItemReady {
+		t.Errorf("%s = %q, want %q", HeaderEvent, got, model.EventItemReady)
+	}
+	if want := Sign("s3cret", req.Header.Get(HeaderTimestamp), body); req.Header.Get(HeaderSignature) != want {
+		t.Errorf("signature = %q, want %q", req.Header.Get(HeaderSignature), want)
+	}
+
+	var p model.WebhookPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if p.Event.Type != model.EventItemReady || p.Item == nil || p.Item.ID != "item-1" || string(p.Synthesis) != `{"headline":"H"}` {
+		t.Errorf("payload = %s", body)
+	}
+
+	log, err := s.ListWebhookDeliveries(ctx, "hook-1", "")
+	if err != nil || len(log) != 1 {
+		t.Fatalf("delivery log = %d, %v; want 1", len(log), err)
+	}
+	if log[0].Status != model.DeliveryDelivered || log[0].ID != req.Header.Get(HeaderDelivery) {
+		t.Errorf("log entry = %+v", log[0])
+	}
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	s, rc, d := setup(t, http.StatusServiceUnavailable)
+	ctx := context.Background()
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	d.enqueue(ctx, model.StatusEvent("item-1", model.StatusReady))
+	d.deliverDue(ctx)
+	d.deliverDue(ctx) // not due again yet
+	if rc.count() != 1 {
+		t.Fatalf("requests = %d, want 1", rc.count())
+	}
+
+	log, _ := s.ListWebhookDeliveries(ctx, "hook-1", model.DeliveryPending)
+	if len(log) != 1 || log[0].Attempts != 1 || log[0].LastError == nil {
+		t.Fatalf("log = %+v, want one pending delivery with 1 failed attempt", log)
+	}
+
+	rc.mu.Lock()
+	rc.status = http.StatusOK
+	rc.mu.Unlock()
+	now = now.Add(time.Minute)
+	d.deliverDue(ctx)
+	if rc.count() != 2 {
+		t.Fatalf("requests = %d, want 2 after backoff", rc.count())
+	}
+	if log, _ := s.ListWebhookDeliveries(ctx, "hook-1", model.DeliveryDelivered); len(log) != 1 || log[0].Attempts != 2 {
+		t.Errorf("log = %+v, want delivered after 2 attempts", log)
+	}
+}
+
+func TestDispatcher_StartConsumesBus(t *testing.T) {
+	_, rc, d := setup(t, http.StatusOK)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Start(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Publish until the consumer has subscribed and the delivery arrives.
+	deadline := time.Now().Add(5 * time.Second)
+	for rc.count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no delivery received")
+		}
+		d.bus.Publish(model.StatusEvent("item-1", model.StatusReady))
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestDispatcher_StartWritesQueuedEventsOnShutdown(t *testing.T) {
+	s, rc, d := setup(t, http.StatusOK)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Start(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for rc.count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no delivery received")
+		}
+		d.bus.Publish(model.StatusEvent("item-1", model.StatusReady))
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// An event published right before shutdown still reaches the outbox.
+	d.bus.Publish(model.StatusEvent("item-2", model.StatusReady))
+	cancel()
+	<-done
+	deliveries, _ := s.ListWebhookDeliveries(context.Background(), "hook-1", "")
+	found := false
+	for _, delivery := range deliveries {
+		found = found || delivery.ItemID == "item-2"
+	}
+	if !found {
+		t.Error("event published before shutdown is not in the outbox")
+	}
+}
@@ -20,16 +20,34 @@ type ItemClaimer interface {
 	UpdateItemStatus(ctx context.Context, id, newStatus string, errorInfo *string) error
 }
 
+// EventPublisher receives item lifecycle events.
+type EventPublisher interface {
+	Publish(e model.Event)
+}
+
 // Worker polls for CAPTURED items and runs the pipeline.
 type Worker struct {
 	claimer   ItemClaimer
 	processor Processor
 	interval  time.Duration
+	events    EventPublisher
+}
+
+// Option configures optional Worker dependencies.
+type Option func(*Worker)
+
+// WithEvents makes the worker publish status events (PROCESSING, READY, FAILED).
+func WithEvents(pub EventPublisher) Option {
+	return func(w *Worker) { w.events = pub }
 }
 
 // New creates a new Worker.
-func New(claimer ItemClaimer, processor Processor, interval time.Duration) *Worker {
-	return &Worker{claimer: claimer, processor: processor, interval: interval}
+func New(claimer ItemClaimer, processor Processor, interval time.Duration, opts ...Option) *Worker {
+	w := &Worker{claimer: claimer, processor: processor, interval: interval}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
 }
 
 // Start begins the polling loop. It blocks until ctx is cancelled.
@@ -55,11 +73,25 @@ func (w *Worker) Start(ctx context.Context) {
 		}
 
 		slog.Info("processing item", "item_id", item.ID, "title", item.Title)
-		if err := w.processor.Run(ctx, item); err != nil {
+		w.publish(model.StatusEvent(item.ID, model.StatusProcessing))
+		err = w.processor.Run(ctx, item)
+		var merged *model.MergedError
+		if errors.As(err, &merged) {
+			// The item turned out to be a saved URL and no longer exists.
+			slog.Info("item merged into existing item", "item_id", item.ID, "into_id", merged.IntoID)
+			w.publish(model.NewEvent(model.EventItemDeleted, item.ID))
+			w.publish(model.StatusEvent(merged.IntoID, model.StatusCaptured))
+			continue
+		}
+		if err != nil {
 			slog.Error("pipeline failed", "item_id", item.ID, "error", err)
 			errInfo := w.buildErrorInfo(err)
 			if sErr := w.claimer.UpdateItemStatus(ctx, item.ID, model.StatusFailed, &errInfo); sErr != nil {
 				slog.Error("failed to set FAILED status", "item_id", item.ID, "error", sErr)
+			} else {
+				e := model.StatusEvent(item.ID, model.StatusFailed)
+				e.Error = err.Error()
+				w.publish(e)
 			}
 			continue
 		}
@@ -68,10 +100,17 @@ func (w *Worker) Start(ctx context.Context) {
 			slog.Error("failed to set READY status", "item_id", item.ID, "error", err)
 		} else {
 			slog.Info("item is now READY", "item_id", item.ID)
+			w.publish(model.StatusEvent(item.ID, model.StatusReady))
 		}
 	}
 }
 
+func (w *Worker) publish(e model.Event) {
+	if w.events != nil {
+		w.events.Publish(e)
+	}
+}
+
 func (w *Worker) sleep(ctx context.Context) {
 	select {
 	case <-ctx.Done():